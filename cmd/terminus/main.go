@@ -2,7 +2,6 @@
 package main
 
 import (
-	"fmt"
 	"os"
 
 	"github.com/deviantintegral/terminus-golang/internal/commands"
@@ -10,7 +9,7 @@ import (
 
 func main() {
 	if err := commands.Execute(); err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		commands.ReportError(os.Stderr, err)
+		os.Exit(commands.ExitCode(err))
 	}
 }
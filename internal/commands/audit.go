@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"time"
+
+	"github.com/deviantintegral/terminus-golang/pkg/audit"
+	"github.com/spf13/cobra"
+)
+
+var auditListCmd = &cobra.Command{
+	Use:   "audit:list",
+	Short: "List changes made from this machine",
+	Long: `List the audit log of changes made from this machine, oldest first.
+
+Every POST, PUT, PATCH and DELETE request is recorded with the command line,
+user, site and environment, trace ID and outcome, as is the result of each
+workflow that a command waited for. Secret flag values are redacted.
+
+Usage examples:
+  audit:list --since=24h                 Changes made in the last day
+  audit:list --since=2024-01-01          Changes made since a date
+  audit:list --site=my-site              Changes to a site, by name or ID
+  audit:list --command=env:deploy        Changes made by a command`,
+	Args: cobra.NoArgs,
+	RunE: runAuditList,
+}
+
+var (
+	auditSinceFlag   string
+	auditUntilFlag   string
+	auditSiteFlag    string
+	auditCommandFlag string
+)
+
+func init() {
+	// Add audit commands directly to rootCmd with colon-separated names
+	rootCmd.AddCommand(auditListCmd)
+
+	auditListCmd.Flags().StringVar(&auditSinceFlag, "since", "", "Only list changes after this time, as a duration ago (e.g. 24h) or a date")
+	auditListCmd.Flags().StringVar(&auditUntilFlag, "until", "", "Only list changes before this time, as a duration ago (e.g. 1h) or a date")
+	auditListCmd.Flags().StringVar(&auditSiteFlag, "site", "", "Only list changes to this site")
+	auditListCmd.Flags().StringVar(&auditCommandFlag, "command", "", "Only list changes made by this command, e.g. env:deploy")
+}
+
+func runAuditList(_ *cobra.Command, _ []string) error {
+	filter := &audit.Filter{Command: auditCommandFlag}
+
+	var err error
+	if filter.Since, err = parseAuditTime("since", auditSinceFlag); err != nil {
+		return err
+	}
+	if filter.Until, err = parseAuditTime("until", auditUntilFlag); err != nil {
+		return err
+	}
+
+	if auditSiteFlag != "" {
+		filter.Sites = []string{auditSiteFlag}
+		// Requests are recorded with the site name or ID they were sent with,
+		// so match both when the site can be looked up. A deleted site can
+		// still be listed by the identifier it was recorded with.
+		if site, err := cliContext.API.Sites.Get(getContext(), auditSiteFlag); err == nil {
+			filter.Sites = append(filter.Sites, site.ID, site.Name)
+		}
+	}
+
+	entries, err := cliContext.Audit.Read(filter)
+	if err != nil {
+		return err
+	}
+
+	return printOutput(entries)
+}
+
+// parseAuditTime parses a time flag given as a duration ago, a date or an
+// RFC 3339 timestamp. An empty value returns the zero time.
+func parseAuditTime(flag, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if ago, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-ago), nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateTime, time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, newValidationError("invalid --%s: %s (must be a duration such as 24h, or a date such as 2024-01-31)", flag, value)
+}
@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/deviantintegral/terminus-golang/pkg/api"
+	"github.com/deviantintegral/terminus-golang/pkg/api/apitest"
+	"github.com/deviantintegral/terminus-golang/pkg/audit"
+)
+
+func TestRunAuditList(t *testing.T) {
+	_, buf := useFake(t)
+	server := apitest.NewServer(t)
+	mySite := server.AddSite("my-site")
+	otherSite := server.AddSite("other-site")
+
+	log := audit.NewLog(filepath.Join(t.TempDir(), "audit.jsonl"), audit.Entry{Command: "env:clear-cache"})
+	client := server.Client(api.WithInterceptors(audit.Interceptor(log)), api.WithWorkflowObserver(audit.WorkflowObserver(log)))
+	cliContext.APIClient, cliContext.API, cliContext.Audit = client, api.NewPantheon(client), log
+	useYes(t)
+
+	quietFlag = true
+	if err := runEnvClearCache(nil, []string{mySite.ID + ".dev"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := runEnvClearCache(nil, []string{otherSite.ID + ".dev"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	quietFlag = false
+
+	oldSite := auditSiteFlag
+	defer func() { auditSiteFlag = oldSite }()
+	auditSiteFlag = "my-site"
+
+	if err := runAuditList(nil, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var entries []*audit.Entry
+	if err := json.Unmarshal(buf.Bytes(), &entries); err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected the request and the workflow for my-site, got %+v", entries)
+	}
+	if finished := entries[1]; finished.WorkflowID == "" || finished.Outcome != "succeeded" || finished.Command != "env:clear-cache" {
+		t.Errorf("unexpected entry: %+v", finished)
+	}
+}
+
+func TestParseAuditTime(t *testing.T) {
+	if got, err := parseAuditTime("since", "2024-01-31"); err != nil || got.Day() != 31 {
+		t.Errorf("expected date to parse, got %v, %v", got, err)
+	}
+	if _, err := parseAuditTime("since", "yesterday"); !errors.Is(err, errValidation) {
+		t.Errorf("expected validation error, got %v", err)
+	}
+}
@@ -4,7 +4,6 @@ package commands
 import (
 	"fmt"
 
-	"github.com/deviantintegral/terminus-golang/pkg/api"
 	"github.com/deviantintegral/terminus-golang/pkg/session"
 	"github.com/spf13/cobra"
 )
@@ -68,7 +67,7 @@ func runAuthLogin(_ *cobra.Command, _ []string) error {
 	token = session.ExtractRawToken(token)
 
 	// Create auth service
-	authService := api.NewAuthService(cliContext.APIClient)
+	authService := cliContext.API.Auth
 
 	// Authenticate
 	printMessage("Logging in...")
@@ -184,7 +183,7 @@ func runAuthWhoami(_ *cobra.Command, _ []string) error {
 	}
 
 	// Create auth service
-	authService := api.NewAuthService(cliContext.APIClient)
+	authService := cliContext.API.Auth
 
 	// Get user info
 	user, err := authService.Whoami(getContext(), sess.UserID)
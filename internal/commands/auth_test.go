@@ -417,6 +417,7 @@ func TestRunAuthLogin_WithMachineToken(t *testing.T) {
 	cliContext = &CLIContext{
 		SessionStore: store,
 		APIClient:    apiClient,
+		API:          api.NewPantheon(apiClient),
 	}
 
 	// Set the machine token flag and quiet mode to suppress output
@@ -499,6 +500,7 @@ func TestRunAuthLogin_WithMachineTokenAndEmail(t *testing.T) {
 	cliContext = &CLIContext{
 		SessionStore: store,
 		APIClient:    apiClient,
+		API:          api.NewPantheon(apiClient),
 	}
 
 	// Set the machine token and email flags
@@ -579,6 +581,7 @@ func TestRunAuthLogin_WithSavedToken(t *testing.T) {
 	cliContext = &CLIContext{
 		SessionStore: store,
 		APIClient:    apiClient,
+		API:          api.NewPantheon(apiClient),
 	}
 
 	// Don't set machine token flag, so it uses saved token
@@ -638,6 +641,7 @@ func TestRunAuthLogin_LoginFailure(t *testing.T) {
 	cliContext = &CLIContext{
 		SessionStore: store,
 		APIClient:    apiClient,
+		API:          api.NewPantheon(apiClient),
 	}
 
 	// Set the machine token flag
@@ -785,6 +789,7 @@ func TestRunAuthWhoami_Success(t *testing.T) {
 	cliContext = &CLIContext{
 		SessionStore: store,
 		APIClient:    apiClient,
+		API:          api.NewPantheon(apiClient),
 		Output: &output.Options{
 			Format: output.FormatJSON,
 		},
@@ -814,6 +819,7 @@ func TestRunAuthWhoami_NoSession(t *testing.T) {
 	cliContext = &CLIContext{
 		SessionStore: store,
 		APIClient:    apiClient,
+		API:          api.NewPantheon(apiClient),
 	}
 
 	// Run whoami (should fail with no session error)
@@ -859,6 +865,7 @@ func TestRunAuthWhoami_EmptyUserID(t *testing.T) {
 	cliContext = &CLIContext{
 		SessionStore: store,
 		APIClient:    apiClient,
+		API:          api.NewPantheon(apiClient),
 	}
 
 	// Run whoami (should fail with no user ID error)
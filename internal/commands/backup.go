@@ -127,7 +127,7 @@ func runBackupList(_ *cobra.Command, args []string) error {
 		return err
 	}
 
-	backupsService := api.NewBackupsService(cliContext.APIClient)
+	backupsService := cliContext.API.Backups
 
 	backups, err := backupsService.List(getContext(), siteID, envID)
 	if err != nil {
@@ -143,7 +143,7 @@ func runBackupCreate(_ *cobra.Command, args []string) error {
 		return err
 	}
 
-	backupsService := api.NewBackupsService(cliContext.APIClient)
+	backupsService := cliContext.API.Backups
 
 	var workflow *models.Workflow
 
@@ -172,10 +172,10 @@ func runBackupGet(_ *cobra.Command, args []string) error {
 	}
 
 	if backupIDFlag == "" {
-		return fmt.Errorf("--backup flag is required")
+		return newValidationError("--backup flag is required")
 	}
 
-	backupsService := api.NewBackupsService(cliContext.APIClient)
+	backupsService := cliContext.API.Backups
 
 	// Determine output path
 	outputPath := backupOutputFlag
@@ -205,7 +205,7 @@ func runBackupRestore(_ *cobra.Command, args []string) error {
 		return nil
 	}
 
-	backupsService := api.NewBackupsService(cliContext.APIClient)
+	backupsService := cliContext.API.Backups
 
 	printMessage("Restoring backup %s for %s.%s...", backupIDFlag, siteID, envID)
 
@@ -223,7 +223,7 @@ func runBackupInfo(_ *cobra.Command, args []string) error {
 		return err
 	}
 
-	backupsService := api.NewBackupsService(cliContext.APIClient)
+	backupsService := cliContext.API.Backups
 
 	// List all backups to find matching one
 	backups, err := backupsService.List(getContext(), siteID, envID)
@@ -282,7 +282,7 @@ func runBackupAutomaticInfo(_ *cobra.Command, args []string) error {
 		return err
 	}
 
-	backupsService := api.NewBackupsService(cliContext.APIClient)
+	backupsService := cliContext.API.Backups
 
 	schedule, err := backupsService.GetSchedule(getContext(), siteID, envID)
 	if err != nil {
@@ -298,7 +298,7 @@ func runBackupAutomaticEnable(_ *cobra.Command, args []string) error {
 		return err
 	}
 
-	backupsService := api.NewBackupsService(cliContext.APIClient)
+	backupsService := cliContext.API.Backups
 
 	printMessage("Enabling automatic backups for %s.%s...", siteID, envID)
 
@@ -317,7 +317,7 @@ func runBackupAutomaticDisable(_ *cobra.Command, args []string) error {
 		return err
 	}
 
-	backupsService := api.NewBackupsService(cliContext.APIClient)
+	backupsService := cliContext.API.Backups
 
 	printMessage("Disabling automatic backups for %s.%s...", siteID, envID)
 
@@ -3,7 +3,6 @@ package commands
 import (
 	"fmt"
 
-	"github.com/deviantintegral/terminus-golang/pkg/api"
 	"github.com/spf13/cobra"
 )
 
@@ -21,7 +20,7 @@ func init() {
 
 func runBranchList(_ *cobra.Command, args []string) error {
 	siteID := args[0]
-	sitesService := api.NewSitesService(cliContext.APIClient)
+	sitesService := cliContext.API.Sites
 
 	branches, err := sitesService.ListBranches(getContext(), siteID)
 	if err != nil {
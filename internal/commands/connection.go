@@ -1,9 +1,18 @@
 package commands
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
 
 	"github.com/deviantintegral/terminus-golang/pkg/api"
+	"github.com/deviantintegral/terminus-golang/pkg/api/models"
+	"github.com/deviantintegral/terminus-golang/pkg/remote"
 	"github.com/spf13/cobra"
 )
 
@@ -23,10 +32,63 @@ var connectionSetCmd = &cobra.Command{
 	RunE:  runConnectionSet,
 }
 
+var connectionSSHCmd = &cobra.Command{
+	Use:   "connection:ssh <site>.<env> [-- <ssh args>]",
+	Short: "Open an SSH session",
+	Long:  "Wake an environment and open an SSH session to its application server with the ssh client",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runConnectionSSH,
+}
+
+var connectionSFTPCmd = &cobra.Command{
+	Use:   "connection:sftp <site>.<env> [-- <sftp args>]",
+	Short: "Open an SFTP session",
+	Long:  "Wake an environment and open an SFTP session to it with the sftp client; development environments must be in SFTP mode",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runConnectionSFTP,
+}
+
+var connectionMySQLCmd = &cobra.Command{
+	Use:   "connection:mysql <site>.<env> [-- <mysql args>]",
+	Short: "Open a MySQL session",
+	Long:  "Wake an environment and connect to its database with the mysql client, passing the password in a temporary option file",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runConnectionMySQL,
+}
+
+var connectionRedisCmd = &cobra.Command{
+	Use:   "connection:redis <site>.<env> [-- <redis-cli args>]",
+	Short: "Open a Redis session",
+	Long:  "Wake an environment and connect to its Redis cache with redis-cli, passing the password in the environment",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runConnectionRedis,
+}
+
+var (
+	tunnelServiceFlag   string
+	tunnelLocalPortFlag int
+)
+
+var connectionTunnelCmd = &cobra.Command{
+	Use:   "connection:tunnel <site>.<env>",
+	Short: "Open a tunnel to the database or Redis",
+	Long:  "Forward a local port to an environment's database or Redis cache over SSH, reconnecting when the tunnel drops, until interrupted",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runConnectionTunnel,
+}
+
 func init() {
 	// Add connection commands directly to rootCmd with colon-separated names
 	rootCmd.AddCommand(connectionInfoCmd)
 	rootCmd.AddCommand(connectionSetCmd)
+	rootCmd.AddCommand(connectionSSHCmd)
+	rootCmd.AddCommand(connectionSFTPCmd)
+	rootCmd.AddCommand(connectionMySQLCmd)
+	rootCmd.AddCommand(connectionRedisCmd)
+	rootCmd.AddCommand(connectionTunnelCmd)
+
+	connectionTunnelCmd.Flags().StringVar(&tunnelServiceFlag, "service", "mysql", "Service to connect to (mysql or redis)")
+	connectionTunnelCmd.Flags().IntVar(&tunnelLocalPortFlag, "local-port", 0, "Local port to listen on (default any free port)")
 }
 
 func runConnectionInfo(_ *cobra.Command, args []string) error {
@@ -35,7 +97,7 @@ func runConnectionInfo(_ *cobra.Command, args []string) error {
 		return err
 	}
 
-	envsService := api.NewEnvironmentsService(cliContext.APIClient)
+	envsService := cliContext.API.Environments
 
 	info, err := envsService.GetConnectionInfo(getContext(), siteID, envID)
 	if err != nil {
@@ -53,10 +115,10 @@ func runConnectionSet(_ *cobra.Command, args []string) error {
 
 	mode := args[1]
 	if mode != "git" && mode != "sftp" {
-		return fmt.Errorf("invalid connection mode: %s (must be 'git' or 'sftp')", mode)
+		return newValidationError("invalid connection mode: %s (must be 'git' or 'sftp')", mode)
 	}
 
-	envsService := api.NewEnvironmentsService(cliContext.APIClient)
+	envsService := cliContext.API.Environments
 
 	printMessage("Setting connection mode to %s for %s.%s...", mode, siteID, envID)
 
@@ -67,3 +129,267 @@ func runConnectionSet(_ *cobra.Command, args []string) error {
 
 	return waitForWorkflow(siteID, workflow.ID, "Setting connection mode")
 }
+
+func runConnectionSSH(_ *cobra.Command, args []string) error {
+	siteID, envID, err := parseSiteEnv(args[0])
+	if err != nil {
+		return err
+	}
+
+	info, err := prepareConnection(siteID, envID)
+	if err != nil {
+		return err
+	}
+	target := remote.TargetFromConnectionInfo(info)
+
+	clientArgs := append([]string{"-p", strconv.Itoa(target.Port), target.User + "@" + target.Host}, args[1:]...)
+	return runClient(siteID, envID, "ssh", clientArgs, nil)
+}
+
+func runConnectionSFTP(_ *cobra.Command, args []string) error {
+	siteID, envID, err := parseSiteEnv(args[0])
+	if err != nil {
+		return err
+	}
+
+	// Code can only be changed over SFTP on development environments in
+	// SFTP mode; test and live only allow access to files
+	if envID != "test" && envID != "live" {
+		env, err := cliContext.API.Environments.Get(getContext(), siteID, envID)
+		if err != nil {
+			return fmt.Errorf("failed to get environment: %w", err)
+		}
+		if !env.OnServerDevelopment && env.ConnectionMode != "sftp" {
+			return newValidationError("%s.%s is in git mode: switch it with 'terminus connection:set %s.%s sftp' first", siteID, envID, siteID, envID)
+		}
+	}
+
+	info, err := prepareConnection(siteID, envID)
+	if err != nil {
+		return err
+	}
+	target := remote.TargetFromConnectionInfo(info)
+
+	clientArgs := append([]string{"-o", "Port=" + strconv.Itoa(target.Port)}, args[1:]...)
+	clientArgs = append(clientArgs, target.User+"@"+target.Host)
+	return runClient(siteID, envID, "sftp", clientArgs, nil)
+}
+
+func runConnectionMySQL(_ *cobra.Command, args []string) error {
+	siteID, envID, err := parseSiteEnv(args[0])
+	if err != nil {
+		return err
+	}
+
+	info, err := prepareConnection(siteID, envID)
+	if err != nil {
+		return err
+	}
+	if info.MySQLHost == "" {
+		return fmt.Errorf("no database connection info for %s.%s", siteID, envID)
+	}
+
+	if err := checkClientMode(siteID, envID, "mysql", args[1:]); err != nil {
+		return err
+	}
+
+	// The password goes in an option file readable only by the current user,
+	// so that it doesn't show up in the process list
+	file, err := os.CreateTemp("", "terminus-mysql-*.cnf")
+	if err != nil {
+		return fmt.Errorf("failed to create MySQL option file: %w", err)
+	}
+	defer func() { _ = os.Remove(file.Name()) }()
+
+	_, err = fmt.Fprintf(file, "[client]\nuser=%s\npassword=%s\nhost=%s\nport=%d\n",
+		mysqlOptionValue(info.MySQLUsername), mysqlOptionValue(info.MySQLPassword), mysqlOptionValue(info.MySQLHost), info.MySQLPort)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write MySQL option file: %w", err)
+	}
+
+	// --defaults-extra-file must come before any other option
+	clientArgs := append([]string{"--defaults-extra-file=" + file.Name(), "--database=" + info.MySQLDatabase}, args[1:]...)
+	return clientRunner("mysql", clientArgs, nil)
+}
+
+// mysqlOptionValue quotes a value for a MySQL option file
+func mysqlOptionValue(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func runConnectionRedis(_ *cobra.Command, args []string) error {
+	siteID, envID, err := parseSiteEnv(args[0])
+	if err != nil {
+		return err
+	}
+
+	info, err := prepareConnection(siteID, envID)
+	if err != nil {
+		return err
+	}
+	if info.RedisHost == "" {
+		return fmt.Errorf("redis is not enabled for %s: enable it with 'terminus redis:enable %s'", siteID, siteID)
+	}
+
+	// redis-cli reads the password from REDISCLI_AUTH, which keeps it out of
+	// the process list
+	clientArgs := append([]string{"-h", info.RedisHost, "-p", strconv.Itoa(info.RedisPort)}, args[1:]...)
+	return runClient(siteID, envID, "redis-cli", clientArgs, []string{"REDISCLI_AUTH=" + info.RedisPassword})
+}
+
+// prepareConnection wakes an environment, so that a sleeping one doesn't
+// refuse the connection, and returns its connection info
+func prepareConnection(siteID, envID string) (*models.ConnectionInfo, error) {
+	info, err := cliContext.API.Environments.GetConnectionInfo(getContext(), siteID, envID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection info: %w", err)
+	}
+	if cliContext.ReadOnly || cliContext.DryRun {
+		return info, nil
+	}
+
+	if err := wakeEnvironment(getContext(), siteID, envID); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// wakeEnvironment wakes an environment, so that a sleeping one doesn't refuse
+// the connection. It is best-effort: an environment that fails the health
+// check, such as a locked one, may still accept connections, so only a
+// cancelled context stops the command.
+func wakeEnvironment(ctx context.Context, siteID, envID string) error {
+	printProgress("Waking %s.%s...", siteID, envID)
+	if err := cliContext.API.Environments.Wake(ctx, siteID, envID); err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		printProgress("Warning: %v", err)
+	}
+	return nil
+}
+
+// checkClientMode stops before a client is started when --read-only or
+// --dry-run is set. Interactive sessions can change anything, so they count
+// as changes.
+func checkClientMode(siteID, envID, name string, args []string) error {
+	if cliContext.ReadOnly {
+		return api.ErrReadOnly
+	}
+	if cliContext.DryRun {
+		return &api.DryRunError{Method: "EXEC", Path: siteID + "." + envID, Body: remote.Command(name, args...)}
+	}
+	return nil
+}
+
+// runClient runs a connection client unless --read-only or --dry-run is set
+func runClient(siteID, envID, name string, args, env []string) error {
+	if err := checkClientMode(siteID, envID, name, args); err != nil {
+		return err
+	}
+	return clientRunner(name, args, env)
+}
+
+// clientRunner runs a client program attached to the terminal, with env added
+// to its environment. It is a variable so that it can be mocked in tests.
+var clientRunner = func(name string, args, env []string) error {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return fmt.Errorf("%s not found: install it or add it to your PATH", name)
+	}
+
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), env...)
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return fmt.Errorf("%s %w", name, err)
+		}
+		return fmt.Errorf("failed to run %s: %w", name, err)
+	}
+	return nil
+}
+
+func runConnectionTunnel(_ *cobra.Command, args []string) error {
+	siteID, envID, err := parseSiteEnv(args[0])
+	if err != nil {
+		return err
+	}
+	if tunnelServiceFlag != "mysql" && tunnelServiceFlag != "redis" {
+		return newValidationError("invalid service: %s (must be 'mysql' or 'redis')", tunnelServiceFlag)
+	}
+
+	// The endpoints are resolved again on each reconnection, since they
+	// change when the environment's containers migrate
+	resolve := func(ctx context.Context) (remote.Target, string, error) {
+		info, err := cliContext.API.Environments.GetConnectionInfo(ctx, siteID, envID)
+		if err != nil {
+			return remote.Target{}, "", fmt.Errorf("failed to get connection info: %w", err)
+		}
+		addr, err := tunnelAddr(info, siteID)
+		if err != nil {
+			return remote.Target{}, "", err
+		}
+		return remote.TargetFromConnectionInfo(info), addr, nil
+	}
+	// Resolve them once up front, so that a bad site or a service that isn't
+	// enabled is reported before listening
+	target, addr, err := resolve(getContext())
+	if err != nil {
+		return err
+	}
+
+	// A tunnel gives full access to the service, so it counts as a change
+	if cliContext.ReadOnly {
+		return api.ErrReadOnly
+	}
+	if cliContext.DryRun {
+		return &api.DryRunError{Method: "TUNNEL", Path: target.User + "@" + target.Addr(), Body: addr}
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(tunnelLocalPortFlag)))
+	if err != nil {
+		return fmt.Errorf("failed to listen on port %d: %w", tunnelLocalPortFlag, err)
+	}
+
+	printProgress("Tunnel to %s on %s.%s listening on %s; press Ctrl-C to stop", tunnelServiceFlag, siteID, envID, listener.Addr())
+	tunnel := &remote.Tunnel{
+		Dialer: cliContext.Remote,
+		// The environment may have gone back to sleep by the time the
+		// tunnel reconnects, so it is woken before each connection
+		Resolve: func(ctx context.Context) (remote.Target, string, error) {
+			if err := wakeEnvironment(ctx, siteID, envID); err != nil {
+				return remote.Target{}, "", err
+			}
+			return resolve(ctx)
+		},
+		Logf: printProgress,
+	}
+	err = tunnel.Serve(getContext(), listener)
+	if errors.Is(err, context.Canceled) {
+		// Interrupting is how a tunnel is meant to be stopped
+		return nil
+	}
+	return err
+}
+
+// tunnelAddr returns the address of the service selected with --service
+func tunnelAddr(info *models.ConnectionInfo, siteID string) (string, error) {
+	if tunnelServiceFlag == "redis" {
+		if info.RedisHost == "" {
+			return "", fmt.Errorf("redis is not enabled for %s: enable it with 'terminus redis:enable %s'", siteID, siteID)
+		}
+		return net.JoinHostPort(info.RedisHost, strconv.Itoa(info.RedisPort)), nil
+	}
+	if info.MySQLHost == "" {
+		return "", fmt.Errorf("no database connection info for %s", siteID)
+	}
+	return net.JoinHostPort(info.MySQLHost, strconv.Itoa(info.MySQLPort)), nil
+}
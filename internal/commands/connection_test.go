@@ -1,7 +1,19 @@
 package commands
 
 import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/deviantintegral/terminus-golang/pkg/api"
+	"github.com/deviantintegral/terminus-golang/pkg/remote/remotetest"
 )
 
 func TestConnectionInfoCmdStructure(t *testing.T) {
@@ -25,7 +37,7 @@ func TestConnectionSetCmdStructure(t *testing.T) {
 }
 
 func TestConnectionCommands(t *testing.T) {
-	expectedCommands := []string{"connection:info", "connection:set"}
+	expectedCommands := []string{"connection:info", "connection:set", "connection:ssh", "connection:sftp", "connection:mysql", "connection:redis", "connection:tunnel"}
 
 	for _, expected := range expectedCommands {
 		found := false
@@ -40,3 +52,296 @@ func TestConnectionCommands(t *testing.T) {
 		}
 	}
 }
+
+// clientCall is a client started by a connection command
+type clientCall struct {
+	name      string
+	args, env []string
+	// optionFile is the content of the file passed with --defaults-extra-file
+	optionFile string
+}
+
+// useClientRunner records the clients started instead of running them
+func useClientRunner(t *testing.T) *[]clientCall {
+	t.Helper()
+
+	original := clientRunner
+	t.Cleanup(func() { clientRunner = original })
+
+	var calls []clientCall
+	clientRunner = func(name string, args, env []string) error {
+		call := clientCall{name: name, args: args, env: env}
+		for _, arg := range args {
+			if path, ok := strings.CutPrefix(arg, "--defaults-extra-file="); ok {
+				data, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatalf("failed to read option file: %v", err)
+				}
+				call.optionFile = string(data)
+			}
+		}
+		calls = append(calls, call)
+		return nil
+	}
+	return &calls
+}
+
+func TestRunConnectionSSH(t *testing.T) {
+	fake, _ := useFake(t)
+	site := fake.AddSite("my-site")
+	calls := useClientRunner(t)
+
+	if err := runConnectionSSH(nil, []string{"my-site.dev", "-v"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"-p", "2222", "dev." + site.ID + "@appserver.dev." + site.ID + ".drush.in", "-v"}
+	if len(*calls) != 1 || (*calls)[0].name != "ssh" || !slices.Equal((*calls)[0].args, want) {
+		t.Errorf("got %+v, want ssh %v", *calls, want)
+	}
+}
+
+func TestRunConnectionSFTP_RequiresSFTPMode(t *testing.T) {
+	fake, _ := useFake(t)
+	site := fake.AddSite("my-site")
+	calls := useClientRunner(t)
+
+	err := runConnectionSFTP(nil, []string{"my-site.dev"})
+	if !errors.Is(err, errValidation) || !strings.Contains(err.Error(), "connection:set") {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	if len(*calls) != 0 {
+		t.Fatalf("expected no client to run, got %+v", *calls)
+	}
+
+	// Test and live always accept SFTP
+	if err := runConnectionSFTP(nil, []string{"my-site.live"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := cliContext.API.Environments.ChangeConnectionMode(getContext(), site.ID, "dev", "sftp"); err != nil {
+		t.Fatalf("failed to change connection mode: %v", err)
+	}
+	if err := runConnectionSFTP(nil, []string{"my-site.dev"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"-o", "Port=2222", "dev." + site.ID + "@appserver.dev." + site.ID + ".drush.in"}
+	if len(*calls) != 2 || !slices.Equal((*calls)[1].args, want) {
+		t.Errorf("got %+v, want sftp %v", *calls, want)
+	}
+}
+
+func TestRunConnectionMySQL(t *testing.T) {
+	fake, _ := useFake(t)
+	site := fake.AddSite("my-site")
+	calls := useClientRunner(t)
+
+	if err := runConnectionMySQL(nil, []string{"my-site.dev", "-e", "SHOW TABLES"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(*calls) != 1 {
+		t.Fatalf("expected one client, got %+v", *calls)
+	}
+	call := (*calls)[0]
+
+	password := "mysql-" + site.ID
+	for _, arg := range call.args {
+		if strings.Contains(arg, password) {
+			t.Errorf("password passed in argument %q", arg)
+		}
+	}
+	if !strings.Contains(call.optionFile, `password="`+password+`"`) {
+		t.Errorf("option file doesn't contain the password:\n%s", call.optionFile)
+	}
+	if !slices.Equal(call.args[1:], []string{"--database=pantheon", "-e", "SHOW TABLES"}) {
+		t.Errorf("unexpected arguments %v", call.args)
+	}
+
+	// The option file is removed once the client exits
+	path := strings.TrimPrefix(call.args[0], "--defaults-extra-file=")
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected option file to be removed, got %v", err)
+	}
+}
+
+func TestMySQLOptionValue(t *testing.T) {
+	if got, want := mysqlOptionValue(`pa"ss\word`), `"pa\"ss\\word"`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestRunConnectionRedis(t *testing.T) {
+	fake, _ := useFake(t)
+	site := fake.AddSite("my-site")
+	calls := useClientRunner(t)
+
+	if err := runConnectionRedis(nil, []string{"my-site.live", "info"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	password := "redis-" + site.ID
+	call := (*calls)[0]
+	if slices.Contains(call.args, password) {
+		t.Errorf("password passed in arguments %v", call.args)
+	}
+	if !slices.Equal(call.env, []string{"REDISCLI_AUTH=" + password}) {
+		t.Errorf("unexpected environment %v", call.env)
+	}
+	want := []string{"-h", "cacheserver.live." + site.ID + ".drush.in", "-p", "6379", "info"}
+	if call.name != "redis-cli" || !slices.Equal(call.args, want) {
+		t.Errorf("got %s %v, want redis-cli %v", call.name, call.args, want)
+	}
+}
+
+func TestRunConnectionSSH_WakeFails(t *testing.T) {
+	fake, _ := useFake(t)
+	fake.AddSite("my-site")
+	calls := useClientRunner(t)
+	fake.FailMethod("Environments.Wake", api.ErrUnauthorized)
+	stdout := useStdFile(t, &os.Stdout, "")
+	stderr := useStdFile(t, &os.Stderr, "")
+
+	// A locked environment fails the health check but still accepts
+	// connections
+	if err := runConnectionSSH(nil, []string{"my-site.dev"}); err != nil {
+		t.Fatalf("expected the connection to go ahead, got %v", err)
+	}
+	if len(*calls) != 1 {
+		t.Errorf("expected the client to run, got %+v", *calls)
+	}
+	if out := stdout(); out != "" {
+		t.Errorf("expected progress messages to stay off stdout, got %q", out)
+	}
+	if log := stderr(); !strings.Contains(log, "Waking") || !strings.Contains(log, "Warning: ") {
+		t.Errorf("expected progress and a warning on stderr, got %q", log)
+	}
+}
+
+func TestRunConnectionMySQL_ReadOnlyAndDryRun(t *testing.T) {
+	fake, _ := useFake(t)
+	fake.AddSite("my-site")
+	calls := useClientRunner(t)
+
+	cliContext.ReadOnly = true
+	if err := runConnectionMySQL(nil, []string{"my-site.dev"}); !errors.Is(err, api.ErrReadOnly) {
+		t.Errorf("expected read-only error, got %v", err)
+	}
+
+	cliContext.ReadOnly, cliContext.DryRun = false, true
+	err := runConnectionMySQL(nil, []string{"my-site.dev", "-e", "DROP TABLE users"})
+	var dryRun *api.DryRunError
+	if !errors.As(err, &dryRun) || dryRun.Body != "mysql -e 'DROP TABLE users'" {
+		t.Errorf("expected dry-run error, got %v", err)
+	}
+	if len(*calls) != 0 {
+		t.Errorf("expected no client to run, got %+v", *calls)
+	}
+}
+
+func TestRunConnectionTunnel(t *testing.T) {
+	fake, _ := useFake(t)
+	fake.AddSite("my-site")
+	server := remotetest.NewServer(t)
+	fake.SFTPHost, fake.SFTPPort = server.Host(), server.Port()
+	cliContext.Remote = server.Dialer()
+
+	// The database is a local echo server, reached through the SSH server
+	database, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer func() { _ = database.Close() }()
+	go func() {
+		for {
+			conn, err := database.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+	host, port, _ := net.SplitHostPort(database.Addr().String())
+	fake.MySQLHost = host
+	fake.MySQLPort, _ = strconv.Atoi(port)
+
+	// Find a free local port for the tunnel
+	free, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	localAddr := free.Addr().String()
+	_ = free.Close()
+	_, localPort, _ := net.SplitHostPort(localAddr)
+	tunnelLocalPortFlag, _ = strconv.Atoi(localPort)
+	t.Cleanup(func() { tunnelLocalPortFlag = 0 })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	oldRootContext := rootContext
+	rootContext = ctx
+	t.Cleanup(func() { rootContext = oldRootContext })
+	stdout := useStdFile(t, &os.Stdout, "")
+	stderr := useStdFile(t, &os.Stderr, "")
+
+	done := make(chan error, 1)
+	go func() { done <- runConnectionTunnel(nil, []string{"my-site.dev"}) }()
+
+	// Wait for the tunnel to listen
+	var conn net.Conn
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if conn, err = net.Dial("tcp", localAddr); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		t.Fatalf("failed to connect to tunnel: %v", err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Write([]byte("ping")); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	reply := make([]byte, 4)
+	if _, err := io.ReadFull(conn, reply); err != nil || string(reply) != "ping" {
+		t.Errorf("got %q (%v), want ping", reply, err)
+	}
+	_ = conn.Close()
+
+	// The environment is woken again when the tunnel reconnects
+	server.DropConnections()
+	for deadline := time.Now().Add(5 * time.Second); strings.Count(stderr(), "Waking") < 2 && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if log := stderr(); strings.Count(log, "Waking") != 2 || !strings.Contains(log, "listening on") || !strings.Contains(log, "reconnecting") {
+		t.Errorf("expected the tunnel to wake the environment again on stderr, got %q", log)
+	}
+
+	// Interrupting stops the tunnel without an error
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if forwards := server.Forwards(); len(forwards) != 1 || forwards[0] != database.Addr().String() {
+		t.Errorf("unexpected forwards %v", forwards)
+	}
+	if out := stdout(); out != "" {
+		t.Errorf("expected tunnel messages to stay off stdout, got %q", out)
+	}
+}
+
+func TestRunConnectionTunnel_ReadOnlyAndInvalidService(t *testing.T) {
+	fake, _ := useFake(t)
+	fake.AddSite("my-site")
+
+	cliContext.ReadOnly = true
+	if err := runConnectionTunnel(nil, []string{"my-site.dev"}); !errors.Is(err, api.ErrReadOnly) {
+		t.Errorf("expected read-only error, got %v", err)
+	}
+
+	tunnelServiceFlag = "postgres"
+	t.Cleanup(func() { tunnelServiceFlag = "mysql" })
+	if err := runConnectionTunnel(nil, []string{"my-site.dev"}); !errors.Is(err, errValidation) {
+		t.Errorf("expected a validation error, got %v", err)
+	}
+}
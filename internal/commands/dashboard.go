@@ -6,7 +6,6 @@ import (
 	"runtime"
 	"strings"
 
-	"github.com/deviantintegral/terminus-golang/pkg/api"
 	"github.com/spf13/cobra"
 )
 
@@ -89,7 +88,7 @@ func getUserDashboardURL() (string, error) {
 
 func getSiteDashboardURL(siteID string) (string, error) {
 	// Verify site exists and get its ID
-	sitesService := api.NewSitesService(cliContext.APIClient)
+	sitesService := cliContext.API.Sites
 	site, err := sitesService.Get(getContext(), siteID)
 	if err != nil {
 		return "", fmt.Errorf("failed to get site info: %w", err)
@@ -105,14 +104,14 @@ func getEnvironmentDashboardURL(siteEnv string) (string, error) {
 	}
 
 	// Verify site exists and get its ID
-	sitesService := api.NewSitesService(cliContext.APIClient)
+	sitesService := cliContext.API.Sites
 	site, err := sitesService.Get(getContext(), siteID)
 	if err != nil {
 		return "", fmt.Errorf("failed to get site info: %w", err)
 	}
 
 	// Verify environment exists
-	envsService := api.NewEnvironmentsService(cliContext.APIClient)
+	envsService := cliContext.API.Environments
 	_, err = envsService.Get(getContext(), site.ID, envID)
 	if err != nil {
 		return "", fmt.Errorf("failed to get environment info: %w", err)
@@ -3,7 +3,6 @@ package commands
 import (
 	"fmt"
 
-	"github.com/deviantintegral/terminus-golang/pkg/api"
 	"github.com/spf13/cobra"
 )
 
@@ -54,7 +53,7 @@ func runDomainList(_ *cobra.Command, args []string) error {
 		return err
 	}
 
-	domainsService := api.NewDomainsService(cliContext.APIClient)
+	domainsService := cliContext.API.Domains
 
 	domains, err := domainsService.List(getContext(), siteID, envID)
 	if err != nil {
@@ -71,7 +70,7 @@ func runDomainAdd(_ *cobra.Command, args []string) error {
 	}
 
 	domain := args[1]
-	domainsService := api.NewDomainsService(cliContext.APIClient)
+	domainsService := cliContext.API.Domains
 
 	printMessage("Adding domain %s to %s.%s...", domain, siteID, envID)
 
@@ -98,7 +97,7 @@ func runDomainRemove(_ *cobra.Command, args []string) error {
 		return nil
 	}
 
-	domainsService := api.NewDomainsService(cliContext.APIClient)
+	domainsService := cliContext.API.Domains
 
 	printMessage("Removing domain %s from %s.%s...", domain, siteID, envID)
 
@@ -118,7 +117,7 @@ func runDomainDNS(_ *cobra.Command, args []string) error {
 	}
 
 	domain := args[1]
-	domainsService := api.NewDomainsService(cliContext.APIClient)
+	domainsService := cliContext.API.Domains
 
 	records, err := domainsService.GetDNS(getContext(), siteID, envID, domain)
 	if err != nil {
@@ -10,11 +10,11 @@ import (
 )
 
 var envListCmd = &cobra.Command{
-	Use:     "env:list <site>",
+	Use:     "env:list <site>...",
 	Aliases: []string{"environment"},
 	Short:   "List environments",
-	Long:    "Display a list of all environments for a site",
-	Args:    cobra.ExactArgs(1),
+	Long:    "Display a list of all environments for one or more sites",
+	Args:    cobra.MinimumNArgs(1),
 	RunE:    runEnvList,
 }
 
@@ -129,15 +129,41 @@ func init() {
 }
 
 func runEnvList(_ *cobra.Command, args []string) error {
-	siteID := args[0]
-	envsService := api.NewEnvironmentsService(cliContext.APIClient)
+	envsService := cliContext.API.Environments
 
-	envs, err := envsService.List(getContext(), siteID)
-	if err != nil {
-		return fmt.Errorf("failed to list environments: %w", err)
+	if len(args) == 1 {
+		envs, err := envsService.List(getContext(), args[0])
+		if err != nil {
+			return fmt.Errorf("failed to list environments: %w", err)
+		}
+		return printOutput(envs)
+	}
+
+	// Fetch environments for multiple sites concurrently, reporting failed
+	// sites without discarding the environments that were found
+	envs := make([]*models.Environment, 0)
+	var failed int
+	for i, result := range envsService.ListForSites(getContext(), args, api.DefaultConcurrency) {
+		if result.Err != nil {
+			printError("Failed to list environments for site %s: %v", args[i], result.Err)
+			failed++
+			continue
+		}
+		for _, env := range result.Value {
+			if env.SiteID == "" {
+				env.SiteID = args[i]
+			}
+			envs = append(envs, env)
+		}
 	}
 
-	return printOutput(envs)
+	if err := printOutput(envs); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to list environments for %d of %d sites", failed, len(args))
+	}
+	return nil
 }
 
 func runEnvInfo(_ *cobra.Command, args []string) error {
@@ -146,7 +172,7 @@ func runEnvInfo(_ *cobra.Command, args []string) error {
 		return err
 	}
 
-	envsService := api.NewEnvironmentsService(cliContext.APIClient)
+	envsService := cliContext.API.Environments
 
 	env, err := envsService.Get(getContext(), siteID, envID)
 	if err != nil {
@@ -167,7 +193,7 @@ func runEnvClearCache(_ *cobra.Command, args []string) error {
 		return nil
 	}
 
-	envsService := api.NewEnvironmentsService(cliContext.APIClient)
+	envsService := cliContext.API.Environments
 
 	printMessage("Clearing cache for %s.%s...", siteID, envID)
 
@@ -185,7 +211,7 @@ func runEnvDeploy(_ *cobra.Command, args []string) error {
 		return err
 	}
 
-	envsService := api.NewEnvironmentsService(cliContext.APIClient)
+	envsService := cliContext.API.Environments
 
 	req := &api.DeployRequest{
 		UpdateDB:   envUpdateDBFlag,
@@ -227,7 +253,7 @@ func runEnvCloneContent(_ *cobra.Command, args []string) error {
 		return nil
 	}
 
-	envsService := api.NewEnvironmentsService(cliContext.APIClient)
+	envsService := cliContext.API.Environments
 
 	req := &api.CloneContentRequest{
 		FromEnvironment: envFromEnvFlag,
@@ -251,7 +277,7 @@ func runEnvCommit(_ *cobra.Command, args []string) error {
 		return err
 	}
 
-	envsService := api.NewEnvironmentsService(cliContext.APIClient)
+	envsService := cliContext.API.Environments
 
 	req := &api.CommitRequest{
 		Message: envCommitMsgFlag,
@@ -278,7 +304,7 @@ func runEnvWipe(_ *cobra.Command, args []string) error {
 		return nil
 	}
 
-	envsService := api.NewEnvironmentsService(cliContext.APIClient)
+	envsService := cliContext.API.Environments
 
 	printMessage("Wiping %s.%s...", siteID, envID)
 
@@ -298,10 +324,10 @@ func runEnvConnectionSet(_ *cobra.Command, args []string) error {
 
 	mode := args[1]
 	if mode != "git" && mode != "sftp" {
-		return fmt.Errorf("invalid mode: %s (must be 'git' or 'sftp')", mode)
+		return newValidationError("invalid mode: %s (must be 'git' or 'sftp')", mode)
 	}
 
-	envsService := api.NewEnvironmentsService(cliContext.APIClient)
+	envsService := cliContext.API.Environments
 
 	printMessage("Setting connection mode to %s for %s.%s...", mode, siteID, envID)
 
@@ -336,7 +362,7 @@ func runEnvMetrics(_ *cobra.Command, args []string) error {
 		envID = ""
 	}
 
-	envsService := api.NewEnvironmentsService(cliContext.APIClient)
+	envsService := cliContext.API.Environments
 
 	metrics, err := envsService.GetMetrics(getContext(), siteID, envID, duration)
 	if err != nil {
@@ -368,7 +394,7 @@ func buildMetricsDuration(period, datapoints string) (string, error) {
 		shortForm = "m"
 		defaultDatapoints = 12
 	default:
-		return "", fmt.Errorf("invalid period: %s (must be 'day', 'week', or 'month')", period)
+		return "", newValidationError("invalid period: %s (must be 'day', 'week', or 'month')", period)
 	}
 
 	// Determine number of datapoints
@@ -378,7 +404,7 @@ func buildMetricsDuration(period, datapoints string) (string, error) {
 	} else {
 		n, err := fmt.Sscanf(datapoints, "%d", &numDatapoints)
 		if err != nil || n != 1 || numDatapoints < 1 {
-			return "", fmt.Errorf("invalid datapoints: %s (must be a positive number or 'auto')", datapoints)
+			return "", newValidationError("invalid datapoints: %s (must be a positive number or 'auto')", datapoints)
 		}
 	}
 
@@ -1,9 +1,148 @@
 package commands
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"iter"
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/deviantintegral/terminus-golang/pkg/api"
+	"github.com/deviantintegral/terminus-golang/pkg/api/apitest"
+	"github.com/deviantintegral/terminus-golang/pkg/api/models"
+	"github.com/deviantintegral/terminus-golang/pkg/output"
 )
 
+// useFake points the CLI context at an in-memory fake of the API and returns
+// the fake and the buffer that command output is written to
+func useFake(t *testing.T) (*apitest.Fake, *bytes.Buffer) {
+	t.Helper()
+
+	oldContext, oldQuiet := cliContext, quietFlag
+	t.Cleanup(func() { cliContext, quietFlag = oldContext, oldQuiet })
+
+	fake := apitest.NewFake()
+	var buf bytes.Buffer
+	cliContext = &CLIContext{
+		API:    fake.Pantheon(),
+		Output: &output.Options{Format: output.FormatJSON, Writer: &buf},
+	}
+	quietFlag = false
+
+	return fake, &buf
+}
+
+// useStdFile replaces *file, such as os.Stdin or os.Stderr, with a temporary
+// file holding input for the rest of the test. It returns a function that
+// reads back what the file holds.
+func useStdFile(t *testing.T, file **os.File, input string) func() string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "std")
+	if err := os.WriteFile(path, []byte(input), 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+	temp, err := os.OpenFile(path, os.O_RDWR, 0) //nolint:gosec // Test file
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", path, err)
+	}
+	old := *file
+	*file = temp
+	t.Cleanup(func() {
+		*file = old
+		_ = temp.Close()
+	})
+
+	return func() string {
+		data, err := os.ReadFile(path) //nolint:gosec // Test file
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", path, err)
+		}
+		return string(data)
+	}
+}
+
+func TestPrintStream_ClosesOnError(t *testing.T) {
+	_, buf := useFake(t)
+
+	wantErr := errors.New("page 2 failed")
+	items := iter.Seq2[map[string]string, error](func(yield func(map[string]string, error) bool) {
+		if yield(map[string]string{"name": "first"}, nil) {
+			yield(nil, wantErr)
+		}
+	})
+
+	if err := printStream(items); !errors.Is(err, wantErr) {
+		t.Fatalf("expected %v, got %v", wantErr, err)
+	}
+
+	var printed []map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &printed); err != nil {
+		t.Fatalf("expected a complete JSON array: %v\n%s", err, buf.String())
+	}
+	if len(printed) != 1 || printed[0]["name"] != "first" {
+		t.Errorf("unexpected output %v", printed)
+	}
+}
+
+func TestRunEnvList_MultipleSites(t *testing.T) {
+	fake, buf := useFake(t)
+	fake.AddSite("site-a")
+	fake.AddSite("site-b")
+	fake.AddEnvironment("site-b", "feature")
+
+	if err := runEnvList(nil, []string{"site-a", "site-b"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var envs []*models.Environment
+	if err := json.Unmarshal(buf.Bytes(), &envs); err != nil {
+		t.Fatalf("failed to parse output: %v\n%s", err, buf.String())
+	}
+	if len(envs) != 7 {
+		t.Errorf("expected 7 environments, got %d", len(envs))
+	}
+}
+
+func TestRunEnvList_FilterAndSort(t *testing.T) {
+	fake, buf := useFake(t)
+	fake.AddSite("site-a")
+	cliContext.Output.Filters = []string{"id!=live"}
+	cliContext.Output.Sort = []string{"id:desc"}
+
+	if err := runEnvList(nil, []string{"site-a"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var envs []*models.Environment
+	if err := json.Unmarshal(buf.Bytes(), &envs); err != nil {
+		t.Fatalf("failed to parse output: %v\n%s", err, buf.String())
+	}
+	if len(envs) != 2 || envs[0].ID != "test" || envs[1].ID != "dev" {
+		t.Errorf("expected test then dev, got %+v", envs)
+	}
+}
+
+func TestRunEnvList_PartialFailure(t *testing.T) {
+	fake, buf := useFake(t)
+	fake.AddSite("site-a")
+
+	err := runEnvList(nil, []string{"site-a", "missing"})
+	if err == nil || err.Error() != "failed to list environments for 1 of 2 sites" {
+		t.Fatalf("expected partial failure, got %v", err)
+	}
+
+	var envs []*models.Environment
+	if err := json.Unmarshal(buf.Bytes(), &envs); err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+	if len(envs) != 3 {
+		t.Errorf("expected the environments of the site that was found, got %d", len(envs))
+	}
+}
+
 func TestEnvMetricsCmdStructure(t *testing.T) {
 	if envMetricsCmd.Use != "env:metrics <site>[.<env>]" {
 		t.Errorf("expected envMetricsCmd.Use to be 'env:metrics <site>[.<env>]', got '%s'", envMetricsCmd.Use)
@@ -214,8 +353,8 @@ func TestFindEnvSeparator(t *testing.T) {
 }
 
 func TestEnvListCmdStructure(t *testing.T) {
-	if envListCmd.Use != "env:list <site>" {
-		t.Errorf("expected envListCmd.Use to be 'env:list <site>', got '%s'", envListCmd.Use)
+	if envListCmd.Use != "env:list <site>..." {
+		t.Errorf("expected envListCmd.Use to be 'env:list <site>...', got '%s'", envListCmd.Use)
 	}
 
 	// Check alias
@@ -267,3 +406,67 @@ func TestEnvCommands(t *testing.T) {
 		}
 	}
 }
+
+func TestRunEnvDeploy_ReadOnly(t *testing.T) {
+	oldContext, oldQuiet := cliContext, quietFlag
+	defer func() { cliContext, quietFlag = oldContext, oldQuiet }()
+
+	server := apitest.NewServer(t)
+	site := server.AddSite("my-site")
+	client := server.Client(api.WithReadOnly(true))
+	cliContext = &CLIContext{APIClient: client, API: api.NewPantheon(client), ReadOnly: true}
+	quietFlag = true
+
+	err := runEnvDeploy(nil, []string{site.Name + ".test"})
+	if !errors.Is(err, api.ErrReadOnly) {
+		t.Fatalf("expected read-only error, got %v", err)
+	}
+	if ExitCode(err) != ExitForbidden {
+		t.Errorf("expected exit code %d, got %d", ExitForbidden, ExitCode(err))
+	}
+	if workflows := server.Workflows(); len(workflows) != 0 {
+		t.Errorf("expected no workflows to start, got %d", len(workflows))
+	}
+}
+
+func TestRunEnvWipe_DryRun(t *testing.T) {
+	oldContext, oldQuiet := cliContext, quietFlag
+	defer func() { cliContext, quietFlag = oldContext, oldQuiet }()
+
+	server := apitest.NewServer(t)
+	site := server.AddSite("my-site")
+	client := server.Client(api.WithDryRun(true))
+	var buf bytes.Buffer
+	cliContext = &CLIContext{
+		APIClient: client,
+		API:       api.NewPantheon(client),
+		Output:    &output.Options{Format: output.FormatJSON, Writer: &buf},
+		DryRun:    true,
+	}
+	quietFlag = true
+
+	// The wipe and the wait for it succeed without anything being sent
+	if err := runEnvWipe(nil, []string{site.Name + ".dev"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if workflows := server.Workflows(); len(workflows) != 0 {
+		t.Errorf("expected no workflows to start, got %d", len(workflows))
+	}
+
+	// The plan is printed even with --quiet
+	if err := printPlan(nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var planned []api.PlannedRequest
+	if err := json.Unmarshal(buf.Bytes(), &planned); err != nil {
+		t.Fatalf("failed to parse plan: %v\n%s", err, buf.String())
+	}
+	if len(planned) != 1 || planned[0].Path != "/sites/my-site/environments/dev/workflows" {
+		t.Errorf("unexpected plan %+v", planned)
+	}
+
+	err := runEnvWipe(nil, []string{site.Name + ".nope"})
+	if err == nil || errors.Is(err, api.ErrDryRun) {
+		t.Errorf("expected an unknown environment to fail, got %v", err)
+	}
+}
@@ -0,0 +1,197 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/deviantintegral/terminus-golang/pkg/api"
+	"github.com/deviantintegral/terminus-golang/pkg/output"
+	"github.com/deviantintegral/terminus-golang/pkg/remote"
+	"github.com/spf13/cobra"
+)
+
+// Process exit codes. These are part of the CLI's contract with scripts and
+// are documented in the README, so existing values must not change.
+const (
+	ExitOK             = 0
+	ExitError          = 1
+	ExitValidation     = 2
+	ExitAuth           = 3
+	ExitForbidden      = 4
+	ExitNotFound       = 5
+	ExitConflict       = 6
+	ExitRateLimited    = 7
+	ExitServerError    = 8
+	ExitWorkflowFailed = 9
+	ExitTimeout        = 10
+	ExitCommandFailed  = 11
+	ExitCancelled      = 130
+)
+
+// errValidation is matched by errors that report invalid arguments or flags
+var errValidation = errors.New("invalid usage")
+
+// validationError reports invalid command-line input
+type validationError struct {
+	err error
+}
+
+// newValidationError creates an error for invalid command-line input
+func newValidationError(format string, args ...interface{}) error {
+	return &validationError{err: fmt.Errorf(format, args...)}
+}
+
+func (e *validationError) Error() string { return e.err.Error() }
+func (e *validationError) Unwrap() error { return e.err }
+
+// Is reports whether target is errValidation
+func (e *validationError) Is(target error) bool { return target == errValidation }
+
+// exitCodes maps errors to exit codes and the code names used in JSON error
+// output, checked in order so that cancellation wins over the API error it caused
+var exitCodes = []struct {
+	err  error
+	code int
+	name string
+}{
+	{context.Canceled, ExitCancelled, "cancelled"},
+	{api.ErrWorkflowTimeout, ExitTimeout, "timeout"},
+	{context.DeadlineExceeded, ExitTimeout, "timeout"},
+	{api.ErrWorkflowFailed, ExitWorkflowFailed, "workflow_failed"},
+	{errValidation, ExitValidation, "validation"},
+	{output.ErrUnknownField, ExitValidation, "validation"},
+	{api.ErrReadOnly, ExitForbidden, "read_only"},
+	{api.ErrUnauthorized, ExitAuth, "unauthorized"},
+	{api.ErrForbidden, ExitForbidden, "forbidden"},
+	{api.ErrNotFound, ExitNotFound, "not_found"},
+	{api.ErrConflict, ExitConflict, "conflict"},
+	{api.ErrRateLimited, ExitRateLimited, "rate_limited"},
+	{api.ErrBadRequest, ExitValidation, "validation"},
+	{api.ErrServer, ExitServerError, "server_error"},
+}
+
+// classifyError returns the exit code and code name for an error
+func classifyError(err error) (int, string) {
+	if err == nil {
+		return ExitOK, ""
+	}
+
+	// Remote commands and the clients started by the connection commands
+	// share one code, so that their own statuses can't be mistaken for ours;
+	// the status itself is in the error report
+	var remoteErr *remote.ExitError
+	if errors.As(err, &remoteErr) {
+		return ExitCommandFailed, "remote_command_failed"
+	}
+	if commandExitCode(err) > 0 {
+		return ExitCommandFailed, "command_failed"
+	}
+
+	for _, mapping := range exitCodes {
+		if errors.Is(err, mapping.err) {
+			return mapping.code, mapping.name
+		}
+	}
+
+	// HTTP client timeouts don't wrap context.DeadlineExceeded
+	var timeoutErr interface{ Timeout() bool }
+	if errors.As(err, &timeoutErr) && timeoutErr.Timeout() {
+		return ExitTimeout, "timeout"
+	}
+
+	return ExitError, "error"
+}
+
+// commandExitCode returns the exit status of a remote command or client that
+// failed, or 0 if err isn't one. Clients killed by a signal have no status.
+func commandExitCode(err error) int {
+	var remoteErr *remote.ExitError
+	if errors.As(err, &remoteErr) {
+		return remoteErr.Code
+	}
+	var clientErr *exec.ExitError
+	if errors.As(err, &clientErr) && clientErr.ExitCode() > 0 {
+		return clientErr.ExitCode()
+	}
+	return 0
+}
+
+// ExitCode returns the process exit code for an error returned by Execute
+func ExitCode(err error) int {
+	code, _ := classifyError(err)
+	return code
+}
+
+// errorReport is the JSON representation of a failed command
+type errorReport struct {
+	Code     string `json:"code"`
+	ExitCode int    `json:"exit_code,omitempty"`
+	Message  string `json:"message"`
+	// CommandExitCode is the status of the remote command or client that
+	// failed
+	CommandExitCode int    `json:"command_exit_code,omitempty"`
+	TraceID         string `json:"trace_id,omitempty"`
+	WorkflowID      string `json:"workflow_id,omitempty"`
+}
+
+// ReportError writes an error returned by Execute to w, as a JSON object when
+// --format=json is set and as text otherwise
+func ReportError(w io.Writer, err error) {
+	exitCode, name := classifyError(err)
+	report := errorReport{
+		Code:            name,
+		ExitCode:        exitCode,
+		Message:         err.Error(),
+		CommandExitCode: commandExitCode(err),
+		TraceID:         api.TraceID(err),
+		WorkflowID:      api.WorkflowID(err),
+	}
+
+	if output.Format(formatFlag) == output.FormatJSON {
+		_ = json.NewEncoder(w).Encode(report)
+		return
+	}
+
+	_, _ = fmt.Fprintf(w, "Error: %s\n", report.Message)
+	if report.WorkflowID != "" {
+		_, _ = fmt.Fprintf(w, "Workflow ID: %s\n", report.WorkflowID)
+	}
+	if report.TraceID != "" {
+		_, _ = fmt.Fprintf(w, "Trace ID: %s\n", report.TraceID)
+	}
+}
+
+// markValidationErrors makes argument and flag errors reported by cobra
+// validation errors, so they exit with ExitValidation
+func markValidationErrors(cmd *cobra.Command) {
+	cmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return &validationError{err: err}
+	})
+
+	if validate := cmd.Args; validate != nil {
+		cmd.Args = func(c *cobra.Command, args []string) error {
+			if err := validate(c, args); err != nil {
+				return &validationError{err: err}
+			}
+			return nil
+		}
+	}
+
+	for _, sub := range cmd.Commands() {
+		markValidationErrors(sub)
+	}
+}
+
+// isCobraUsageError reports whether err is one of cobra's own usage errors
+// that can't be intercepted through a hook
+func isCobraUsageError(err error) bool {
+	msg := err.Error()
+	return strings.HasPrefix(msg, "unknown command") ||
+		strings.HasPrefix(msg, "required flag(s)") ||
+		strings.HasPrefix(msg, "if any flags in the group")
+}
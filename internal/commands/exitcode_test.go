@@ -0,0 +1,160 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"testing"
+
+	"github.com/deviantintegral/terminus-golang/pkg/api"
+	"github.com/deviantintegral/terminus-golang/pkg/output"
+	"github.com/deviantintegral/terminus-golang/pkg/remote"
+	"github.com/spf13/cobra"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, ExitOK},
+		{"generic", errors.New("boom"), ExitError},
+		{"unauthorized", &api.Error{StatusCode: http.StatusUnauthorized}, ExitAuth},
+		{"forbidden", &api.Error{StatusCode: http.StatusForbidden}, ExitForbidden},
+		{"wrapped not found", fmt.Errorf("failed to get site: %w", &api.Error{StatusCode: http.StatusNotFound}), ExitNotFound},
+		{"conflict", &api.Error{StatusCode: http.StatusConflict}, ExitConflict},
+		{"rate limited", fmt.Errorf("request failed after 6 attempts: %w", &api.Error{StatusCode: http.StatusTooManyRequests}), ExitRateLimited},
+		{"bad request", &api.Error{StatusCode: http.StatusBadRequest}, ExitValidation},
+		{"server error", &api.Error{StatusCode: http.StatusServiceUnavailable}, ExitServerError},
+		{"validation", newValidationError("invalid mode: %s", "ftp"), ExitValidation},
+		{"unknown filter field", fmt.Errorf("%w %q", output.ErrUnknownField, "plna"), ExitValidation},
+		{"workflow failed", fmt.Errorf("Deploying failed: %w", &api.WorkflowError{WorkflowID: "wf1", Message: "boom"}), ExitWorkflowFailed},
+		{"workflow timeout", fmt.Errorf("workflow wait failed: %w", &api.WorkflowError{WorkflowID: "wf1", Timeout: true}), ExitTimeout},
+		{"deadline", fmt.Errorf("request failed: %w", context.DeadlineExceeded), ExitTimeout},
+		{"cancelled API call", fmt.Errorf("request failed: %w", context.Canceled), ExitCancelled},
+		{"interrupted wait", fmt.Errorf("workflow wait failed: %w", &api.WorkflowError{WorkflowID: "wf1", Err: context.Canceled}), ExitCancelled},
+		{"read-only", fmt.Errorf("failed to deploy: %w", &api.ReadOnlyError{Method: http.MethodPost, Path: "/api/sites/x/workflows"}), ExitForbidden},
+	}
+
+	// Clients started by connection commands and remote commands have their
+	// own code, whatever their status
+	clientErr := exec.Command("sh", "-c", "exit 3").Run()
+	tests = append(tests, []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"client exit status", fmt.Errorf("mysql %w", clientErr), ExitCommandFailed},
+		{"remote exit status", &remote.ExitError{Command: "drush", Code: ExitNotFound}, ExitCommandFailed},
+	}...)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.want {
+				t.Errorf("ExitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReportErrorJSON(t *testing.T) {
+	oldFormat := formatFlag
+	formatFlag = "json"
+	defer func() { formatFlag = oldFormat }()
+
+	err := fmt.Errorf("Deploying failed: %w", &api.WorkflowError{WorkflowID: "wf-123", Message: "merge conflict"})
+
+	var buf bytes.Buffer
+	ReportError(&buf, err)
+
+	var report map[string]interface{}
+	if jsonErr := json.Unmarshal(buf.Bytes(), &report); jsonErr != nil {
+		t.Fatalf("expected JSON output, got %q: %v", buf.String(), jsonErr)
+	}
+
+	expected := map[string]interface{}{
+		"code":        "workflow_failed",
+		"exit_code":   float64(ExitWorkflowFailed),
+		"message":     "Deploying failed: merge conflict",
+		"workflow_id": "wf-123",
+	}
+	for key, want := range expected {
+		if report[key] != want {
+			t.Errorf("%s = %v, want %v", key, report[key], want)
+		}
+	}
+	if _, ok := report["trace_id"]; ok {
+		t.Error("expected trace_id to be omitted when unknown")
+	}
+}
+
+func TestReportErrorJSON_CommandExitCode(t *testing.T) {
+	oldFormat := formatFlag
+	formatFlag = "json"
+	defer func() { formatFlag = oldFormat }()
+
+	var buf bytes.Buffer
+	ReportError(&buf, &remote.ExitError{Command: "wp", Code: 3})
+
+	var report map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("expected JSON output, got %q: %v", buf.String(), err)
+	}
+	if report["code"] != "remote_command_failed" || report["exit_code"] != float64(ExitCommandFailed) || report["command_exit_code"] != float64(3) {
+		t.Errorf("unexpected report: %v", report)
+	}
+}
+
+func TestReportErrorText(t *testing.T) {
+	oldFormat := formatFlag
+	formatFlag = "table"
+	defer func() { formatFlag = oldFormat }()
+
+	var buf bytes.Buffer
+	ReportError(&buf, fmt.Errorf("failed to get site: %w", &api.Error{StatusCode: http.StatusNotFound, Message: "not found", TraceID: "trace-1"}))
+
+	expected := "Error: failed to get site: API error 404: not found\nTrace ID: trace-1\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestMarkValidationErrors(t *testing.T) {
+	newCmd := func() *cobra.Command {
+		root := &cobra.Command{Use: "root", SilenceErrors: true, SilenceUsage: true}
+		root.AddCommand(&cobra.Command{
+			Use:  "child <arg>",
+			Args: cobra.ExactArgs(1),
+			RunE: func(_ *cobra.Command, _ []string) error { return nil },
+		})
+		markValidationErrors(root)
+		return root
+	}
+
+	tests := map[string][]string{
+		"wrong arg count": {"child"},
+		"unknown flag":    {"child", "a", "--bogus"},
+	}
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			root := newCmd()
+			root.SetArgs(args)
+			err := root.Execute()
+			if ExitCode(err) != ExitValidation {
+				t.Errorf("expected validation exit code, got %d (%v)", ExitCode(err), err)
+			}
+		})
+	}
+
+	if !isCobraUsageError(errors.New(`required flag(s) "message" not set`)) {
+		t.Error("expected required flag error to be a usage error")
+	}
+	if isCobraUsageError(errors.New("failed to get site")) {
+		t.Error("expected other errors not to be usage errors")
+	}
+}
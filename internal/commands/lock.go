@@ -3,7 +3,6 @@ package commands
 import (
 	"fmt"
 
-	"github.com/deviantintegral/terminus-golang/pkg/api"
 	"github.com/spf13/cobra"
 )
 
@@ -55,7 +54,7 @@ func runLockInfo(_ *cobra.Command, args []string) error {
 		return err
 	}
 
-	envsService := api.NewEnvironmentsService(cliContext.APIClient)
+	envsService := cliContext.API.Environments
 
 	lock, err := envsService.GetLock(getContext(), siteID, envID)
 	if err != nil {
@@ -71,7 +70,7 @@ func runLockEnable(_ *cobra.Command, args []string) error {
 		return err
 	}
 
-	envsService := api.NewEnvironmentsService(cliContext.APIClient)
+	envsService := cliContext.API.Environments
 
 	printMessage("Enabling lock for %s.%s...", siteID, envID)
 
@@ -95,7 +94,7 @@ func runLockDisable(_ *cobra.Command, args []string) error {
 		return nil
 	}
 
-	envsService := api.NewEnvironmentsService(cliContext.APIClient)
+	envsService := cliContext.API.Environments
 
 	printMessage("Disabling lock for %s.%s...", siteID, envID)
 
@@ -3,7 +3,6 @@ package commands
 import (
 	"fmt"
 
-	"github.com/deviantintegral/terminus-golang/pkg/api"
 	"github.com/spf13/cobra"
 )
 
@@ -28,7 +27,7 @@ func runMachineTokenList(_ *cobra.Command, _ []string) error {
 		return fmt.Errorf("no user ID in session")
 	}
 
-	usersService := api.NewUsersService(cliContext.APIClient)
+	usersService := cliContext.API.Users
 
 	tokens, err := usersService.ListMachineTokens(getContext(), sess.UserID)
 	if err != nil {
@@ -3,7 +3,6 @@ package commands
 import (
 	"fmt"
 
-	"github.com/deviantintegral/terminus-golang/pkg/api"
 	"github.com/spf13/cobra"
 )
 
@@ -68,7 +67,7 @@ func init() {
 
 func runMultidevList(_ *cobra.Command, args []string) error {
 	siteID := args[0]
-	multidevService := api.NewMultidevService(cliContext.APIClient)
+	multidevService := cliContext.API.Multidev
 
 	multidevs, err := multidevService.List(getContext(), siteID)
 	if err != nil {
@@ -89,7 +88,7 @@ func runMultidevCreate(_ *cobra.Command, args []string) error {
 		return err
 	}
 
-	multidevService := api.NewMultidevService(cliContext.APIClient)
+	multidevService := cliContext.API.Multidev
 
 	printMessage("Creating multidev %s from %s...", envName, multidevFromEnvFlag)
 
@@ -112,7 +111,7 @@ func runMultidevDelete(_ *cobra.Command, args []string) error {
 		return nil
 	}
 
-	multidevService := api.NewMultidevService(cliContext.APIClient)
+	multidevService := cliContext.API.Multidev
 
 	printMessage("Deleting multidev %s.%s...", siteID, envID)
 
@@ -130,7 +129,7 @@ func runMultidevMergeToDev(_ *cobra.Command, args []string) error {
 		return err
 	}
 
-	multidevService := api.NewMultidevService(cliContext.APIClient)
+	multidevService := cliContext.API.Multidev
 
 	printMessage("Merging %s.%s to dev...", siteID, envID)
 
@@ -148,7 +147,7 @@ func runMultidevMergeFromDev(_ *cobra.Command, args []string) error {
 		return err
 	}
 
-	multidevService := api.NewMultidevService(cliContext.APIClient)
+	multidevService := cliContext.API.Multidev
 
 	printMessage("Merging dev into %s.%s...", siteID, envID)
 
@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/deviantintegral/terminus-golang/pkg/api"
+)
+
+// useFromEnv sets the environment multidevs are created from for the rest of the test
+func useFromEnv(t *testing.T, env string) {
+	t.Helper()
+
+	oldFromEnv := multidevFromEnvFlag
+	multidevFromEnvFlag = env
+	t.Cleanup(func() { multidevFromEnvFlag = oldFromEnv })
+}
+
+func TestRunMultidevCreate(t *testing.T) {
+	fake, _ := useFake(t)
+	fake.AddSite("my-site")
+	quietFlag = true
+	useFromEnv(t, "dev")
+
+	if err := runMultidevCreate(nil, []string{"my-site.feature"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	multidevs, err := cliContext.API.Multidev.List(getContext(), "my-site")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(multidevs) != 1 || multidevs[0].ID != "feature" {
+		t.Errorf("expected feature multidev, got %+v", multidevs)
+	}
+}
+
+func TestRunMultidevCreate_WorkflowFails(t *testing.T) {
+	fake, _ := useFake(t)
+	fake.AddSite("my-site")
+	fake.FailWorkflows("create_cloud_development_environment", "Branch already exists")
+	quietFlag = true
+	useFromEnv(t, "dev")
+
+	err := runMultidevCreate(nil, []string{"my-site.feature"})
+	if !errors.Is(err, api.ErrWorkflowFailed) {
+		t.Fatalf("expected failed workflow error, got %v", err)
+	}
+	if ExitCode(err) != ExitWorkflowFailed {
+		t.Errorf("expected exit code %d, got %d", ExitWorkflowFailed, ExitCode(err))
+	}
+}
@@ -1,9 +1,14 @@
 package commands
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/deviantintegral/terminus-golang/pkg/api"
+	"github.com/deviantintegral/terminus-golang/pkg/api/models"
 	"github.com/spf13/cobra"
 )
 
@@ -31,6 +36,40 @@ var orgPeopleListCmd = &cobra.Command{
 	RunE:  runOrgPeopleList,
 }
 
+var orgPeopleAddCmd = &cobra.Command{
+	Use:   "org:people:add <org> <email>",
+	Short: "Add an organization member",
+	Long:  "Add a user to an organization by email address",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runOrgPeopleAdd,
+}
+
+var orgPeopleRemoveCmd = &cobra.Command{
+	Use:   "org:people:remove <org> <member>",
+	Short: "Remove an organization member",
+	Long:  "Remove a user, given by email address or user ID, from an organization",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runOrgPeopleRemove,
+}
+
+var orgPeopleRoleCmd = &cobra.Command{
+	Use:   "org:people:role <org> <member> <role>",
+	Short: "Change an organization member's role",
+	Long:  "Change the role (admin, developer, team_member or unprivileged) of an organization member, given by email address or user ID",
+	Args:  cobra.ExactArgs(3),
+	RunE:  runOrgPeopleRole,
+}
+
+var orgPeopleOffboardCmd = &cobra.Command{
+	Use:   "org:people:offboard <email>",
+	Short: "Remove a user from all your organizations and sites",
+	Long:  "Remove a user from every organization you administer and every site team you own or administer through an organization, after showing what will be removed",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runOrgPeopleOffboard,
+}
+
+var orgPeopleRoleFlag string
+
 var orgSiteListCmd = &cobra.Command{
 	Use:   "org:site:list <org>",
 	Short: "List organization sites",
@@ -52,8 +91,14 @@ func init() {
 	rootCmd.AddCommand(orgListCmd)
 	rootCmd.AddCommand(orgInfoCmd)
 	rootCmd.AddCommand(orgPeopleListCmd)
+	rootCmd.AddCommand(orgPeopleAddCmd)
+	rootCmd.AddCommand(orgPeopleRemoveCmd)
+	rootCmd.AddCommand(orgPeopleRoleCmd)
+	rootCmd.AddCommand(orgPeopleOffboardCmd)
 	rootCmd.AddCommand(orgSiteListCmd)
 	rootCmd.AddCommand(orgUpstreamsListCmd)
+
+	orgPeopleAddCmd.Flags().StringVar(&orgPeopleRoleFlag, "role", "team_member", "Role of the new member (admin, developer, team_member or unprivileged)")
 }
 
 func runOrgList(_ *cobra.Command, _ []string) error {
@@ -66,19 +111,18 @@ func runOrgList(_ *cobra.Command, _ []string) error {
 		return fmt.Errorf("no user ID in session")
 	}
 
-	orgsService := api.NewOrganizationsService(cliContext.APIClient)
+	orgsService := cliContext.API.Organizations
 
-	orgs, err := orgsService.List(getContext(), sess.UserID)
-	if err != nil {
+	if err := printStream(orgsService.ListIter(getContext(), sess.UserID)); err != nil {
 		return fmt.Errorf("failed to list organizations: %w", err)
 	}
 
-	return printOutput(orgs)
+	return nil
 }
 
 func runOrgInfo(_ *cobra.Command, args []string) error {
 	orgID := args[0]
-	orgsService := api.NewOrganizationsService(cliContext.APIClient)
+	orgsService := cliContext.API.Organizations
 
 	org, err := orgsService.Get(getContext(), orgID)
 	if err != nil {
@@ -90,31 +134,119 @@ func runOrgInfo(_ *cobra.Command, args []string) error {
 
 func runOrgPeopleList(_ *cobra.Command, args []string) error {
 	orgID := args[0]
-	orgsService := api.NewOrganizationsService(cliContext.APIClient)
+	orgsService := cliContext.API.Organizations
 
-	members, err := orgsService.ListMembers(getContext(), orgID)
-	if err != nil {
+	if err := printStream(orgsService.ListMembersIter(getContext(), orgID)); err != nil {
 		return fmt.Errorf("failed to list organization members: %w", err)
 	}
 
-	return printOutput(members)
+	return nil
 }
 
-func runOrgSiteList(_ *cobra.Command, args []string) error {
+// orgRoles are the roles an organization member can be given
+var orgRoles = []string{"admin", "developer", "team_member", "unprivileged"}
+
+func validateOrgRole(role string) error {
+	if !slices.Contains(orgRoles, role) {
+		return newValidationError("invalid role: %s (must be one of %s)", role, strings.Join(orgRoles, ", "))
+	}
+	return nil
+}
+
+// getOrgMember looks up a member of an organization by email address or
+// user ID
+func getOrgMember(orgID, member string) (*models.OrganizationMember, error) {
+	members, err := cliContext.API.Organizations.ListMemberships(getContext(), orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organization members: %w", err)
+	}
+	for _, m := range members {
+		if m.ID == member || strings.EqualFold(m.Email, member) {
+			return m, nil
+		}
+	}
+	return nil, fmt.Errorf("%s is not a member of organization %s: %w", member, orgID, api.ErrNotFound)
+}
+
+func runOrgPeopleAdd(_ *cobra.Command, args []string) error {
+	orgID, email := args[0], args[1]
+	if err := validateOrgRole(orgPeopleRoleFlag); err != nil {
+		return err
+	}
+
+	printMessage("Adding %s to organization %s as %s...", email, orgID, orgPeopleRoleFlag)
+
+	member, err := cliContext.API.Organizations.AddMember(getContext(), orgID, &api.AddOrganizationMemberRequest{
+		Email: email,
+		Role:  orgPeopleRoleFlag,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add organization member: %w", err)
+	}
+
+	return printOutput(member)
+}
+
+func runOrgPeopleRemove(_ *cobra.Command, args []string) error {
 	orgID := args[0]
-	sitesService := api.NewSitesService(cliContext.APIClient)
 
-	sites, err := sitesService.ListByOrganization(getContext(), orgID)
+	member, err := getOrgMember(orgID, args[1])
+	if err != nil {
+		return err
+	}
+
+	if !confirm(fmt.Sprintf("Are you sure you want to remove %s from organization %s?", member.Email, orgID)) {
+		printMessage("Canceled")
+		return nil
+	}
+
+	printMessage("Removing %s from organization %s...", member.Email, orgID)
+
+	if err := cliContext.API.Organizations.RemoveMember(getContext(), orgID, member.ID); err != nil {
+		return fmt.Errorf("failed to remove organization member: %w", err)
+	}
+
+	printMessage("Organization member removed successfully")
+
+	return nil
+}
+
+func runOrgPeopleRole(_ *cobra.Command, args []string) error {
+	orgID, role := args[0], args[2]
+	if err := validateOrgRole(role); err != nil {
+		return err
+	}
+
+	member, err := getOrgMember(orgID, args[1])
 	if err != nil {
+		return err
+	}
+
+	printMessage("Changing the role of %s in organization %s to %s...", member.Email, orgID, role)
+
+	if err := cliContext.API.Organizations.UpdateMemberRole(getContext(), orgID, member.ID, role); err != nil {
+		return fmt.Errorf("failed to change organization member role: %w", err)
+	}
+
+	printMessage("Organization member role changed successfully")
+
+	return nil
+}
+
+func runOrgSiteList(_ *cobra.Command, args []string) error {
+	orgID := args[0]
+	sitesService := cliContext.API.Sites
+
+	if err := printStream(sitesService.ListByOrganizationIter(getContext(), orgID)); err != nil {
 		return fmt.Errorf("failed to list organization sites: %w", err)
 	}
 
-	return printOutput(sites)
+	return nil
 }
 
 func runOrgUpstreamsList(_ *cobra.Command, args []string) error {
 	orgID := args[0]
-	orgsService := api.NewOrganizationsService(cliContext.APIClient)
+	orgsService := cliContext.API.Organizations
 
 	upstreams, err := orgsService.ListUpstreams(getContext(), orgID)
 	if err != nil {
@@ -123,3 +255,180 @@ func runOrgUpstreamsList(_ *cobra.Command, args []string) error {
 
 	return printOutput(upstreams)
 }
+
+// offboardMembership is an organization or site team membership that
+// org:people:offboard removes
+type offboardMembership struct {
+	Type   string `json:"type" yaml:"type"`
+	Name   string `json:"name" yaml:"name"`
+	Role   string `json:"role" yaml:"role"`
+	Result string `json:"result" yaml:"result"`
+
+	id     string
+	userID string
+}
+
+func runOrgPeopleOffboard(_ *cobra.Command, args []string) error {
+	email := args[0]
+
+	// Load session to get user ID
+	sess, err := cliContext.SessionStore.LoadSession()
+	if err != nil {
+		return fmt.Errorf("failed to load session: %w", err)
+	}
+	if sess == nil || sess.UserID == "" {
+		return fmt.Errorf("no user ID in session")
+	}
+	if strings.EqualFold(email, sess.Email) {
+		return newValidationError("you can't offboard yourself")
+	}
+
+	memberships, err := findOffboardMemberships(sess.UserID, email)
+	if err != nil {
+		return err
+	}
+	if len(memberships) == 0 {
+		printMessage("%s isn't a member of any organization or site team you administer", email)
+		return nil
+	}
+
+	// The preview is only needed when there is a prompt to answer, and goes
+	// to stderr so that stdout only holds the report
+	if !yesFlag {
+		if err := printPreview(memberships); err != nil {
+			return err
+		}
+	}
+	if !confirm(fmt.Sprintf("Remove %s from these %d memberships?", email, len(memberships))) {
+		printMessage("Canceled")
+		return nil
+	}
+
+	attempted, failed := 0, 0
+	for _, m := range memberships {
+		if m.Result != "" {
+			continue
+		}
+		attempted++
+		if m.Type == "organization" {
+			err = cliContext.API.Organizations.RemoveMember(getContext(), m.id, m.userID)
+		} else {
+			err = cliContext.API.Sites.RemoveTeamMember(getContext(), m.id, m.userID)
+		}
+		switch {
+		case errors.Is(err, api.ErrReadOnly):
+			return err
+		case err != nil:
+			failed++
+			m.Result = "failed: " + err.Error()
+		default:
+			m.Result = "removed"
+		}
+	}
+
+	if err := printOutput(memberships); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to remove %s from %d of %d memberships", email, failed, attempted)
+	}
+	return nil
+}
+
+// findOffboardMemberships returns the memberships of email in the
+// organizations userID administers and in the teams of the sites userID owns
+// or that belong to those organizations. Site owners can't be removed from
+// their own sites, so those memberships are marked as skipped.
+func findOffboardMemberships(userID, email string) ([]*offboardMembership, error) {
+	orgs, err := cliContext.API.Organizations.List(getContext(), userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+
+	var memberships []*offboardMembership
+	var adminOrgs []*models.Organization
+	results := api.ForEach(getContext(), orgs, api.DefaultConcurrency,
+		func(ctx context.Context, org *models.Organization) ([]*models.OrganizationMember, error) {
+			return cliContext.API.Organizations.ListMemberships(ctx, org.ID)
+		})
+	for i, result := range results {
+		org := orgs[i]
+		if result.Err != nil {
+			return nil, fmt.Errorf("failed to list members of organization %s: %w", org.Label, result.Err)
+		}
+		if !slices.ContainsFunc(result.Value, func(m *models.OrganizationMember) bool { return m.ID == userID && m.Role == "admin" }) {
+			continue
+		}
+		adminOrgs = append(adminOrgs, org)
+		for _, m := range result.Value {
+			if strings.EqualFold(m.Email, email) {
+				memberships = append(memberships, &offboardMembership{Type: "organization", Name: org.Label, Role: m.Role, id: org.ID, userID: m.ID})
+			}
+		}
+	}
+
+	sites, err := offboardSites(userID, adminOrgs)
+	if err != nil {
+		return nil, err
+	}
+	teams := api.ForEach(getContext(), sites, api.DefaultConcurrency,
+		func(ctx context.Context, site *models.Site) ([]*models.TeamMember, error) {
+			return cliContext.API.Sites.GetTeam(ctx, site.ID)
+		})
+	for i, result := range teams {
+		site := sites[i]
+		if result.Err != nil {
+			return nil, fmt.Errorf("failed to get team of site %s: %w", site.Name, result.Err)
+		}
+		member := findTeamMember(result.Value, email)
+		if member == nil {
+			continue
+		}
+		m := &offboardMembership{Type: "site", Name: site.Name, Role: member.Role, id: site.ID, userID: member.ID}
+		if member.Role == "owner" {
+			m.Result = "skipped: owns the site"
+		}
+		memberships = append(memberships, m)
+	}
+
+	return memberships, nil
+}
+
+// offboardSites returns the sites userID owns and the sites of adminOrgs,
+// without duplicates
+func offboardSites(userID string, adminOrgs []*models.Organization) ([]*models.Site, error) {
+	userSites, err := cliContext.API.Sites.List(getContext(), userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user sites: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var sites []*models.Site
+	add := func(site *models.Site) {
+		if !seen[site.ID] {
+			seen[site.ID] = true
+			sites = append(sites, site)
+		}
+	}
+
+	for _, site := range userSites {
+		if site.Owner == userID {
+			add(site)
+		}
+	}
+
+	results := api.ForEach(getContext(), adminOrgs, api.DefaultConcurrency,
+		func(ctx context.Context, org *models.Organization) ([]*models.Site, error) {
+			return cliContext.API.Sites.ListByOrganization(ctx, org.ID)
+		})
+	for i, result := range results {
+		if result.Err != nil {
+			return nil, fmt.Errorf("failed to list sites of organization %s: %w", adminOrgs[i].Label, result.Err)
+		}
+		for _, site := range result.Value {
+			add(site)
+		}
+	}
+
+	return sites, nil
+}
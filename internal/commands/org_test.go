@@ -0,0 +1,191 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/deviantintegral/terminus-golang/pkg/api"
+	"github.com/deviantintegral/terminus-golang/pkg/api/apitest"
+	"github.com/deviantintegral/terminus-golang/pkg/session"
+)
+
+// useSession logs the fake's user in for the rest of the test
+func useSession(t *testing.T, fake *apitest.Fake) {
+	t.Helper()
+
+	store := session.NewStore(t.TempDir())
+	if err := store.SaveSession(&session.Session{SessionToken: "token", UserID: fake.UserID, Email: fake.Email}); err != nil {
+		t.Fatalf("failed to save session: %v", err)
+	}
+	cliContext.SessionStore = store
+}
+
+// orgRolesOf returns the roles of an organization's members by email address
+func orgRolesOf(t *testing.T, orgID string) map[string]string {
+	t.Helper()
+
+	members, err := cliContext.API.Organizations.ListMemberships(getContext(), orgID)
+	if err != nil {
+		t.Fatalf("failed to list members: %v", err)
+	}
+	roles := make(map[string]string, len(members))
+	for _, member := range members {
+		roles[member.Email] = member.Role
+	}
+	return roles
+}
+
+func TestRunOrgPeopleAddRoleRemove(t *testing.T) {
+	fake, _ := useFake(t)
+	org := fake.AddOrganization("my-org", "My Org")
+	useYes(t)
+
+	orgPeopleRoleFlag = "developer"
+	t.Cleanup(func() { orgPeopleRoleFlag = "team_member" })
+	if err := runOrgPeopleAdd(nil, []string{org.ID, "dev@example.com"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if role := orgRolesOf(t, org.ID)["dev@example.com"]; role != "developer" {
+		t.Errorf("expected developer role, got %q", role)
+	}
+
+	if err := runOrgPeopleRole(nil, []string{org.ID, "Dev@example.com", "admin"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if role := orgRolesOf(t, org.ID)["dev@example.com"]; role != "admin" {
+		t.Errorf("expected admin role, got %q", role)
+	}
+
+	if err := runOrgPeopleRemove(nil, []string{org.ID, "dev@example.com"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := orgRolesOf(t, org.ID)["dev@example.com"]; ok {
+		t.Error("expected member to be removed")
+	}
+
+	if err := runOrgPeopleRemove(nil, []string{org.ID, "dev@example.com"}); !errors.Is(err, api.ErrNotFound) {
+		t.Errorf("expected not found error, got %v", err)
+	}
+	if err := runOrgPeopleRole(nil, []string{org.ID, "dev@example.com", "owner"}); !errors.Is(err, errValidation) {
+		t.Errorf("expected a validation error, got %v", err)
+	}
+}
+
+func TestRunOrgPeopleOffboard(t *testing.T) {
+	fake, buf := useFake(t)
+	useSession(t, fake)
+	useYes(t)
+
+	// The user administers one organization and is only a member of another
+	admin := fake.AddOrganization("agency", "Agency")
+	fake.AddOrganizationMember(admin.ID, fake.Email, "admin")
+	fake.AddOrganizationMember(admin.ID, "leaver@example.com", "developer")
+	other := fake.AddOrganization("client", "Client")
+	fake.AddOrganizationMember(other.ID, fake.Email, "team_member")
+	fake.AddOrganizationMember(other.ID, "leaver@example.com", "developer")
+
+	// The leaver is removed from the teams of sites the user owns and of
+	// sites of the administered organization, but not from a site they own
+	fake.AddSite("owned")
+	fake.AddTeamMember("owned", "leaver@example.com", "team_member")
+	orgSite := fake.AddSite("agency-site")
+	fake.AddSiteToOrganization(admin.ID, orgSite.ID)
+	fake.AddTeamMember("agency-site", "leaver@example.com", "developer")
+	fake.AddSite("untouched")
+	fake.AddSite("their-site")
+	fake.AddTeamMember("their-site", "leaver@example.com", "owner")
+
+	if err := runOrgPeopleOffboard(nil, []string{"leaver@example.com"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var report []map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("failed to parse report: %v\n%s", err, buf.String())
+	}
+	var got []string
+	for _, item := range report {
+		got = append(got, item["type"]+" "+item["name"]+": "+item["result"])
+	}
+	want := []string{
+		"organization Agency: removed",
+		"site owned: removed",
+		"site agency-site: removed",
+		"site their-site: skipped: owns the site",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got report\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if _, ok := orgRolesOf(t, admin.ID)["leaver@example.com"]; ok {
+		t.Error("expected leaver to be removed from the administered organization")
+	}
+	if _, ok := orgRolesOf(t, other.ID)["leaver@example.com"]; !ok {
+		t.Error("expected leaver to stay in the organization the user doesn't administer")
+	}
+	for _, site := range []string{"owned", "agency-site"} {
+		if _, ok := teamRoles(t, site)["leaver@example.com"]; ok {
+			t.Errorf("expected leaver to be removed from %s", site)
+		}
+	}
+}
+
+func TestRunOrgPeopleOffboard_Prompt(t *testing.T) {
+	fake, buf := useFake(t)
+	useSession(t, fake)
+
+	fake.AddSite("owned")
+	fake.AddTeamMember("owned", "leaver@example.com", "team_member")
+
+	// Answer the prompt and capture the preview
+	useStdFile(t, &os.Stdin, "y\n")
+	stderr := useStdFile(t, &os.Stderr, "")
+
+	if err := runOrgPeopleOffboard(nil, []string{"leaver@example.com"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	preview := stderr()
+	if !strings.Contains(preview, "owned") || !strings.Contains(preview, "[y/N]") || strings.Contains(preview, "removed") {
+		t.Errorf("expected the preview and prompt on stderr, got %s", preview)
+	}
+
+	// Only the report is on stdout
+	var report []map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("failed to parse report: %v\n%s", err, buf.String())
+	}
+	if len(report) != 1 || report[0]["result"] != "removed" {
+		t.Errorf("unexpected report: %+v", report)
+	}
+}
+
+func TestRunOrgPeopleOffboard_ReportsFailures(t *testing.T) {
+	fake, buf := useFake(t)
+	useSession(t, fake)
+	useYes(t)
+
+	fake.AddSite("first")
+	fake.AddTeamMember("first", "leaver@example.com", "team_member")
+	fake.FailMethod("Sites.RemoveTeamMember", api.ErrForbidden)
+
+	err := runOrgPeopleOffboard(nil, []string{"leaver@example.com"})
+	if err == nil || !strings.Contains(err.Error(), "1 of 1") {
+		t.Fatalf("expected a failure, got %v", err)
+	}
+	if !strings.Contains(buf.String(), "failed: forbidden") {
+		t.Errorf("expected the failure in the report, got %s", buf.String())
+	}
+}
+
+func TestRunOrgPeopleOffboard_Self(t *testing.T) {
+	fake, _ := useFake(t)
+	useSession(t, fake)
+
+	if err := runOrgPeopleOffboard(nil, []string{fake.Email}); !errors.Is(err, errValidation) {
+		t.Errorf("expected a validation error, got %v", err)
+	}
+}
@@ -3,7 +3,6 @@ package commands
 import (
 	"fmt"
 
-	"github.com/deviantintegral/terminus-golang/pkg/api"
 	"github.com/spf13/cobra"
 )
 
@@ -28,7 +27,7 @@ func runPaymentMethodList(_ *cobra.Command, _ []string) error {
 		return fmt.Errorf("no user ID in session")
 	}
 
-	usersService := api.NewUsersService(cliContext.APIClient)
+	usersService := cliContext.API.Users
 
 	methods, err := usersService.ListPaymentMethods(getContext(), sess.UserID)
 	if err != nil {
@@ -3,7 +3,6 @@ package commands
 import (
 	"fmt"
 
-	"github.com/deviantintegral/terminus-golang/pkg/api"
 	"github.com/spf13/cobra"
 )
 
@@ -32,7 +31,7 @@ func init() {
 func runPlanInfo(_ *cobra.Command, args []string) error {
 	siteID := args[0]
 
-	sitesService := api.NewSitesService(cliContext.APIClient)
+	sitesService := cliContext.API.Sites
 
 	plan, err := sitesService.GetPlan(getContext(), siteID)
 	if err != nil {
@@ -44,7 +43,7 @@ func runPlanInfo(_ *cobra.Command, args []string) error {
 
 func runPlanList(_ *cobra.Command, args []string) error {
 	siteID := args[0]
-	sitesService := api.NewSitesService(cliContext.APIClient)
+	sitesService := cliContext.API.Sites
 
 	plans, err := sitesService.GetPlans(getContext(), siteID)
 	if err != nil {
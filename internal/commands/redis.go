@@ -3,7 +3,6 @@ package commands
 import (
 	"fmt"
 
-	"github.com/deviantintegral/terminus-golang/pkg/api"
 	"github.com/spf13/cobra"
 )
 
@@ -31,7 +30,7 @@ func init() {
 
 func runRedisEnable(_ *cobra.Command, args []string) error {
 	siteID := args[0]
-	redisService := api.NewRedisService(cliContext.APIClient)
+	redisService := cliContext.API.Redis
 
 	printMessage("Enabling Redis for %s...", siteID)
 
@@ -45,7 +44,7 @@ func runRedisEnable(_ *cobra.Command, args []string) error {
 
 func runRedisDisable(_ *cobra.Command, args []string) error {
 	siteID := args[0]
-	redisService := api.NewRedisService(cliContext.APIClient)
+	redisService := cliContext.API.Redis
 
 	printMessage("Disabling Redis for %s...", siteID)
 
@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/deviantintegral/terminus-golang/pkg/api"
+	"github.com/deviantintegral/terminus-golang/pkg/remote"
+	"github.com/spf13/cobra"
+)
+
+var remoteTimeoutFlag time.Duration
+
+var remoteDrushCmd = &cobra.Command{
+	Use:     "remote:drush <site>.<env> -- <command>",
+	Aliases: []string{"drush"},
+	Short:   "Run a Drush command remotely",
+	Long:    "Run a Drush command on an environment over SSH, streaming its output and exiting with code 11 if it fails",
+	Args:    cobra.MinimumNArgs(1),
+	RunE:    runRemoteDrush,
+}
+
+var remoteWPCmd = &cobra.Command{
+	Use:     "remote:wp <site>.<env> -- <command>",
+	Aliases: []string{"wp"},
+	Short:   "Run a WP-CLI command remotely",
+	Long:    "Run a WP-CLI command on an environment over SSH, streaming its output and exiting with code 11 if it fails",
+	Args:    cobra.MinimumNArgs(1),
+	RunE:    runRemoteWP,
+}
+
+func init() {
+	// Add remote commands directly to rootCmd with colon-separated names
+	rootCmd.AddCommand(remoteDrushCmd)
+	rootCmd.AddCommand(remoteWPCmd)
+
+	for _, cmd := range []*cobra.Command{remoteDrushCmd, remoteWPCmd} {
+		cmd.Flags().DurationVar(&remoteTimeoutFlag, "command-timeout", 0, "Stop the remote command if it runs longer than this, e.g. 10m (default no limit)")
+	}
+}
+
+func runRemoteDrush(_ *cobra.Command, args []string) error {
+	return runRemoteCommand("drush", args)
+}
+
+func runRemoteWP(_ *cobra.Command, args []string) error {
+	return runRemoteCommand("wp", args)
+}
+
+// runRemoteCommand runs program with the arguments after <site>.<env> on the
+// environment's application server
+func runRemoteCommand(program string, args []string) error {
+	siteID, envID, err := parseSiteEnv(args[0])
+	if err != nil {
+		return err
+	}
+	command := remote.Command(program, args[1:]...)
+
+	info, err := cliContext.API.Environments.GetConnectionInfo(getContext(), siteID, envID)
+	if err != nil {
+		return fmt.Errorf("failed to get connection info: %w", err)
+	}
+	target := remote.TargetFromConnectionInfo(info)
+
+	// Remote commands can change anything, so they count as changes
+	if cliContext.ReadOnly {
+		return api.ErrReadOnly
+	}
+	if cliContext.DryRun {
+		return &api.DryRunError{Method: "SSH", Path: target.User + "@" + target.Addr(), Body: command}
+	}
+
+	ctx := getContext()
+	if remoteTimeoutFlag > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, remoteTimeoutFlag)
+		defer cancel()
+	}
+
+	client, err := cliContext.Remote.Dial(ctx, target)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = client.Close() }()
+
+	err = remote.Run(ctx, client, command, remoteStdin(), cliContext.Output.Writer, os.Stderr)
+	if errors.Is(err, context.DeadlineExceeded) && remoteTimeoutFlag > 0 {
+		return fmt.Errorf("%s did not finish within %s: %w", program, remoteTimeoutFlag, err)
+	}
+	return err
+}
+
+// remoteStdin returns stdin when it is redirected from a file or pipe, so
+// that input such as a SQL dump can be sent to the remote command. A
+// terminal isn't forwarded, since the remote command doesn't get one.
+func remoteStdin() io.Reader {
+	info, err := os.Stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice != 0 {
+		return nil
+	}
+	return os.Stdin
+}
@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/deviantintegral/terminus-golang/pkg/api"
+	"github.com/deviantintegral/terminus-golang/pkg/remote/remotetest"
+)
+
+// useRemote points the CLI context at a local SSH server standing in for
+// the environments of the fake API
+func useRemote(t *testing.T) (*remotetest.Server, string, *bytes.Buffer) {
+	t.Helper()
+
+	fake, buf := useFake(t)
+	site := fake.AddSite("my-site")
+	server := remotetest.NewServer(t)
+	fake.SFTPHost, fake.SFTPPort = server.Host(), server.Port()
+	cliContext.Remote = server.Dialer()
+
+	return server, site.ID, buf
+}
+
+func TestRunRemoteDrush(t *testing.T) {
+	server, siteID, buf := useRemote(t)
+	server.Handler = func(_ context.Context, s *remotetest.Session) int {
+		_, _ = fmt.Fprintf(s.Stdout, "%s as %s", s.Command, s.User)
+		return 0
+	}
+
+	if err := runRemoteCommand("drush", []string{"my-site.dev", "sql:query", "SELECT 1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "drush sql:query 'SELECT 1' as dev." + siteID; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestRunRemoteWP_ExitStatus(t *testing.T) {
+	server, _, _ := useRemote(t)
+	server.Handler = func(context.Context, *remotetest.Session) int { return 4 }
+
+	err := runRemoteCommand("wp", []string{"my-site.live", "plugin", "list"})
+	if err == nil || err.Error() != "wp exited with status 4" {
+		t.Fatalf("expected wp to fail, got %v", err)
+	}
+	if code := ExitCode(err); code != ExitCommandFailed {
+		t.Errorf("expected exit code %d, got %d", ExitCommandFailed, code)
+	}
+}
+
+func TestRunRemoteDrush_Timeout(t *testing.T) {
+	server, _, _ := useRemote(t)
+	server.Handler = func(ctx context.Context, _ *remotetest.Session) int {
+		<-ctx.Done()
+		return 1
+	}
+	remoteTimeoutFlag = 50 * time.Millisecond
+	t.Cleanup(func() { remoteTimeoutFlag = 0 })
+
+	err := runRemoteCommand("drush", []string{"my-site.dev", "cron"})
+	if !errors.Is(err, context.DeadlineExceeded) || ExitCode(err) != ExitTimeout {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+}
+
+func TestRunRemoteDrush_ReadOnlyAndDryRun(t *testing.T) {
+	server, _, _ := useRemote(t)
+
+	cliContext.ReadOnly = true
+	if err := runRemoteCommand("drush", []string{"my-site.dev", "cr"}); !errors.Is(err, api.ErrReadOnly) {
+		t.Errorf("expected read-only error, got %v", err)
+	}
+
+	cliContext.ReadOnly, cliContext.DryRun = false, true
+	err := runRemoteCommand("drush", []string{"my-site.dev", "cr"})
+	var dryRunErr *api.DryRunError
+	if !errors.As(err, &dryRunErr) || dryRunErr.Method != "SSH" || dryRunErr.Body != "drush cr" {
+		t.Errorf("expected dry-run error, got %v", err)
+	}
+
+	if sessions := server.Sessions(); len(sessions) != 0 {
+		t.Errorf("expected no commands to run, got %d", len(sessions))
+	}
+}
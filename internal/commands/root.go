@@ -2,23 +2,42 @@ package commands
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"iter"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/deviantintegral/terminus-golang/pkg/api"
+	"github.com/deviantintegral/terminus-golang/pkg/audit"
 	"github.com/deviantintegral/terminus-golang/pkg/config"
 	"github.com/deviantintegral/terminus-golang/pkg/output"
+	"github.com/deviantintegral/terminus-golang/pkg/remote"
 	"github.com/deviantintegral/terminus-golang/pkg/session"
 	"github.com/spf13/cobra"
 )
 
 var (
 	// Global flags
-	formatFlag   string
-	fieldsFlag   []string
-	yesFlag      bool
-	quietFlag    bool
-	verboseCount int
+	formatFlag    string
+	fieldsFlag    []string
+	filterFlag    []string
+	sortFlag      []string
+	queryFlag     string
+	yesFlag       bool
+	quietFlag     bool
+	verboseCount  int
+	cacheFlag     bool
+	noCacheFlag   bool
+	readOnlyFlag  bool
+	dryRunFlag    bool
+	traceFileFlag string
+
+	timeoutFlag     time.Duration
+	waitTimeoutFlag time.Duration
 )
 
 // CLIContext holds shared context for all commands
@@ -26,11 +45,28 @@ type CLIContext struct {
 	Config       *config.Config
 	SessionStore *session.Store
 	APIClient    *api.Client
-	Output       *output.Options
+	// API holds the services used by commands; tests may replace it with fakes
+	API    *api.Pantheon
+	Output *output.Options
+	// ReadOnly is set when the client refuses requests that change anything
+	ReadOnly bool
+	// DryRun is set when the client records changes instead of sending them
+	DryRun bool
+	// Audit records changes and the workflows they start
+	Audit *audit.Log
+	// Remote opens SSH connections to environments
+	Remote *remote.Dialer
 }
 
 var cliContext *CLIContext
 
+// rootContext is cancelled when the process receives an interrupt or
+// termination signal
+var rootContext = context.Background()
+
+// commandSpan covers the whole command when tracing is enabled
+var commandSpan *api.Span
+
 // rootCmd represents the base command
 var rootCmd = &cobra.Command{
 	Use:   "terminus",
@@ -39,8 +75,11 @@ var rootCmd = &cobra.Command{
 
 It provides tools to manage sites, environments, workflows, backups, and more
 on the Pantheon platform.`,
-	PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
-		return initCLIContext()
+	PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+		return initCLIContext(cmd)
+	},
+	PersistentPostRun: func(_ *cobra.Command, _ []string) {
+		logClientStats()
 	},
 	SilenceUsage:  true,
 	SilenceErrors: true,
@@ -48,16 +87,56 @@ on the Pantheon platform.`,
 
 // Execute executes the root command
 func Execute() error {
-	return rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	rootContext = ctx
+
+	// Restore the default handler after the first signal so that a second
+	// Ctrl-C terminates immediately
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	markValidationErrors(rootCmd)
+
+	cmd, err := rootCmd.ExecuteContextC(ctx)
+	commandSpan.Finish(err)
+	if err != nil && isCobraUsageError(err) {
+		return &validationError{err: err}
+	}
+	if cliContext != nil && cliContext.DryRun {
+		// The planned changes are the output of a dry run, even with --quiet
+		if printErr := printPlan(err); printErr != nil {
+			return printErr
+		}
+		if errors.Is(err, api.ErrDryRun) {
+			return nil
+		}
+	}
+	if errors.Is(err, api.ErrReadOnly) {
+		return fmt.Errorf("%s is not allowed in read-only mode: %w", cmd.Name(), err)
+	}
+	return err
 }
 
 func init() {
 	// Global flags
-	rootCmd.PersistentFlags().StringVar(&formatFlag, "format", "table", "Output format (table, json, yaml, csv, list)")
+	rootCmd.PersistentFlags().StringVar(&formatFlag, "format", "table", "Output format (table, json, ndjson, yaml, csv, tsv, list, markdown, html, template=<go template>, jsonpath=<jsonpath>)")
 	rootCmd.PersistentFlags().StringSliceVar(&fieldsFlag, "fields", nil, "Fields to display (comma-separated)")
+	rootCmd.PersistentFlags().StringArrayVar(&filterFlag, "filter", nil, "Only list items matching field=value; !=, ~= (regular expression), <, >, <= and >= (numbers and dates) also work. Repeat to combine")
+	rootCmd.PersistentFlags().StringSliceVar(&sortFlag, "sort", nil, "Sort lists by field, or field:desc (comma-separated for several fields)")
+	rootCmd.PersistentFlags().StringVar(&queryFlag, "query", "", "jq-like expression to run on the output before formatting, e.g. '.[] | select(.Plan == \"Basic\") | .Name'")
 	rootCmd.PersistentFlags().BoolVarP(&yesFlag, "yes", "y", false, "Answer yes to all prompts")
 	rootCmd.PersistentFlags().BoolVarP(&quietFlag, "quiet", "q", false, "Suppress output")
 	rootCmd.PersistentFlags().CountVarP(&verboseCount, "verbose", "v", "Verbose output (-v, -vv, or -vvv for increasing verbosity)")
+	rootCmd.PersistentFlags().BoolVar(&cacheFlag, "cache", false, "Cache responses from read-only API endpoints for a few minutes (default from the response_cache config key)")
+	rootCmd.PersistentFlags().BoolVar(&noCacheFlag, "no-cache", false, "Bypass the API response cache and the cache of resolved names")
+	rootCmd.PersistentFlags().DurationVar(&timeoutFlag, "timeout", 0, "Maximum time for each API request, e.g. 30s (default from the timeout config key)")
+	rootCmd.PersistentFlags().DurationVar(&waitTimeoutFlag, "wait-timeout", 0, "Maximum time to wait for workflows, e.g. 1h (default from the wait_timeout config key)")
+	rootCmd.PersistentFlags().BoolVar(&readOnlyFlag, "read-only", false, "Refuse any API request that would change something (default from the read_only config key)")
+	rootCmd.PersistentFlags().StringVar(&traceFileFlag, "trace-file", "", "Write tracing spans for API requests and workflow waits to this file as JSON lines, or - for stderr (default from the trace_file config key)")
+	rootCmd.PersistentFlags().BoolVar(&dryRunFlag, "dry-run", false, "Resolve and validate as usual, then print the changes a command would send instead of sending them")
 
 	// Note: All commands are now added directly to rootCmd in their respective files using colon-separated names:
 	// - auth commands (auth:login, auth:logout, auth:whoami) in auth.go
@@ -72,7 +151,7 @@ func init() {
 	// - lock commands (lock:info, lock:enable, lock:disable) in lock.go
 	// - plan commands (plan:info, plan:list) in plan.go
 	// - upstream commands (upstream:info, upstream:list, upstream:updates:list) in upstream.go
-	// - self commands (self:info) in self.go
+	// - self commands (self:info, self:clear-cache) in self.go
 	// - art commands (art, art:list) in art.go
 	// - redis commands (redis:enable, redis:disable) in redis.go
 	// - branch commands (branch:list) in branch.go
@@ -83,7 +162,20 @@ func init() {
 }
 
 // initCLIContext initializes the CLI context
-func initCLIContext() error {
+func initCLIContext(cmd *cobra.Command) error {
+	// Check the output options before doing any work
+	outputOpts := &output.Options{
+		Format:  output.Format(formatFlag),
+		Fields:  fieldsFlag,
+		Writer:  os.Stdout,
+		Filters: filterFlag,
+		Sort:    sortFlag,
+		Query:   queryFlag,
+	}
+	if err := outputOpts.Validate(); err != nil {
+		return newValidationError("%v", err)
+	}
+
 	// Load configuration
 	cfg, err := config.New()
 	if err != nil {
@@ -93,9 +185,76 @@ func initCLIContext() error {
 	// Create session store
 	sessionStore := session.NewStore(cfg.CacheDir)
 
+	// Command-line flags take precedence over configured timeouts
+	requestTimeout := cfg.RequestTimeout()
+	if timeoutFlag > 0 {
+		requestTimeout = timeoutFlag
+	}
+	waitTimeout := cfg.WorkflowWaitTimeout()
+	if waitTimeoutFlag > 0 {
+		waitTimeout = waitTimeoutFlag
+	}
+
+	// Load the existing session, if any, and find the email of its machine token
+	sess, err := sessionStore.LoadSession()
+	if err != nil {
+		sess = nil
+	}
+	var tokenEmail string
+	if sess != nil {
+		tokenEmail = sess.Email
+		if tokenEmail == "" {
+			// No email in session, try to find any available machine token
+			emails, listErr := sessionStore.ListTokens()
+			if listErr == nil && len(emails) == 1 {
+				tokenEmail = emails[0]
+			}
+		}
+	}
+
+	// Record every change in the audit log
+	hostname, _ := os.Hostname()
+	auditLog := audit.NewLog(cfg.AuditLogFile(), audit.Entry{
+		Command:     cmd.Name(),
+		CommandLine: audit.CommandLine(os.Args[1:]),
+		User:        tokenEmail,
+		Host:        hostname,
+	})
+
 	// Create API client with optional logger
 	clientOpts := []api.ClientOption{
 		api.WithBaseURL(cfg.GetBaseURL()),
+		api.WithRateLimit(cfg.RateLimit, cfg.RateLimitBurst),
+		api.WithMaxConcurrency(cfg.MaxConcurrency),
+		api.WithTimeout(requestTimeout),
+		api.WithWaitTimeout(waitTimeout),
+		api.WithReadOnly(readOnlyFlag || cfg.ReadOnly),
+		api.WithDryRun(dryRunFlag),
+		api.WithInterceptors(audit.Interceptor(auditLog)),
+		api.WithWorkflowObserver(audit.WorkflowObserver(auditLog)),
+	}
+
+	// Export tracing spans, grouping each command's requests into one trace
+	traceFile := traceFileFlag
+	if traceFile == "" {
+		traceFile = cfg.TraceFile
+	}
+	if traceFile != "" {
+		exporter, err := newSpanExporter(traceFile)
+		if err != nil {
+			return err
+		}
+		clientOpts = append(clientOpts, api.WithSpanExporter(exporter))
+		rootContext, commandSpan = api.StartSpan(rootContext, exporter, "command "+cmd.Name())
+	}
+
+	// Remember resolved names unless disabled. Cached responses can be
+	// minutes out of date, so they are only used when asked for.
+	if !noCacheFlag {
+		clientOpts = append(clientOpts, api.WithNameCache(cfg.NameCacheFile()))
+		if cacheFlag || cfg.ResponseCache {
+			clientOpts = append(clientOpts, api.WithCache(api.NewResponseCache(cfg.ResponseCacheDir())))
+		}
 	}
 
 	// Add logger if verbose mode is enabled
@@ -106,21 +265,10 @@ func initCLIContext() error {
 
 	apiClient := api.NewClient(clientOpts...)
 
-	// Try to load existing session and set up token refresher
-	sess, err := sessionStore.LoadSession()
-	if err == nil && sess != nil {
+	// Use the existing session and set up token refresher
+	if sess != nil {
 		apiClient.SetToken(sess.SessionToken)
 
-		// Determine which email to use for the token refresher
-		tokenEmail := sess.Email
-		if tokenEmail == "" {
-			// No email in session, try to find any available machine token
-			emails, listErr := sessionStore.ListTokens()
-			if listErr == nil && len(emails) == 1 {
-				tokenEmail = emails[0]
-			}
-		}
-
 		// Set up token refresher if we have an email to look up the machine token
 		if tokenEmail != "" {
 			// Create a closure that loads the machine token from the token file
@@ -146,8 +294,7 @@ func initCLIContext() error {
 
 			// Proactively refresh token if it will expire soon
 			if sess.NeedsRenewal() {
-				ctx := context.Background()
-				newToken, refreshErr := refresher.RefreshToken(ctx)
+				newToken, refreshErr := refresher.RefreshToken(getContext())
 				if refreshErr == nil {
 					apiClient.SetToken(newToken)
 				}
@@ -157,58 +304,173 @@ func initCLIContext() error {
 		}
 	}
 
-	// Create output options
-	outputOpts := &output.Options{
-		Format: output.Format(formatFlag),
-		Fields: fieldsFlag,
-		Writer: os.Stdout,
-	}
-
 	cliContext = &CLIContext{
 		Config:       cfg,
 		SessionStore: sessionStore,
 		APIClient:    apiClient,
+		API:          api.NewPantheon(apiClient),
 		Output:       outputOpts,
+		ReadOnly:     readOnlyFlag || cfg.ReadOnly,
+		DryRun:       dryRunFlag,
+		Audit:        auditLog,
+		Remote:       remote.NewDialer(),
 	}
 
 	return nil
 }
 
+// newSpanExporter creates an exporter that appends spans to file, or writes
+// them to stderr if file is "-". The file stays open until the process exits.
+func newSpanExporter(file string) (api.SpanExporter, error) {
+	if file == "-" {
+		return api.NewJSONSpanExporter(os.Stderr), nil
+	}
+
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open trace file: %w", err)
+	}
+	return api.NewJSONSpanExporter(f), nil
+}
+
+// logClientStats reports time spent throttling API requests when verbose output is enabled
+func logClientStats() {
+	if verboseCount == 0 || cliContext == nil || cliContext.APIClient == nil {
+		return
+	}
+
+	stats := cliContext.APIClient.Stats()
+	if stats.RateLimitWait == 0 && stats.ConcurrencyWait == 0 {
+		return
+	}
+
+	logger := api.NewLogger(api.VerbosityLevel(verboseCount))
+	logger.Info("Sent %d API requests; waited %v on the rate limit and %v on the concurrency limit",
+		stats.Requests, stats.RateLimitWait.Round(time.Millisecond), stats.ConcurrencyWait.Round(time.Millisecond))
+}
+
 // confirm prompts the user for confirmation
 func confirm(message string) bool {
-	if yesFlag {
+	// In read-only and dry-run modes the change being confirmed won't be sent
+	// anyway, so skip the prompt and go straight to refusing or recording it
+	if yesFlag || (cliContext != nil && (cliContext.ReadOnly || cliContext.DryRun)) {
 		return true
 	}
 
-	fmt.Printf("%s [y/N]: ", message)
+	// The prompt goes to stderr, with any preview, so that stdout only holds
+	// the command's output
+	fmt.Fprintf(os.Stderr, "%s [y/N]: ", message)
 	var response string
 	_, _ = fmt.Scanln(&response)
 
 	return response == "y" || response == "Y" || response == "yes"
 }
 
+// silent reports whether command output is suppressed, either by --quiet or
+// by dry-run mode, where the planned changes are printed instead
+func silent() bool {
+	return quietFlag || (cliContext != nil && cliContext.DryRun)
+}
+
+// printPlan prints the changes recorded in dry-run mode, followed by the one
+// that stopped the command if err is a *api.DryRunError
+func printPlan(err error) error {
+	planned := []api.PlannedRequest{}
+	if cliContext.APIClient != nil {
+		planned = append(planned, cliContext.APIClient.PlannedRequests()...)
+	}
+	var dryRunErr *api.DryRunError
+	if errors.As(err, &dryRunErr) {
+		planned = append(planned, api.PlannedRequest(*dryRunErr))
+	}
+	return output.Print(planned, cliContext.Output)
+}
+
 // printOutput prints data using the configured output format
 func printOutput(data interface{}) error {
-	if quietFlag {
+	if silent() {
 		return nil
 	}
 
 	return output.Print(data, cliContext.Output)
 }
 
+// printPreview prints data to stderr in the output format, so that it can be
+// checked before answering a prompt without ending up in the command's output
+func printPreview(data interface{}) error {
+	if silent() {
+		return nil
+	}
+
+	opts := output.DefaultOptions()
+	if cliContext.Output != nil {
+		copied := *cliContext.Output
+		opts = &copied
+	}
+	opts.Writer = os.Stderr
+	return output.Print(data, opts)
+}
+
+// printStream prints items from an iterator as they arrive, so long lists
+// start printing before the last page has been fetched
+func printStream[T any](items iter.Seq2[T, error]) (err error) {
+	if silent() {
+		for _, itemErr := range items {
+			if itemErr != nil {
+				return itemErr
+			}
+		}
+		return nil
+	}
+
+	// Close the stream even when a later page fails, so the items already
+	// printed still form a complete document
+	stream := output.NewStream(cliContext.Output)
+	defer func() {
+		if closeErr := stream.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	for item, itemErr := range items {
+		if itemErr != nil {
+			return itemErr
+		}
+		if err := stream.Write(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // printMessage prints a message to stdout
 func printMessage(format string, args ...interface{}) {
-	if !quietFlag {
+	if !silent() {
 		fmt.Printf(format+"\n", args...)
 	}
 }
 
-// printError prints an error message to stderr
+// printProgress prints a progress message or warning to stderr, so that it
+// stays out of the output of the command and of any client it starts
+func printProgress(format string, args ...interface{}) {
+	if !silent() {
+		fmt.Fprintf(os.Stderr, format+"\n", args...)
+	}
+}
+
+// printError prints an error message to stderr, as a JSON object when --format=json is set
 func printError(format string, args ...interface{}) {
+	if output.Format(formatFlag) == output.FormatJSON {
+		_ = json.NewEncoder(os.Stderr).Encode(errorReport{
+			Code:    "error",
+			Message: fmt.Sprintf(format, args...),
+		})
+		return
+	}
 	fmt.Fprintf(os.Stderr, "Error: "+format+"\n", args...)
 }
 
-// getContext returns a context for API calls
+// getContext returns a context for API calls that is cancelled on Ctrl-C
 func getContext() context.Context {
-	return context.Background()
+	return rootContext
 }
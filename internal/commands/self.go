@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"runtime"
 
+	"github.com/deviantintegral/terminus-golang/pkg/api"
 	"github.com/deviantintegral/terminus-golang/pkg/version"
 	"github.com/spf13/cobra"
 )
@@ -17,9 +18,18 @@ var selfInfoCmd = &cobra.Command{
 	RunE:  runSelfInfo,
 }
 
+var selfClearCacheCmd = &cobra.Command{
+	Use:   "self:clear-cache",
+	Short: "Clear cached API data",
+	Long:  "Remove all cached API responses and resolved names so the next commands fetch fresh data",
+	Args:  cobra.NoArgs,
+	RunE:  runSelfClearCache,
+}
+
 func init() {
 	// Add self commands directly to rootCmd with colon-separated names
 	rootCmd.AddCommand(selfInfoCmd)
+	rootCmd.AddCommand(selfClearCacheCmd)
 }
 
 func runSelfInfo(_ *cobra.Command, _ []string) error {
@@ -57,3 +67,18 @@ func runSelfInfo(_ *cobra.Command, _ []string) error {
 
 	return printOutput(info)
 }
+
+func runSelfClearCache(_ *cobra.Command, _ []string) error {
+	cache := api.NewResponseCache(cliContext.Config.ResponseCacheDir())
+	if err := cache.Clear(); err != nil {
+		return err
+	}
+
+	resolver := api.NewResolver(cliContext.APIClient, cliContext.Config.NameCacheFile())
+	if err := resolver.Clear(); err != nil {
+		return err
+	}
+
+	printMessage("Cleared cached API responses and names")
+	return nil
+}
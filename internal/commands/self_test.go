@@ -18,7 +18,7 @@ func TestSelfInfoCmdStructure(t *testing.T) {
 }
 
 func TestSelfCommands(t *testing.T) {
-	expectedCommands := []string{"self:info"}
+	expectedCommands := []string{"self:info", "self:clear-cache"}
 
 	for _, expected := range expectedCommands {
 		found := false
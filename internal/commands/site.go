@@ -1,12 +1,18 @@
 package commands
 
 import (
+	"context"
 	"fmt"
+	"iter"
+	"os"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/deviantintegral/terminus-golang/pkg/api"
 	"github.com/deviantintegral/terminus-golang/pkg/api/models"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
 var siteListCmd = &cobra.Command{
@@ -49,6 +55,38 @@ var siteTeamListCmd = &cobra.Command{
 	RunE:  runSiteTeamList,
 }
 
+var siteTeamAddCmd = &cobra.Command{
+	Use:   "site:team:add <site> <email>",
+	Short: "Add a site team member",
+	Long:  "Add a user to a site's team by email address",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runSiteTeamAdd,
+}
+
+var siteTeamRemoveCmd = &cobra.Command{
+	Use:   "site:team:remove <site> <member>",
+	Short: "Remove a site team member",
+	Long:  "Remove a user, given by email address or user ID, from a site's team",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runSiteTeamRemove,
+}
+
+var siteTeamRoleCmd = &cobra.Command{
+	Use:   "site:team:role <site> <member> <role>",
+	Short: "Change a site team member's role",
+	Long:  "Change the role (developer or team_member) of a site team member, given by email address or user ID",
+	Args:  cobra.ExactArgs(3),
+	RunE:  runSiteTeamRole,
+}
+
+var siteTeamSyncCmd = &cobra.Command{
+	Use:   "site:team:sync <site>",
+	Short: "Sync a site team from a file",
+	Long:  "Compare a site's team with the members listed in a YAML file, show the changes needed and apply them",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runSiteTeamSync,
+}
+
 var siteOrgListCmd = &cobra.Command{
 	Use:   "site:org:list <site>",
 	Short: "List organizations for a site",
@@ -63,6 +101,8 @@ var (
 	siteOwnerFlag    string
 	siteTeamFlag     bool
 	siteUpstreamFlag string
+	siteTeamRoleFlag string
+	siteTeamFileFlag string
 )
 
 func init() {
@@ -72,6 +112,10 @@ func init() {
 	rootCmd.AddCommand(siteCreateCmd)
 	rootCmd.AddCommand(siteDeleteCmd)
 	rootCmd.AddCommand(siteTeamListCmd)
+	rootCmd.AddCommand(siteTeamAddCmd)
+	rootCmd.AddCommand(siteTeamRemoveCmd)
+	rootCmd.AddCommand(siteTeamRoleCmd)
+	rootCmd.AddCommand(siteTeamSyncCmd)
 	rootCmd.AddCommand(siteOrgListCmd)
 
 	// Flags
@@ -82,11 +126,16 @@ func init() {
 
 	siteCreateCmd.Flags().StringVar(&siteOrgFlag, "org", "", "Organization ID")
 	siteCreateCmd.Flags().StringVar(&siteRegionFlag, "region", "", "Preferred region")
+
+	siteTeamAddCmd.Flags().StringVar(&siteTeamRoleFlag, "role", "team_member", "Role of the new member (developer or team_member)")
+
+	siteTeamSyncCmd.Flags().StringVar(&siteTeamFileFlag, "from-file", "", "YAML file listing the members the team should have")
+	_ = siteTeamSyncCmd.MarkFlagRequired("from-file")
 }
 
 func runSiteOrgList(_ *cobra.Command, args []string) error {
 	siteID := args[0]
-	sitesService := api.NewSitesService(cliContext.APIClient)
+	sitesService := cliContext.API.Sites
 
 	orgs, err := sitesService.ListOrganizations(getContext(), siteID)
 	if err != nil {
@@ -111,9 +160,9 @@ func runSiteList(_ *cobra.Command, _ []string) error {
 		return fmt.Errorf("no user ID in session")
 	}
 
-	sitesService := api.NewSitesService(cliContext.APIClient)
+	sitesService := cliContext.API.Sites
 
-	var sites []*models.Site
+	var sites iter.Seq2[*models.Site, error]
 
 	switch {
 	case siteOrgFlag != "":
@@ -124,47 +173,70 @@ func runSiteList(_ *cobra.Command, _ []string) error {
 		}
 
 		// If org flag is specified, only list sites for that specific organization
-		sites, err = sitesService.ListByOrganization(getContext(), orgID)
-		if err != nil {
-			return fmt.Errorf("failed to list organization sites: %w", err)
-		}
+		sites = sitesService.ListByOrganizationIter(getContext(), orgID)
 	case siteTeamFlag:
 		// If --team flag is specified, only fetch direct user memberships
 		// This matches PHP Terminus behavior: single API call to /users/{id}/memberships/sites
-		sites, err = sitesService.List(getContext(), sess.UserID)
-		if err != nil {
-			return fmt.Errorf("failed to list team sites: %w", err)
-		}
+		sites = sitesService.ListIter(getContext(), sess.UserID)
 	default:
 		// Otherwise, list all sites from user memberships and organization memberships
-		var getAllErr error
-		sites, getAllErr = getAllUserSites(sess.UserID)
+		allSites, getAllErr := getAllUserSites(sess.UserID)
 		if getAllErr != nil {
 			return fmt.Errorf("failed to list sites: %w", getAllErr)
 		}
+		sites = func(yield func(*models.Site, error) bool) {
+			for _, site := range allSites {
+				if !yield(site, nil) {
+					return
+				}
+			}
+		}
 	}
 
-	// Apply filters (skip team filter if --team was used, as sites are already filtered)
-	sites = filterSites(sites, sess.UserID)
-
-	// Convert to SiteListItem to exclude upstream field from output
-	listItems := make([]*models.SiteListItem, len(sites))
-	for i, site := range sites {
-		listItems[i] = site.ToListItem()
+	if err := printStream(siteListItems(sites, sess.UserID)); err != nil {
+		return fmt.Errorf("failed to list sites: %w", err)
 	}
 
-	return printOutput(listItems)
+	return nil
+}
+
+// siteListItems applies command-line filters to sites as they arrive and
+// converts them to SiteListItem to exclude the upstream field from output
+func siteListItems(sites iter.Seq2[*models.Site, error], currentUserID string) iter.Seq2[*models.SiteListItem, error] {
+	return func(yield func(*models.SiteListItem, error) bool) {
+		for site, err := range sites {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if len(filterSites([]*models.Site{site}, currentUserID)) == 0 {
+				continue
+			}
+			if !yield(site.ToListItem(), nil) {
+				return
+			}
+		}
+	}
 }
 
 // getAllUserSites fetches all sites accessible to the user, including:
 // 1. Sites from direct user memberships
 // 2. Sites from all organizations the user is a member of
 func getAllUserSites(userID string) ([]*models.Site, error) {
-	sitesService := api.NewSitesService(cliContext.APIClient)
-	orgsService := api.NewOrganizationsService(cliContext.APIClient)
+	sitesService := cliContext.API.Sites
+	orgsService := cliContext.API.Organizations
 
-	// Track unique sites by ID to avoid duplicates
+	// Track unique sites by ID to avoid duplicates, preserving the order
+	// in which they were found
 	siteMap := make(map[string]*models.Site)
+	var sites []*models.Site
+	addSite := func(site *models.Site) {
+		// Don't overwrite if site already exists (to preserve direct team membership info)
+		if _, exists := siteMap[site.ID]; !exists {
+			siteMap[site.ID] = site
+			sites = append(sites, site)
+		}
+	}
 
 	// 1. Get sites from direct user memberships
 	userSites, err := sitesService.List(getContext(), userID)
@@ -172,7 +244,7 @@ func getAllUserSites(userID string) ([]*models.Site, error) {
 		return nil, fmt.Errorf("failed to list user sites: %w", err)
 	}
 	for _, site := range userSites {
-		siteMap[site.ID] = site
+		addSite(site)
 	}
 
 	// 2. Get user's organization memberships
@@ -181,73 +253,39 @@ func getAllUserSites(userID string) ([]*models.Site, error) {
 		return nil, fmt.Errorf("failed to list user organizations: %w", err)
 	}
 
-	// 3. For each organization, get all sites
-	for _, org := range orgs {
-		orgSites, err := sitesService.ListByOrganization(getContext(), org.ID)
-		if err != nil {
+	// 3. Get all sites for each organization concurrently
+	results := api.ForEach(getContext(), orgs, api.DefaultConcurrency,
+		func(ctx context.Context, org *models.Organization) ([]*models.Site, error) {
+			return sitesService.ListByOrganization(ctx, org.ID)
+		})
+
+	for i, result := range results {
+		if result.Err != nil {
 			// Continue on error to get sites from other orgs
 			// Log the error but don't fail completely
-			orgName := org.ID
-			if org.Label != "" {
-				orgName = org.Label
+			orgName := orgs[i].ID
+			if orgs[i].Label != "" {
+				orgName = orgs[i].Label
 			}
-			printMessage("Warning: failed to list sites for organization %s: %v", orgName, err)
+			printMessage("Warning: failed to list sites for organization %s: %v", orgName, result.Err)
 			continue
 		}
 
-		// Add org sites to the map (deduplicating by ID)
-		// Don't overwrite if site already exists (to preserve direct team membership info)
-		for _, site := range orgSites {
-			if _, exists := siteMap[site.ID]; !exists {
-				siteMap[site.ID] = site
-			}
+		for _, site := range result.Value {
+			addSite(site)
 		}
 	}
 
-	// Convert map to slice
-	sites := make([]*models.Site, 0, len(siteMap))
-	for _, site := range siteMap {
-		sites = append(sites, site)
+	if sites == nil {
+		sites = []*models.Site{}
 	}
-
 	return sites, nil
 }
 
 // resolveOrgID resolves an organization name or label to its UUID
 // If the input is already a UUID, it returns it unchanged
 func resolveOrgID(orgIdentifier, userID string) (string, error) {
-	// If already a UUID, return as-is
-	if api.IsUUID(orgIdentifier) {
-		return orgIdentifier, nil
-	}
-
-	// Get user's organizations to resolve the name
-	orgsService := api.NewOrganizationsService(cliContext.APIClient)
-	orgs, err := orgsService.List(getContext(), userID)
-	if err != nil {
-		return "", fmt.Errorf("failed to list organizations: %w", err)
-	}
-
-	// Find organization by name or label (case-insensitive)
-	for _, org := range orgs {
-		if strings.EqualFold(org.Name, orgIdentifier) || strings.EqualFold(org.Label, orgIdentifier) {
-			return org.ID, nil
-		}
-	}
-
-	// Build helpful error message with available organizations
-	var availableOrgs []string
-	for _, org := range orgs {
-		if org.Name != "" {
-			availableOrgs = append(availableOrgs, org.Name)
-		} else if org.Label != "" {
-			availableOrgs = append(availableOrgs, org.Label)
-		}
-	}
-	if len(availableOrgs) > 0 {
-		return "", fmt.Errorf("organization not found: %s (available: %s)", orgIdentifier, strings.Join(availableOrgs, ", "))
-	}
-	return "", fmt.Errorf("organization not found: %s", orgIdentifier)
+	return cliContext.API.Organizations.Resolve(getContext(), userID, orgIdentifier)
 }
 
 // filterSites applies command-line filters to the sites list
@@ -304,7 +342,7 @@ func filterSites(sites []*models.Site, currentUserID string) []*models.Site {
 
 func runSiteInfo(_ *cobra.Command, args []string) error {
 	siteID := args[0]
-	sitesService := api.NewSitesService(cliContext.APIClient)
+	sitesService := cliContext.API.Sites
 
 	site, err := sitesService.Get(getContext(), siteID)
 	if err != nil {
@@ -327,7 +365,7 @@ func runSiteCreate(_ *cobra.Command, args []string) error {
 	siteName := args[0]
 	label := args[1]
 	upstreamID := args[2]
-	sitesService := api.NewSitesService(cliContext.APIClient)
+	sitesService := cliContext.API.Sites
 
 	req := &api.CreateSiteRequest{
 		SiteName:     siteName,
@@ -357,7 +395,7 @@ func runSiteDelete(_ *cobra.Command, args []string) error {
 		return nil
 	}
 
-	sitesService := api.NewSitesService(cliContext.APIClient)
+	sitesService := cliContext.API.Sites
 
 	printMessage("Deleting site %s...", siteID)
 
@@ -372,7 +410,7 @@ func runSiteDelete(_ *cobra.Command, args []string) error {
 
 func runSiteTeamList(_ *cobra.Command, args []string) error {
 	siteID := args[0]
-	sitesService := api.NewSitesService(cliContext.APIClient)
+	sitesService := cliContext.API.Sites
 
 	team, err := sitesService.GetTeam(getContext(), siteID)
 	if err != nil {
@@ -381,3 +419,244 @@ func runSiteTeamList(_ *cobra.Command, args []string) error {
 
 	return printOutput(team)
 }
+
+// siteTeamRoles are the roles a site team member can be given
+var siteTeamRoles = []string{"developer", "team_member"}
+
+func validateSiteTeamRole(role string) error {
+	if !slices.Contains(siteTeamRoles, role) {
+		return newValidationError("invalid role: %s (must be %s)", role, strings.Join(siteTeamRoles, " or "))
+	}
+	return nil
+}
+
+// findTeamMember returns the member of team with the given email address or
+// user ID
+func findTeamMember(team []*models.TeamMember, member string) *models.TeamMember {
+	for _, m := range team {
+		if m.ID == member || strings.EqualFold(m.Email, member) {
+			return m
+		}
+	}
+	return nil
+}
+
+// getTeamMember looks up a member of a site's team by email address or user ID
+func getTeamMember(siteID, member string) (*models.TeamMember, error) {
+	team, err := cliContext.API.Sites.GetTeam(getContext(), siteID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team members: %w", err)
+	}
+	found := findTeamMember(team, member)
+	if found == nil {
+		return nil, fmt.Errorf("%s is not a member of the team of %s: %w", member, siteID, api.ErrNotFound)
+	}
+	return found, nil
+}
+
+func runSiteTeamAdd(_ *cobra.Command, args []string) error {
+	siteID, email := args[0], args[1]
+	if err := validateSiteTeamRole(siteTeamRoleFlag); err != nil {
+		return err
+	}
+
+	printMessage("Adding %s to the team of %s as %s...", email, siteID, siteTeamRoleFlag)
+
+	member, err := cliContext.API.Sites.AddTeamMember(getContext(), siteID, &api.AddTeamMemberRequest{
+		Email: email,
+		Role:  siteTeamRoleFlag,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add team member: %w", err)
+	}
+
+	return printOutput(member)
+}
+
+func runSiteTeamRemove(_ *cobra.Command, args []string) error {
+	siteID := args[0]
+
+	member, err := getTeamMember(siteID, args[1])
+	if err != nil {
+		return err
+	}
+
+	if !confirm(fmt.Sprintf("Are you sure you want to remove %s from the team of %s?", member.Email, siteID)) {
+		printMessage("Canceled")
+		return nil
+	}
+
+	printMessage("Removing %s from the team of %s...", member.Email, siteID)
+
+	if err := cliContext.API.Sites.RemoveTeamMember(getContext(), siteID, member.ID); err != nil {
+		return fmt.Errorf("failed to remove team member: %w", err)
+	}
+
+	printMessage("Team member removed successfully")
+
+	return nil
+}
+
+func runSiteTeamRole(_ *cobra.Command, args []string) error {
+	siteID, role := args[0], args[2]
+	if err := validateSiteTeamRole(role); err != nil {
+		return err
+	}
+
+	member, err := getTeamMember(siteID, args[1])
+	if err != nil {
+		return err
+	}
+
+	printMessage("Changing the role of %s on %s to %s...", member.Email, siteID, role)
+
+	if err := cliContext.API.Sites.UpdateTeamMemberRole(getContext(), siteID, member.ID, role); err != nil {
+		return fmt.Errorf("failed to change team member role: %w", err)
+	}
+
+	printMessage("Team member role changed successfully")
+
+	return nil
+}
+
+// siteTeamFile is the membership read by site:team:sync, such as:
+//
+//	members:
+//	  - email: alice@example.com
+//	    role: developer
+//	  - email: bob@example.com
+type siteTeamFile struct {
+	Members []struct {
+		Email string `yaml:"email"`
+		Role  string `yaml:"role"`
+	} `yaml:"members"`
+}
+
+// teamChange is a change site:team:sync makes to a team
+type teamChange struct {
+	Action      string `json:"action" yaml:"action"`
+	Email       string `json:"email" yaml:"email"`
+	Role        string `json:"role" yaml:"role"`
+	CurrentRole string `json:"current_role,omitempty" yaml:"current_role,omitempty"`
+
+	userID string
+}
+
+// readSiteTeamFile reads the members listed in a site:team:sync file, keyed
+// by lowercase email address, with roles defaulting to team_member
+func readSiteTeamFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // User-specified team file
+	if err != nil {
+		return nil, fmt.Errorf("failed to read team file: %w", err)
+	}
+
+	var file siteTeamFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, newValidationError("invalid team file %s: %v", path, err)
+	}
+
+	members := make(map[string]string, len(file.Members))
+	for _, m := range file.Members {
+		email := strings.ToLower(strings.TrimSpace(m.Email))
+		if email == "" {
+			return nil, newValidationError("invalid team file %s: every member needs an email", path)
+		}
+		if _, ok := members[email]; ok {
+			return nil, newValidationError("invalid team file %s: %s is listed more than once", path, email)
+		}
+		role := m.Role
+		if role == "" {
+			role = "team_member"
+		}
+		if err := validateSiteTeamRole(role); err != nil {
+			return nil, err
+		}
+		members[email] = role
+	}
+	return members, nil
+}
+
+// planTeamChanges returns the changes that make team match the desired
+// members: additions, then role changes, then removals, so that the team is
+// never left without someone who was meant to stay. The site owner is left
+// alone, since ownership is changed separately.
+func planTeamChanges(team []*models.TeamMember, desired map[string]string) []*teamChange {
+	var adds, updates, removals []*teamChange
+
+	current := make(map[string]bool, len(team))
+	for _, member := range team {
+		email := strings.ToLower(member.Email)
+		current[email] = true
+		if member.Role == "owner" {
+			continue
+		}
+
+		role, ok := desired[email]
+		switch {
+		case !ok:
+			removals = append(removals, &teamChange{Action: "remove", Email: member.Email, CurrentRole: member.Role, userID: member.ID})
+		case role != member.Role:
+			updates = append(updates, &teamChange{Action: "change role", Email: member.Email, Role: role, CurrentRole: member.Role, userID: member.ID})
+		}
+	}
+
+	for email, role := range desired {
+		if !current[email] {
+			adds = append(adds, &teamChange{Action: "add", Email: email, Role: role})
+		}
+	}
+	sort.Slice(adds, func(i, j int) bool { return adds[i].Email < adds[j].Email })
+
+	return slices.Concat(adds, updates, removals)
+}
+
+func runSiteTeamSync(_ *cobra.Command, args []string) error {
+	siteID := args[0]
+	sitesService := cliContext.API.Sites
+
+	desired, err := readSiteTeamFile(siteTeamFileFlag)
+	if err != nil {
+		return err
+	}
+
+	team, err := sitesService.GetTeam(getContext(), siteID)
+	if err != nil {
+		return fmt.Errorf("failed to get team members: %w", err)
+	}
+
+	changes := planTeamChanges(team, desired)
+	if len(changes) == 0 {
+		printMessage("The team of %s is already in sync", siteID)
+		return nil
+	}
+
+	if err := printOutput(changes); err != nil {
+		return err
+	}
+
+	if !confirm(fmt.Sprintf("Apply %d changes to the team of %s?", len(changes), siteID)) {
+		printMessage("Canceled")
+		return nil
+	}
+
+	for _, change := range changes {
+		switch change.Action {
+		case "add":
+			printMessage("Adding %s as %s...", change.Email, change.Role)
+			_, err = sitesService.AddTeamMember(getContext(), siteID, &api.AddTeamMemberRequest{Email: change.Email, Role: change.Role})
+		case "change role":
+			printMessage("Changing the role of %s to %s...", change.Email, change.Role)
+			err = sitesService.UpdateTeamMemberRole(getContext(), siteID, change.userID, change.Role)
+		case "remove":
+			printMessage("Removing %s...", change.Email)
+			err = sitesService.RemoveTeamMember(getContext(), siteID, change.userID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to %s %s: %w", change.Action, change.Email, err)
+		}
+	}
+
+	printMessage("Team synced successfully")
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deviantintegral/terminus-golang/pkg/api"
+	"github.com/deviantintegral/terminus-golang/pkg/api/models"
+)
+
+// useYes answers yes to confirmation prompts for the rest of the test
+func useYes(t *testing.T) {
+	t.Helper()
+
+	oldYes := yesFlag
+	yesFlag = true
+	t.Cleanup(func() { yesFlag = oldYes })
+}
+
+// teamRoles returns the roles of a site's team members by email address
+func teamRoles(t *testing.T, siteID string) map[string]string {
+	t.Helper()
+
+	team, err := cliContext.API.Sites.GetTeam(getContext(), siteID)
+	if err != nil {
+		t.Fatalf("failed to get team: %v", err)
+	}
+	roles := make(map[string]string, len(team))
+	for _, member := range team {
+		roles[member.Email] = member.Role
+	}
+	return roles
+}
+
+func TestRunSiteTeamAddRoleRemove(t *testing.T) {
+	fake, _ := useFake(t)
+	fake.AddSite("my-site")
+	useYes(t)
+
+	siteTeamRoleFlag = "developer"
+	t.Cleanup(func() { siteTeamRoleFlag = "team_member" })
+	if err := runSiteTeamAdd(nil, []string{"my-site", "dev@example.com"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if role := teamRoles(t, "my-site")["dev@example.com"]; role != "developer" {
+		t.Errorf("expected developer role, got %q", role)
+	}
+
+	if err := runSiteTeamRole(nil, []string{"my-site", "DEV@example.com", "team_member"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if role := teamRoles(t, "my-site")["dev@example.com"]; role != "team_member" {
+		t.Errorf("expected team_member role, got %q", role)
+	}
+
+	if err := runSiteTeamRemove(nil, []string{"my-site", "dev@example.com"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := teamRoles(t, "my-site")["dev@example.com"]; ok {
+		t.Error("expected member to be removed")
+	}
+
+	err := runSiteTeamRemove(nil, []string{"my-site", "dev@example.com"})
+	if !errors.Is(err, api.ErrNotFound) {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+func TestRunSiteTeamRole_InvalidRole(t *testing.T) {
+	fake, _ := useFake(t)
+	fake.AddSite("my-site")
+
+	err := runSiteTeamRole(nil, []string{"my-site", "dev@example.com", "admin"})
+	if !errors.Is(err, errValidation) {
+		t.Errorf("expected a validation error, got %v", err)
+	}
+}
+
+func TestRunSiteTeamSync(t *testing.T) {
+	fake, buf := useFake(t)
+	site := fake.AddSite("my-site")
+	fake.AddTeamMember("my-site", "keep@example.com", "developer")
+	fake.AddTeamMember("my-site", "promote@example.com", "team_member")
+	fake.AddTeamMember("my-site", "leaver@example.com", "team_member")
+	useYes(t)
+
+	path := filepath.Join(t.TempDir(), "team.yml")
+	data := `members:
+  - email: keep@example.com
+    role: developer
+  - email: Promote@example.com
+    role: developer
+  - email: new@example.com
+`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("failed to write team file: %v", err)
+	}
+	siteTeamFileFlag = path
+	t.Cleanup(func() { siteTeamFileFlag = "" })
+
+	if err := runSiteTeamSync(nil, []string{"my-site"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var plan []map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &plan); err != nil {
+		t.Fatalf("failed to parse plan: %v\n%s", err, buf.String())
+	}
+	wantPlan := []string{"add new@example.com", "change role promote@example.com", "remove leaver@example.com"}
+	if len(plan) != len(wantPlan) {
+		t.Fatalf("expected %d changes, got %v", len(wantPlan), plan)
+	}
+	for i, want := range wantPlan {
+		if got := plan[i]["action"] + " " + plan[i]["email"]; got != want {
+			t.Errorf("change %d = %q, want %q", i, got, want)
+		}
+	}
+
+	// The owner isn't in the file but stays on the team
+	want := map[string]string{
+		fake.Email:            "owner",
+		"keep@example.com":    "developer",
+		"promote@example.com": "developer",
+		"new@example.com":     "team_member",
+	}
+	got := teamRoles(t, site.ID)
+	if len(got) != len(want) {
+		t.Errorf("got team %v, want %v", got, want)
+	}
+	for email, role := range want {
+		if got[email] != role {
+			t.Errorf("%s has role %q, want %q", email, got[email], role)
+		}
+	}
+
+	// Nothing changes the second time
+	buf.Reset()
+	if err := runSiteTeamSync(nil, []string{"my-site"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected no plan, got %s", buf.String())
+	}
+}
+
+func TestPlanTeamChanges_LeavesOwner(t *testing.T) {
+	team := []*models.TeamMember{{ID: "1", Email: "owner@example.com", Role: "owner"}}
+	if changes := planTeamChanges(team, map[string]string{"owner@example.com": "developer"}); len(changes) != 0 {
+		t.Errorf("expected no changes, got %+v", changes)
+	}
+}
+
+func TestReadSiteTeamFile_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"missing email": "members:\n  - role: developer\n",
+		"invalid role":  "members:\n  - email: a@example.com\n    role: admin\n",
+		"duplicate":     "members:\n  - email: a@example.com\n  - email: A@example.com\n",
+		"not yaml":      "members: [",
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "team.yml")
+			if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+				t.Fatalf("failed to write team file: %v", err)
+			}
+			if _, err := readSiteTeamFile(path); !errors.Is(err, errValidation) {
+				t.Errorf("expected a validation error, got %v", err)
+			}
+		})
+	}
+}
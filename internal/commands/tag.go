@@ -3,7 +3,6 @@ package commands
 import (
 	"fmt"
 
-	"github.com/deviantintegral/terminus-golang/pkg/api"
 	"github.com/spf13/cobra"
 )
 
@@ -23,7 +22,7 @@ func runTagList(_ *cobra.Command, args []string) error {
 	siteID := args[0]
 	orgID := args[1]
 
-	sitesService := api.NewSitesService(cliContext.APIClient)
+	sitesService := cliContext.API.Sites
 
 	tags, err := sitesService.GetTags(getContext(), siteID, orgID)
 	if err != nil {
@@ -3,7 +3,6 @@ package commands
 import (
 	"fmt"
 
-	"github.com/deviantintegral/terminus-golang/pkg/api"
 	"github.com/deviantintegral/terminus-golang/pkg/api/models"
 	"github.com/spf13/cobra"
 )
@@ -52,7 +51,7 @@ func init() {
 func runUpstreamInfo(_ *cobra.Command, args []string) error {
 	upstreamID := args[0]
 
-	upstreamsService := api.NewUpstreamsService(cliContext.APIClient)
+	upstreamsService := cliContext.API.Upstreams
 
 	upstream, err := upstreamsService.Get(getContext(), upstreamID)
 	if err != nil {
@@ -72,7 +71,7 @@ func runUpstreamList(_ *cobra.Command, _ []string) error {
 		return fmt.Errorf("no user ID in session")
 	}
 
-	upstreamsService := api.NewUpstreamsService(cliContext.APIClient)
+	upstreamsService := cliContext.API.Upstreams
 
 	upstreams, err := upstreamsService.List(getContext(), sess.UserID)
 	if err != nil {
@@ -148,7 +147,7 @@ func runUpstreamUpdatesList(_ *cobra.Command, args []string) error {
 		return err
 	}
 
-	upstreamsService := api.NewUpstreamsService(cliContext.APIClient)
+	upstreamsService := cliContext.API.Upstreams
 
 	updates, err := upstreamsService.ListUpdates(getContext(), siteID, envID)
 	if err != nil {
@@ -1,13 +1,15 @@
 package commands
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
-	"time"
 
 	"github.com/deviantintegral/terminus-golang/pkg/api"
 	"github.com/deviantintegral/terminus-golang/pkg/api/models"
+	"github.com/deviantintegral/terminus-golang/pkg/output"
 	"github.com/schollz/progressbar/v3"
 	"github.com/spf13/cobra"
 )
@@ -55,7 +57,7 @@ func init() {
 
 func runWorkflowList(_ *cobra.Command, args []string) error {
 	siteID := args[0]
-	workflowsService := api.NewWorkflowsService(cliContext.APIClient)
+	workflowsService := cliContext.API.Workflows
 
 	workflows, err := workflowsService.List(getContext(), siteID)
 	if err != nil {
@@ -68,7 +70,7 @@ func runWorkflowList(_ *cobra.Command, args []string) error {
 func runWorkflowInfo(_ *cobra.Command, args []string) error {
 	siteID := args[0]
 	workflowID := args[1]
-	workflowsService := api.NewWorkflowsService(cliContext.APIClient)
+	workflowsService := cliContext.API.Workflows
 
 	workflow, err := workflowsService.Get(getContext(), siteID, workflowID)
 	if err != nil {
@@ -88,7 +90,7 @@ func runWorkflowWait(_ *cobra.Command, args []string) error {
 func runWorkflowWatch(_ *cobra.Command, args []string) error {
 	siteID := args[0]
 	workflowID := args[1]
-	workflowsService := api.NewWorkflowsService(cliContext.APIClient)
+	workflowsService := cliContext.API.Workflows
 
 	printMessage("Watching workflow %s...", workflowID)
 
@@ -107,6 +109,7 @@ func runWorkflowWatch(_ *cobra.Command, args []string) error {
 	}
 
 	if err := workflowsService.Watch(getContext(), siteID, workflowID, opts); err != nil {
+		printResumeHint(siteID, err)
 		return fmt.Errorf("failed to watch workflow: %w", err)
 	}
 
@@ -121,16 +124,16 @@ func runWorkflowWatch(_ *cobra.Command, args []string) error {
 		return nil
 	}
 
-	return fmt.Errorf("workflow failed: %s", workflow.GetMessage())
+	return fmt.Errorf("workflow failed: %w", api.NewWorkflowError(workflow))
 }
 
 // waitForWorkflow waits for a workflow to complete and displays progress
 func waitForWorkflow(siteID, workflowID, description string) error {
-	workflowsService := api.NewWorkflowsService(cliContext.APIClient)
+	workflowsService := cliContext.API.Workflows
 
 	// Create progress bar
 	var bar *progressbar.ProgressBar
-	if !quietFlag {
+	if !silent() {
 		bar = progressbar.NewOptions(-1,
 			progressbar.OptionSetDescription(description),
 			progressbar.OptionSetWriter(os.Stderr),
@@ -139,9 +142,9 @@ func waitForWorkflow(siteID, workflowID, description string) error {
 		)
 	}
 
+	// The poll interval and timeout come from the client's defaults, which
+	// honour --wait-timeout
 	opts := &api.WaitOptions{
-		PollInterval: 3 * time.Second,
-		Timeout:      30 * time.Minute,
 		OnProgress: func(_ *models.Workflow) {
 			if bar != nil {
 				_ = bar.Add(1)
@@ -152,8 +155,11 @@ func waitForWorkflow(siteID, workflowID, description string) error {
 	workflow, err := workflowsService.Wait(getContext(), siteID, workflowID, opts)
 	if err != nil {
 		if bar != nil {
-			_ = bar.Finish()
+			// Stop the spinner and clear its line so the error starts on a fresh line
+			_ = bar.Exit()
+			_ = bar.Clear()
 		}
+		printResumeHint(siteID, err)
 		return fmt.Errorf("workflow wait failed: %w", err)
 	}
 
@@ -166,14 +172,24 @@ func waitForWorkflow(siteID, workflowID, description string) error {
 		return nil
 	}
 
-	return fmt.Errorf("%s failed: %s", description, workflow.GetMessage())
+	return fmt.Errorf("%s failed: %w", description, api.NewWorkflowError(workflow))
+}
+
+// printResumeHint tells the user how to resume waiting for a workflow whose
+// wait was interrupted, since the workflow keeps running on the platform
+func printResumeHint(siteID string, err error) {
+	workflowID := api.WorkflowID(err)
+	if workflowID == "" || !errors.Is(err, context.Canceled) || output.Format(formatFlag) == output.FormatJSON {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Workflow %s is still running. Resume with: terminus workflow:wait %s %s\n", workflowID, siteID, workflowID)
 }
 
 // parseSiteEnv parses a site.env string
 func parseSiteEnv(input string) (site, env string, err error) {
 	parts := strings.SplitN(input, ".", 2)
 	if len(parts) != 2 {
-		return "", "", fmt.Errorf("invalid format: expected 'site.env', got '%s'", input)
+		return "", "", newValidationError("invalid format: expected 'site.env', got '%s'", input)
 	}
 	return parts[0], parts[1], nil
 }
@@ -0,0 +1,520 @@
+package apitest
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deviantintegral/terminus-golang/pkg/api"
+	"github.com/deviantintegral/terminus-golang/pkg/api/models"
+	"github.com/google/uuid"
+)
+
+// Fake is an in-memory implementation of every api service interface, for
+// testing code that takes an *api.Pantheon without going through HTTP.
+// Workflows finish as soon as they are started and apply the same side
+// effects as the Server: creating and deleting sites and multidevs, and
+// creating backups. Sites may be referred to by name or ID. A Fake is safe
+// for concurrent use.
+type Fake struct {
+	// UserID is the ID of the authenticated user
+	UserID string
+	// Email is the email address of the authenticated user
+	Email string
+	// SFTPHost and SFTPPort, when set, replace the SFTP endpoint returned by
+	// GetConnectionInfo, such as with the address of a remotetest.Server
+	SFTPHost string
+	SFTPPort int
+	// MySQLHost and MySQLPort, when set, replace the database endpoint
+	// returned by GetConnectionInfo
+	MySQLHost string
+	MySQLPort int
+
+	mu        sync.Mutex
+	sites     []*models.Site
+	envs      map[string][]*models.Environment
+	workflows []*models.Workflow
+	backups   map[string][]*models.Backup
+	schedules map[string]map[string]interface{}
+	domains   map[string][]*models.Domain
+	locks     map[string]*models.Lock
+	team      map[string][]*models.TeamMember
+	tags      map[string][]*models.Tag
+	orgs      []*models.Organization
+	members   map[string][]*models.OrganizationMember
+	orgSites  map[string][]string
+	upstreams []*models.Upstream
+	tokens    []*models.MachineToken
+	keys      []*models.SSHKey
+	failures  map[string]string
+	errs      map[string]error
+	now       func() time.Time
+}
+
+// NewFake creates an empty fake authenticated as a new user
+func NewFake() *Fake {
+	return &Fake{
+		UserID:    uuid.New().String(),
+		Email:     "user@example.com",
+		envs:      make(map[string][]*models.Environment),
+		backups:   make(map[string][]*models.Backup),
+		schedules: make(map[string]map[string]interface{}),
+		domains:   make(map[string][]*models.Domain),
+		locks:     make(map[string]*models.Lock),
+		team:      make(map[string][]*models.TeamMember),
+		tags:      make(map[string][]*models.Tag),
+		members:   make(map[string][]*models.OrganizationMember),
+		orgSites:  make(map[string][]string),
+		failures:  make(map[string]string),
+		errs:      make(map[string]error),
+		now:       time.Now,
+	}
+}
+
+// Pantheon returns a Pantheon whose services are all backed by the fake
+func (f *Fake) Pantheon() *api.Pantheon {
+	return &api.Pantheon{
+		Auth:          fakeAuth{f},
+		Backups:       fakeBackups{f},
+		Domains:       fakeDomains{f},
+		Environments:  fakeEnvironments{f},
+		Multidev:      fakeMultidev{f},
+		Organizations: fakeOrganizations{f},
+		Redis:         fakeRedis{f},
+		Sites:         fakeSites{f},
+		Upstreams:     fakeUpstreams{f},
+		Users:         fakeUsers{f},
+		Workflows:     fakeWorkflows{f},
+	}
+}
+
+// AddSite adds a site with the default environments and returns it
+func (f *Fake) AddSite(name string) *models.Site {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.addSite(name, "")
+}
+
+// AddEnvironment adds an environment, such as a multidev, to a site
+func (f *Fake) AddEnvironment(siteID, envID string) *models.Environment {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.addEnvironment(f.siteID(siteID), envID)
+}
+
+// AddBackup adds a finished backup of an environment element (code,
+// database or files) and returns it
+func (f *Fake) AddBackup(siteID, envID, element string) *models.Backup {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.addBackup(f.siteID(siteID), envID, element)
+}
+
+// AddDomain attaches a custom domain to an environment
+func (f *Fake) AddDomain(siteID, envID, domain string) *models.Domain {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.addDomain(f.siteID(siteID), envID, domain)
+}
+
+// AddTeamMember adds a user with the given role to a site's team
+func (f *Fake) AddTeamMember(siteID, email, role string) *models.TeamMember {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.addTeamMember(f.siteID(siteID), email, role)
+}
+
+// AddOrganization adds an organization the user belongs to
+func (f *Fake) AddOrganization(name, label string) *models.Organization {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	org := &models.Organization{ID: uuid.New().String(), Name: name, Label: label}
+	f.orgs = append(f.orgs, org)
+	return org
+}
+
+// AddOrganizationMember adds a user with the given role to an organization.
+// Adding the authenticated user's email address adds the authenticated user.
+func (f *Fake) AddOrganizationMember(orgID, email, role string) *models.OrganizationMember {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.addOrganizationMember(orgID, email, role)
+}
+
+// AddSiteToOrganization makes an organization a supporting member of a site
+func (f *Fake) AddSiteToOrganization(orgID, siteID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	siteID = f.siteID(siteID)
+	f.orgSites[orgID] = append(f.orgSites[orgID], siteID)
+	if site := f.findSite(siteID); site != nil && site.Organization == "" {
+		site.Organization = orgID
+	}
+}
+
+// AddUpstream adds an upstream available to the user
+func (f *Fake) AddUpstream(machineName, framework string) *models.Upstream {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	upstream := &models.Upstream{
+		ID:          uuid.New().String(),
+		Label:       machineName,
+		MachineName: machineName,
+		Type:        "core",
+		Framework:   framework,
+	}
+	f.upstreams = append(f.upstreams, upstream)
+	return upstream
+}
+
+// AddMachineToken adds a machine token to the user's account
+func (f *Fake) AddMachineToken(deviceName string) *models.MachineToken {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	token := &models.MachineToken{ID: uuid.New().String(), DeviceName: deviceName, Email: f.Email}
+	f.tokens = append(f.tokens, token)
+	return token
+}
+
+// AddSSHKey adds an SSH public key to the user's account
+func (f *Fake) AddSSHKey(key string) *models.SSHKey {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	sshKey := &models.SSHKey{ID: fmt.Sprintf("%032x", len(f.keys)+1), Key: key}
+	f.keys = append(f.keys, sshKey)
+	return sshKey
+}
+
+// FailWorkflows makes workflows of the given type finish with a failure
+// reporting message
+func (f *Fake) FailWorkflows(workflowType, message string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.failures[workflowType] = message
+}
+
+// FailMethod makes every call to a service method return err. Methods are
+// named by interface and method, such as "Sites.Get"; the Iter variants of a
+// List method share its name. A nil err clears the failure.
+func (f *Fake) FailMethod(method string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err == nil {
+		delete(f.errs, method)
+		return
+	}
+	f.errs[method] = err
+}
+
+// Site returns a copy of the site with the given name or ID, if it exists
+func (f *Fake) Site(siteIdentifier string) (*models.Site, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	site := f.findSite(siteIdentifier)
+	if site == nil {
+		return nil, false
+	}
+	siteCopy := *site
+	return &siteCopy, true
+}
+
+// Workflows returns copies of every workflow started, oldest first
+func (f *Fake) Workflows() []*models.Workflow {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return clone(f.workflows)
+}
+
+// call checks for cancellation and injected failures before a service method
+// runs, and locks the fake. The returned function unlocks it.
+func (f *Fake) call(ctx context.Context, method string) (func(), error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	if err, ok := f.errs[method]; ok {
+		f.mu.Unlock()
+		return nil, err
+	}
+	return f.mu.Unlock, nil
+}
+
+// startWorkflow records a workflow that has already finished, applying its
+// side effects if it succeeded
+func (f *Fake) startWorkflow(siteID, envID, workflowType string, params map[string]interface{}) *models.Workflow {
+	now := float64(f.now().Unix())
+	workflow := &models.Workflow{
+		ID:            uuid.New().String(),
+		Type:          workflowType,
+		Description:   workflowDescription(workflowType),
+		SiteID:        siteID,
+		EnvironmentID: envID,
+		UserID:        f.UserID,
+		CreatedAt:     now,
+		StartedAt:     now,
+		FinishedAt:    now,
+		Step:          1,
+		Params:        params,
+		FinalTask:     &models.Task{ID: uuid.New().String(), Type: workflowType, SiteID: siteID},
+	}
+	f.workflows = append(f.workflows, workflow)
+
+	if message, failed := f.failures[workflowType]; failed {
+		workflow.Result = "failed"
+		workflow.FinalTask.Result = "failed"
+		workflow.FinalTask.Messages = []interface{}{map[string]interface{}{"level": "ERROR", "message": message}}
+	} else {
+		workflow.Result = "succeeded"
+		workflow.FinalTask.Result = "succeeded"
+		f.apply(workflow)
+	}
+
+	workflowCopy := *workflow
+	return &workflowCopy
+}
+
+// apply performs the side effects of a workflow that succeeded
+func (f *Fake) apply(workflow *models.Workflow) {
+	switch workflow.Type {
+	case "create_site":
+		name, _ := workflow.Params["site_name"].(string)
+		label, _ := workflow.Params["label"].(string)
+		site := f.addSite(name, label)
+		if orgID, ok := workflow.Params["organization_id"].(string); ok {
+			site.Organization = orgID
+			f.orgSites[orgID] = append(f.orgSites[orgID], site.ID)
+		}
+		workflow.FinalTask.SiteID = site.ID
+	case "delete_site":
+		for i, site := range f.sites {
+			if site.ID == workflow.SiteID {
+				f.sites = append(f.sites[:i], f.sites[i+1:]...)
+				break
+			}
+		}
+		delete(f.envs, workflow.SiteID)
+	case "do_export":
+		for _, element := range []string{"code", "database", "files"} {
+			if include, _ := workflow.Params[element].(bool); include {
+				f.addBackup(workflow.SiteID, workflow.EnvironmentID, element)
+			}
+		}
+	case "create_cloud_development_environment":
+		if envID, ok := workflow.Params["environment_id"].(string); ok {
+			f.addEnvironment(workflow.SiteID, envID)
+		}
+	case "delete_cloud_development_environment":
+		if envID, ok := workflow.Params["environment_id"].(string); ok {
+			envs := f.envs[workflow.SiteID]
+			for i, env := range envs {
+				if env.ID == envID {
+					f.envs[workflow.SiteID] = append(envs[:i], envs[i+1:]...)
+					break
+				}
+			}
+		}
+	}
+}
+
+func (f *Fake) addSite(name, label string) *models.Site {
+	if label == "" {
+		label = name
+	}
+
+	site := &models.Site{
+		ID:            uuid.New().String(),
+		Name:          name,
+		Label:         label,
+		Created:       f.now().Unix(),
+		Framework:     "drupal8",
+		Service:       "free",
+		PlanName:      "Sandbox",
+		PHP:           "83",
+		Holder:        "user",
+		HolderID:      f.UserID,
+		Owner:         f.UserID,
+		PreferredZone: "us-central1",
+	}
+	f.sites = append(f.sites, site)
+	for _, envID := range DefaultEnvironments {
+		f.addEnvironment(site.ID, envID)
+	}
+	f.addTeamMember(site.ID, f.Email, "owner").ID = f.UserID
+
+	return site
+}
+
+func (f *Fake) addEnvironment(siteID, envID string) *models.Environment {
+	name := siteID
+	if site := f.findSite(siteID); site != nil {
+		name = site.Name
+	}
+
+	env := &models.Environment{
+		ID:             envID,
+		SiteID:         siteID,
+		Domain:         fmt.Sprintf("%s-%s.pantheonsite.io", envID, name),
+		Initialized:    true,
+		ConnectionMode: "git",
+		PHP:            "83",
+		TargetRef:      "refs/heads/master",
+	}
+	f.envs[siteID] = append(f.envs[siteID], env)
+
+	return env
+}
+
+func (f *Fake) addBackup(siteID, envID, element string) *models.Backup {
+	now := f.now().Unix()
+	folder := fmt.Sprintf("%d_backup", now)
+	backup := &models.Backup{
+		ID:            folder + "_" + element,
+		SiteID:        siteID,
+		EnvironmentID: envID,
+		ArchiveType:   element,
+		Timestamp:     now,
+		FinishTime:    now,
+		Size:          1024,
+		Folder:        folder,
+		TTL:           365 * 86400,
+		ExpiryTime:    now + 365*86400,
+	}
+
+	key := backupKey(siteID, envID)
+	f.backups[key] = append(f.backups[key], backup)
+
+	return backup
+}
+
+func (f *Fake) addDomain(siteID, envID, domain string) *models.Domain {
+	record := &models.Domain{
+		ID:            domain,
+		Domain:        domain,
+		SiteID:        siteID,
+		EnvironmentID: envID,
+		Type:          "custom",
+		Status:        "ok",
+		Deletable:     true,
+	}
+	key := backupKey(siteID, envID)
+	f.domains[key] = append(f.domains[key], record)
+
+	return record
+}
+
+func (f *Fake) addOrganizationMember(orgID, email, role string) *models.OrganizationMember {
+	member := &models.OrganizationMember{ID: uuid.New().String(), Email: email, Role: role}
+	if strings.EqualFold(email, f.Email) {
+		member.ID = f.UserID
+	}
+	f.members[orgID] = append(f.members[orgID], member)
+	return member
+}
+
+func (f *Fake) addTeamMember(siteID, email, role string) *models.TeamMember {
+	member := &models.TeamMember{ID: uuid.New().String(), Email: email, Role: role}
+	f.team[siteID] = append(f.team[siteID], member)
+	return member
+}
+
+// findSite returns the site with the given name or ID
+func (f *Fake) findSite(siteIdentifier string) *models.Site {
+	for _, site := range f.sites {
+		if site.ID == siteIdentifier || site.Name == siteIdentifier {
+			return site
+		}
+	}
+	return nil
+}
+
+// siteID returns the ID of the site with the given name or ID, or the
+// identifier itself if there is no such site
+func (f *Fake) siteID(siteIdentifier string) string {
+	if site := f.findSite(siteIdentifier); site != nil {
+		return site.ID
+	}
+	return siteIdentifier
+}
+
+// site returns the site with the given name or ID or a not found error
+func (f *Fake) site(siteIdentifier string) (*models.Site, error) {
+	if site := f.findSite(siteIdentifier); site != nil {
+		return site, nil
+	}
+	return nil, notFound("Site %s not found", siteIdentifier)
+}
+
+// environment returns an environment of a site or a not found error
+func (f *Fake) environment(siteIdentifier, envID string) (*models.Site, *models.Environment, error) {
+	site, err := f.site(siteIdentifier)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, env := range f.envs[site.ID] {
+		if env.ID == envID {
+			return site, env, nil
+		}
+	}
+	return nil, nil, notFound("Environment %s not found", envID)
+}
+
+// findWorkflow returns a copy of the workflow with the given ID
+func (f *Fake) findWorkflow(workflowID string, match func(*models.Workflow) bool) (*models.Workflow, error) {
+	for _, workflow := range f.workflows {
+		if workflow.ID == workflowID && match(workflow) {
+			workflowCopy := *workflow
+			return &workflowCopy, nil
+		}
+	}
+	return nil, notFound("Workflow %s not found", workflowID)
+}
+
+// notFound returns the error the API client reports for a 404 response
+func notFound(format string, args ...interface{}) error {
+	return &api.Error{StatusCode: http.StatusNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+// clone returns shallow copies of items so that callers can't modify the fake
+func clone[T any](items []*T) []*T {
+	copies := make([]*T, 0, len(items))
+	for _, item := range items {
+		itemCopy := *item
+		copies = append(copies, &itemCopy)
+	}
+	return copies
+}
+
+// seq streams the result of a List method the way Pager.All does
+func seq[T any](items []*T, err error) iter.Seq2[*T, error] {
+	return func(yield func(*T, error) bool) {
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		for _, item := range items {
+			if !yield(item, nil) {
+				return
+			}
+		}
+	}
+}
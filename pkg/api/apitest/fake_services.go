@@ -0,0 +1,1346 @@
+package apitest
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/deviantintegral/terminus-golang/pkg/api"
+	"github.com/deviantintegral/terminus-golang/pkg/api/models"
+)
+
+// Compile-time checks that the fakes implement the interfaces
+var (
+	_ api.Auth          = fakeAuth{}
+	_ api.Backups       = fakeBackups{}
+	_ api.Domains       = fakeDomains{}
+	_ api.Environments  = fakeEnvironments{}
+	_ api.Multidev      = fakeMultidev{}
+	_ api.Organizations = fakeOrganizations{}
+	_ api.Redis         = fakeRedis{}
+	_ api.Sites         = fakeSites{}
+	_ api.Upstreams     = fakeUpstreams{}
+	_ api.Users         = fakeUsers{}
+	_ api.Workflows     = fakeWorkflows{}
+)
+
+type fakeAuth struct{ *Fake }
+
+func (f fakeAuth) Login(ctx context.Context, _ string) (*api.SessionResponse, error) {
+	unlock, err := f.call(ctx, "Auth.Login")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return &api.SessionResponse{Session: "fake-session-token", UserID: f.UserID, ExpiresAt: f.now().Add(24 * time.Hour).Unix()}, nil
+}
+
+func (f fakeAuth) Whoami(ctx context.Context, userID string) (*models.User, error) {
+	unlock, err := f.call(ctx, "Auth.Whoami")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if userID != f.UserID {
+		return nil, notFound("User %s not found", userID)
+	}
+	return &models.User{ID: f.UserID, Email: f.Email}, nil
+}
+
+func (f fakeAuth) ValidateSession(ctx context.Context, userID string) (bool, error) {
+	unlock, err := f.call(ctx, "Auth.ValidateSession")
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	return userID == f.UserID, nil
+}
+
+type fakeBackups struct{ *Fake }
+
+func (f fakeBackups) List(ctx context.Context, siteID, envID string) ([]*models.Backup, error) {
+	unlock, err := f.call(ctx, "Backups.List")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	site, _, err := f.environment(siteID, envID)
+	if err != nil {
+		return nil, err
+	}
+	return clone(f.backups[backupKey(site.ID, envID)]), nil
+}
+
+func (f fakeBackups) Get(ctx context.Context, siteID, envID, backupID string) (*models.Backup, error) {
+	unlock, err := f.call(ctx, "Backups.Get")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	backup, err := f.backup(siteID, envID, backupID)
+	if err != nil {
+		return nil, err
+	}
+	backupCopy := *backup
+	return &backupCopy, nil
+}
+
+func (f fakeBackups) Create(ctx context.Context, siteID, envID string, req *api.CreateBackupRequest) (*models.Workflow, error) {
+	unlock, err := f.call(ctx, "Backups.Create")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	site, _, err := f.environment(siteID, envID)
+	if err != nil {
+		return nil, err
+	}
+	params := map[string]interface{}{"code": true, "database": true, "files": true, "entry_type": "backup"}
+	if req != nil && req.KeepFor > 0 {
+		params["ttl"] = req.KeepFor * 86400
+	}
+	return f.startWorkflow(site.ID, envID, "do_export", params), nil
+}
+
+func (f fakeBackups) CreateElement(ctx context.Context, siteID, envID, element string) (*models.Workflow, error) {
+	unlock, err := f.call(ctx, "Backups.CreateElement")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	site, _, err := f.environment(siteID, envID)
+	if err != nil {
+		return nil, err
+	}
+	params := map[string]interface{}{element: true, "entry_type": "backup"}
+	return f.startWorkflow(site.ID, envID, "do_export", params), nil
+}
+
+func (f fakeBackups) GetDownloadURL(ctx context.Context, siteID, envID, backupID, element string) (string, error) {
+	unlock, err := f.call(ctx, "Backups.GetDownloadURL")
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	backup, err := f.backup(siteID, envID, backupID)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("https://backups.example.com/%s/%s", backup.Folder, element), nil
+}
+
+func (f fakeBackups) Download(ctx context.Context, siteID, envID, backupID, _, outputPath string) error {
+	unlock, err := f.call(ctx, "Backups.Download")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, err := f.backup(siteID, envID, backupID); err != nil {
+		return err
+	}
+	if err := os.WriteFile(outputPath, []byte("backup "+backupID+"\n"), 0o600); err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	return nil
+}
+
+func (f fakeBackups) Restore(ctx context.Context, siteID, envID, backupID string) (*models.Workflow, error) {
+	unlock, err := f.call(ctx, "Backups.Restore")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	backup, err := f.backup(siteID, envID, backupID)
+	if err != nil {
+		return nil, err
+	}
+	return f.startWorkflow(backup.SiteID, envID, "restore", map[string]interface{}{"backup_id": backupID}), nil
+}
+
+func (f fakeBackups) GetSchedule(ctx context.Context, siteID, envID string) (map[string]interface{}, error) {
+	unlock, err := f.call(ctx, "Backups.GetSchedule")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	site, _, err := f.environment(siteID, envID)
+	if err != nil {
+		return nil, err
+	}
+	schedule := map[string]interface{}{}
+	for key, value := range f.schedules[backupKey(site.ID, envID)] {
+		schedule[key] = value
+	}
+	return schedule, nil
+}
+
+func (f fakeBackups) SetSchedule(ctx context.Context, siteID, envID string, enabled bool, day int) error {
+	unlock, err := f.call(ctx, "Backups.SetSchedule")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	site, _, err := f.environment(siteID, envID)
+	if err != nil {
+		return err
+	}
+	schedule := map[string]interface{}{}
+	if enabled {
+		schedule["daily_backup_hour"] = 0
+		schedule["weekly_backup_day"] = day
+	}
+	f.schedules[backupKey(site.ID, envID)] = schedule
+	return nil
+}
+
+// backup returns a stored backup or a not found error
+func (f fakeBackups) backup(siteID, envID, backupID string) (*models.Backup, error) {
+	site, _, err := f.environment(siteID, envID)
+	if err != nil {
+		return nil, err
+	}
+	for _, backup := range f.backups[backupKey(site.ID, envID)] {
+		if backup.ID == backupID {
+			return backup, nil
+		}
+	}
+	return nil, notFound("Backup %s not found", backupID)
+}
+
+type fakeDomains struct{ *Fake }
+
+func (f fakeDomains) List(ctx context.Context, siteID, envID string) ([]*models.Domain, error) {
+	unlock, err := f.call(ctx, "Domains.List")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	site, env, err := f.environment(siteID, envID)
+	if err != nil {
+		return nil, err
+	}
+	platform := &models.Domain{ID: env.Domain, Domain: env.Domain, SiteID: site.ID, EnvironmentID: envID, Type: "platform", Status: "ok"}
+	return append([]*models.Domain{platform}, clone(f.domains[backupKey(site.ID, envID)])...), nil
+}
+
+func (f fakeDomains) Get(ctx context.Context, siteID, envID, domainID string) (*models.Domain, error) {
+	unlock, err := f.call(ctx, "Domains.Get")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	domain, _, err := f.domain(siteID, envID, domainID)
+	if err != nil {
+		return nil, err
+	}
+	domainCopy := *domain
+	return &domainCopy, nil
+}
+
+func (f fakeDomains) Add(ctx context.Context, siteID, envID, domain string) (*models.Domain, error) {
+	unlock, err := f.call(ctx, "Domains.Add")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	site, _, err := f.environment(siteID, envID)
+	if err != nil {
+		return nil, err
+	}
+	domainCopy := *f.addDomain(site.ID, envID, domain)
+	return &domainCopy, nil
+}
+
+func (f fakeDomains) Remove(ctx context.Context, siteID, envID, domainID string) error {
+	unlock, err := f.call(ctx, "Domains.Remove")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	_, key, err := f.domain(siteID, envID, domainID)
+	if err != nil {
+		return err
+	}
+	domains := f.domains[key]
+	for i, domain := range domains {
+		if domain.ID == domainID {
+			f.domains[key] = append(domains[:i], domains[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (f fakeDomains) GetDNS(ctx context.Context, siteID, envID, domainID string) ([]*models.DNSRecord, error) {
+	unlock, err := f.call(ctx, "Domains.GetDNS")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if _, _, err := f.domain(siteID, envID, domainID); err != nil {
+		return nil, err
+	}
+	_, env, _ := f.environment(siteID, envID)
+	return []*models.DNSRecord{{Type: "CNAME", Target: env.Domain, Status: "okay"}}, nil
+}
+
+// domain returns a custom domain and its storage key or a not found error
+func (f fakeDomains) domain(siteID, envID, domainID string) (*models.Domain, string, error) {
+	site, _, err := f.environment(siteID, envID)
+	if err != nil {
+		return nil, "", err
+	}
+	key := backupKey(site.ID, envID)
+	for _, domain := range f.domains[key] {
+		if domain.ID == domainID {
+			return domain, key, nil
+		}
+	}
+	return nil, "", notFound("Domain %s not found", domainID)
+}
+
+type fakeEnvironments struct{ *Fake }
+
+func (f fakeEnvironments) List(ctx context.Context, siteIdentifier string) ([]*models.Environment, error) {
+	unlock, err := f.call(ctx, "Environments.List")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	site, err := f.site(siteIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	return clone(f.envs[site.ID]), nil
+}
+
+func (f fakeEnvironments) ListForSites(ctx context.Context, siteIdentifiers []string, workers int) []api.Result[[]*models.Environment] {
+	return api.ForEach(ctx, siteIdentifiers, workers, f.List)
+}
+
+func (f fakeEnvironments) Get(ctx context.Context, siteID, envID string) (*models.Environment, error) {
+	unlock, err := f.call(ctx, "Environments.Get")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	_, env, err := f.environment(siteID, envID)
+	if err != nil {
+		return nil, err
+	}
+	envCopy := *env
+	return &envCopy, nil
+}
+
+func (f fakeEnvironments) ClearCache(ctx context.Context, siteID, envID string) (*models.Workflow, error) {
+	return f.workflow(ctx, "Environments.ClearCache", siteID, envID, "clear_cache", map[string]interface{}{})
+}
+
+func (f fakeEnvironments) Deploy(ctx context.Context, siteID, envID string, req *api.DeployRequest) (*models.Workflow, error) {
+	if req == nil {
+		req = &api.DeployRequest{}
+	}
+	return f.workflow(ctx, "Environments.Deploy", siteID, envID, "deploy", map[string]interface{}{
+		"updatedb":    req.UpdateDB,
+		"annotation":  req.Note,
+		"clear_cache": req.ClearCache,
+	})
+}
+
+func (f fakeEnvironments) CloneContent(ctx context.Context, siteID, envID string, req *api.CloneContentRequest) (*models.Workflow, error) {
+	return f.workflow(ctx, "Environments.CloneContent", siteID, envID, "clone_database_files", map[string]interface{}{
+		"from_environment": req.FromEnvironment,
+		"db":               req.Database,
+		"files":            req.Files,
+	})
+}
+
+func (f fakeEnvironments) ChangeConnectionMode(ctx context.Context, siteID, envID, mode string) (*models.Workflow, error) {
+	workflow, err := f.workflow(ctx, "Environments.ChangeConnectionMode", siteID, envID, "connection_mode_change", map[string]interface{}{"mode": mode})
+	if err == nil && workflow.IsSuccessful() {
+		f.mu.Lock()
+		if _, env, err := f.environment(siteID, envID); err == nil {
+			env.ConnectionMode = mode
+			env.OnServerDevelopment = mode == "sftp"
+		}
+		f.mu.Unlock()
+	}
+	return workflow, err
+}
+
+func (f fakeEnvironments) Commit(ctx context.Context, siteID, envID string, req *api.CommitRequest) (*models.Workflow, error) {
+	return f.workflow(ctx, "Environments.Commit", siteID, envID, "commit_and_push_on_server_changes", map[string]interface{}{"message": req.Message})
+}
+
+func (f fakeEnvironments) Wipe(ctx context.Context, siteID, envID string) (*models.Workflow, error) {
+	return f.workflow(ctx, "Environments.Wipe", siteID, envID, "wipe", map[string]interface{}{})
+}
+
+func (f fakeEnvironments) GetConnectionInfo(ctx context.Context, siteID, envID string) (*models.ConnectionInfo, error) {
+	unlock, err := f.call(ctx, "Environments.GetConnectionInfo")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	site, _, err := f.environment(siteID, envID)
+	if err != nil {
+		return nil, err
+	}
+	username := envID + "." + site.ID
+	host := fmt.Sprintf("appserver.%s.%s.drush.in", envID, site.ID)
+	sftpHost, sftpPort := host, 2222
+	if f.SFTPHost != "" {
+		sftpHost, sftpPort = f.SFTPHost, f.SFTPPort
+	}
+	mysqlHost, mysqlPort := fmt.Sprintf("dbserver.%s.%s.drush.in", envID, site.ID), 3306
+	if f.MySQLHost != "" {
+		mysqlHost, mysqlPort = f.MySQLHost, f.MySQLPort
+	}
+	return &models.ConnectionInfo{
+		SFTPHost:      sftpHost,
+		SFTPPort:      sftpPort,
+		SFTPUsername:  username,
+		SFTPCommand:   fmt.Sprintf("sftp -o Port=2222 %s@%s", username, host),
+		GitHost:       fmt.Sprintf("codeserver.dev.%s.drush.in", site.ID),
+		GitPort:       2222,
+		GitUsername:   "codeserver.dev." + site.ID,
+		MySQLHost:     mysqlHost,
+		MySQLPort:     mysqlPort,
+		MySQLUsername: "pantheon",
+		MySQLPassword: "mysql-" + site.ID,
+		MySQLDatabase: "pantheon",
+		RedisHost:     fmt.Sprintf("cacheserver.%s.%s.drush.in", envID, site.ID),
+		RedisPort:     6379,
+		RedisPassword: "redis-" + site.ID,
+	}, nil
+}
+
+func (f fakeEnvironments) Wake(ctx context.Context, siteID, envID string) error {
+	unlock, err := f.call(ctx, "Environments.Wake")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	_, _, err = f.environment(siteID, envID)
+	return err
+}
+
+func (f fakeEnvironments) GetUpstreamUpdates(ctx context.Context, siteID, envID string) (*models.UpstreamUpdate, error) {
+	unlock, err := f.call(ctx, "Environments.GetUpstreamUpdates")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if _, _, err := f.environment(siteID, envID); err != nil {
+		return nil, err
+	}
+	return &models.UpstreamUpdate{}, nil
+}
+
+func (f fakeEnvironments) ApplyUpstreamUpdates(ctx context.Context, siteID, envID string, updateDB, acceptUpstream bool) (*models.Workflow, error) {
+	return f.workflow(ctx, "Environments.ApplyUpstreamUpdates", siteID, envID, "apply_upstream_updates", map[string]interface{}{
+		"updatedb":        updateDB,
+		"accept_upstream": acceptUpstream,
+	})
+}
+
+func (f fakeEnvironments) GetLock(ctx context.Context, siteID, envID string) (*models.Lock, error) {
+	unlock, err := f.call(ctx, "Environments.GetLock")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	site, _, err := f.environment(siteID, envID)
+	if err != nil {
+		return nil, err
+	}
+	if lock, ok := f.locks[backupKey(site.ID, envID)]; ok {
+		lockCopy := *lock
+		return &lockCopy, nil
+	}
+	return &models.Lock{}, nil
+}
+
+func (f fakeEnvironments) SetLock(ctx context.Context, siteID, envID, username, password string) error {
+	return f.setLock(ctx, "Environments.SetLock", siteID, envID, &models.Lock{Username: username, Password: password, Locked: true})
+}
+
+func (f fakeEnvironments) RemoveLock(ctx context.Context, siteID, envID string) error {
+	return f.setLock(ctx, "Environments.RemoveLock", siteID, envID, &models.Lock{})
+}
+
+func (f fakeEnvironments) GetMetrics(ctx context.Context, siteIdentifier, envID, _ string) ([]*models.Metrics, error) {
+	unlock, err := f.call(ctx, "Environments.GetMetrics")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if _, _, err := f.environment(siteIdentifier, envID); err != nil {
+		return nil, err
+	}
+	return []*models.Metrics{}, nil
+}
+
+// workflow starts an environment workflow
+func (f fakeEnvironments) workflow(ctx context.Context, method, siteID, envID, workflowType string, params map[string]interface{}) (*models.Workflow, error) {
+	unlock, err := f.call(ctx, method)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	site, _, err := f.environment(siteID, envID)
+	if err != nil {
+		return nil, err
+	}
+	return f.startWorkflow(site.ID, envID, workflowType, params), nil
+}
+
+// setLock replaces an environment's lock
+func (f fakeEnvironments) setLock(ctx context.Context, method, siteID, envID string, lock *models.Lock) error {
+	unlock, err := f.call(ctx, method)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	site, env, err := f.environment(siteID, envID)
+	if err != nil {
+		return err
+	}
+	f.locks[backupKey(site.ID, envID)] = lock
+	env.Locked = lock.Locked
+	return nil
+}
+
+type fakeMultidev struct{ *Fake }
+
+func (f fakeMultidev) Create(ctx context.Context, siteID, envName, fromEnv string) (*models.Workflow, error) {
+	unlock, err := f.call(ctx, "Multidev.Create")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	site, _, err := f.environment(siteID, fromEnv)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := f.environment(site.ID, envName); err == nil {
+		return nil, &api.Error{StatusCode: 409, Message: fmt.Sprintf("Environment %s already exists", envName)}
+	}
+	return f.startWorkflow(site.ID, "", "create_cloud_development_environment", map[string]interface{}{
+		"environment_id":   envName,
+		"from_environment": fromEnv,
+	}), nil
+}
+
+func (f fakeMultidev) Delete(ctx context.Context, siteID, envID string, deleteBranch bool) (*models.Workflow, error) {
+	return f.workflow(ctx, "Multidev.Delete", siteID, envID, "delete_cloud_development_environment", map[string]interface{}{
+		"environment_id": envID,
+		"delete_branch":  deleteBranch,
+	})
+}
+
+func (f fakeMultidev) MergeToDev(ctx context.Context, siteID, envID string, updateDB bool) (*models.Workflow, error) {
+	return f.workflow(ctx, "Multidev.MergeToDev", siteID, envID, "merge_cloud_development_environment_into_dev", map[string]interface{}{
+		"from_environment": envID,
+		"updatedb":         updateDB,
+	})
+}
+
+func (f fakeMultidev) MergeFromDev(ctx context.Context, siteID, envID string, updateDB bool) (*models.Workflow, error) {
+	return f.workflow(ctx, "Multidev.MergeFromDev", siteID, envID, "merge_dev_into_cloud_development_environment", map[string]interface{}{
+		"updatedb": updateDB,
+	})
+}
+
+func (f fakeMultidev) List(ctx context.Context, siteID string) ([]*models.Environment, error) {
+	unlock, err := f.call(ctx, "Multidev.List")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	site, err := f.site(siteID)
+	if err != nil {
+		return nil, err
+	}
+	var multidevs []*models.Environment
+	for _, env := range f.envs[site.ID] {
+		if env.ID != "dev" && env.ID != "test" && env.ID != "live" {
+			multidevs = append(multidevs, env)
+		}
+	}
+	return clone(multidevs), nil
+}
+
+// workflow starts a workflow on an existing multidev
+func (f fakeMultidev) workflow(ctx context.Context, method, siteID, envID, workflowType string, params map[string]interface{}) (*models.Workflow, error) {
+	return fakeEnvironments(f).workflow(ctx, method, siteID, envID, workflowType, params)
+}
+
+type fakeOrganizations struct{ *Fake }
+
+func (f fakeOrganizations) List(ctx context.Context, _ string) ([]*models.Organization, error) {
+	unlock, err := f.call(ctx, "Organizations.List")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return clone(f.orgs), nil
+}
+
+func (f fakeOrganizations) ListIter(ctx context.Context, userID string) iter.Seq2[*models.Organization, error] {
+	return seq(f.List(ctx, userID))
+}
+
+func (f fakeOrganizations) Resolve(ctx context.Context, _, orgIdentifier string) (string, error) {
+	unlock, err := f.call(ctx, "Organizations.Resolve")
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	org, err := f.org(orgIdentifier)
+	if err != nil {
+		return "", err
+	}
+	return org.ID, nil
+}
+
+func (f fakeOrganizations) Get(ctx context.Context, orgID string) (*models.Organization, error) {
+	unlock, err := f.call(ctx, "Organizations.Get")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	org, err := f.org(orgID)
+	if err != nil {
+		return nil, err
+	}
+	orgCopy := *org
+	return &orgCopy, nil
+}
+
+func (f fakeOrganizations) ListMembers(ctx context.Context, orgID string) ([]*models.User, error) {
+	unlock, err := f.call(ctx, "Organizations.ListMembers")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if _, err := f.org(orgID); err != nil {
+		return nil, err
+	}
+	users := make([]*models.User, 0, len(f.members[orgID]))
+	for _, member := range f.members[orgID] {
+		users = append(users, &models.User{ID: member.ID, Email: member.Email, FirstName: member.FirstName, LastName: member.LastName})
+	}
+	return users, nil
+}
+
+func (f fakeOrganizations) ListMembersIter(ctx context.Context, orgID string) iter.Seq2[*models.User, error] {
+	return seq(f.ListMembers(ctx, orgID))
+}
+
+func (f fakeOrganizations) ListMemberships(ctx context.Context, orgID string) ([]*models.OrganizationMember, error) {
+	unlock, err := f.call(ctx, "Organizations.ListMemberships")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	org, err := f.org(orgID)
+	if err != nil {
+		return nil, err
+	}
+	return clone(f.members[org.ID]), nil
+}
+
+func (f fakeOrganizations) AddMember(ctx context.Context, orgID string, req *api.AddOrganizationMemberRequest) (*models.OrganizationMember, error) {
+	unlock, err := f.call(ctx, "Organizations.AddMember")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	org, err := f.org(orgID)
+	if err != nil {
+		return nil, err
+	}
+	for _, member := range f.members[org.ID] {
+		if strings.EqualFold(member.Email, req.Email) {
+			return nil, &api.Error{StatusCode: 409, Message: fmt.Sprintf("%s is already a member of the organization", req.Email)}
+		}
+	}
+	memberCopy := *f.addOrganizationMember(org.ID, req.Email, req.Role)
+	return &memberCopy, nil
+}
+
+func (f fakeOrganizations) RemoveMember(ctx context.Context, orgID, userID string) error {
+	unlock, err := f.call(ctx, "Organizations.RemoveMember")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	org, err := f.org(orgID)
+	if err != nil {
+		return err
+	}
+	members := f.members[org.ID]
+	for i, member := range members {
+		if member.ID == userID {
+			f.members[org.ID] = append(members[:i], members[i+1:]...)
+			return nil
+		}
+	}
+	return notFound("User %s is not a member of the organization", userID)
+}
+
+func (f fakeOrganizations) UpdateMemberRole(ctx context.Context, orgID, userID, role string) error {
+	unlock, err := f.call(ctx, "Organizations.UpdateMemberRole")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	org, err := f.org(orgID)
+	if err != nil {
+		return err
+	}
+	for _, member := range f.members[org.ID] {
+		if member.ID == userID {
+			member.Role = role
+			return nil
+		}
+	}
+	return notFound("User %s is not a member of the organization", userID)
+}
+
+func (f fakeOrganizations) ListUpstreams(ctx context.Context, orgID string) ([]*models.Upstream, error) {
+	unlock, err := f.call(ctx, "Organizations.ListUpstreams")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if _, err := f.org(orgID); err != nil {
+		return nil, err
+	}
+	var upstreams []*models.Upstream
+	for _, upstream := range f.upstreams {
+		if upstream.Organization == orgID {
+			upstreams = append(upstreams, upstream)
+		}
+	}
+	return clone(upstreams), nil
+}
+
+// org returns the organization with the given ID, name or label
+func (f fakeOrganizations) org(orgIdentifier string) (*models.Organization, error) {
+	for _, org := range f.orgs {
+		if org.ID == orgIdentifier || org.Name == orgIdentifier || org.Label == orgIdentifier {
+			return org, nil
+		}
+	}
+	return nil, notFound("Organization %s not found", orgIdentifier)
+}
+
+type fakeRedis struct{ *Fake }
+
+func (f fakeRedis) Enable(ctx context.Context, siteID string) (*models.Workflow, error) {
+	return f.workflow(ctx, "Redis.Enable", siteID, "enable_addon")
+}
+
+func (f fakeRedis) Disable(ctx context.Context, siteID string) (*models.Workflow, error) {
+	return f.workflow(ctx, "Redis.Disable", siteID, "disable_addon")
+}
+
+// workflow starts a Redis add-on workflow
+func (f fakeRedis) workflow(ctx context.Context, method, siteID, workflowType string) (*models.Workflow, error) {
+	unlock, err := f.call(ctx, method)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	site, err := f.site(siteID)
+	if err != nil {
+		return nil, err
+	}
+	return f.startWorkflow(site.ID, "", workflowType, map[string]interface{}{"addon": "cacheserver"}), nil
+}
+
+type fakeSites struct{ *Fake }
+
+func (f fakeSites) List(ctx context.Context, userID string) ([]*models.Site, error) {
+	unlock, err := f.call(ctx, "Sites.List")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	sites := clone(f.sites)
+	for _, site := range sites {
+		site.MembershipUserID = userID
+	}
+	return sites, nil
+}
+
+func (f fakeSites) ListIter(ctx context.Context, userID string) iter.Seq2[*models.Site, error] {
+	return seq(f.List(ctx, userID))
+}
+
+func (f fakeSites) Get(ctx context.Context, siteIdentifier string) (*models.Site, error) {
+	unlock, err := f.call(ctx, "Sites.Get")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	site, err := f.site(siteIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	siteCopy := *site
+	return &siteCopy, nil
+}
+
+func (f fakeSites) Create(ctx context.Context, _ string, req *api.CreateSiteRequest) (*models.Site, error) {
+	unlock, err := f.call(ctx, "Sites.Create")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if f.findSite(req.SiteName) != nil {
+		return nil, &api.Error{StatusCode: 409, Message: fmt.Sprintf("Site name %s is already taken", req.SiteName)}
+	}
+	upstreamID, err := fakeUpstreams(f).resolve(req.UpstreamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve upstream: %w", err)
+	}
+
+	params := map[string]interface{}{"site_name": req.SiteName, "label": req.Label}
+	if req.Organization != "" {
+		params["organization_id"] = req.Organization
+	}
+	if req.Region != "" {
+		params["preferred_zone"] = req.Region
+	}
+	workflow := f.startWorkflow("", "", "create_site", params)
+	if !workflow.IsSuccessful() {
+		return nil, fmt.Errorf("site creation workflow failed: %w", api.NewWorkflowError(workflow))
+	}
+
+	site := f.findSite(workflow.FinalTask.SiteID)
+	deploy := f.startWorkflow(site.ID, "", "deploy_product", map[string]interface{}{"product_id": upstreamID})
+	if !deploy.IsSuccessful() {
+		return nil, fmt.Errorf("product deployment workflow failed: %w", api.NewWorkflowError(deploy))
+	}
+	site.Upstream = upstreamID
+	if req.Region != "" {
+		site.PreferredZone = req.Region
+	}
+
+	siteCopy := *site
+	return &siteCopy, nil
+}
+
+func (f fakeSites) Delete(ctx context.Context, siteIdentifier string) error {
+	unlock, err := f.call(ctx, "Sites.Delete")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	site, err := f.site(siteIdentifier)
+	if err != nil {
+		return err
+	}
+	workflow := f.startWorkflow(site.ID, "", "delete_site", map[string]interface{}{})
+	if !workflow.IsSuccessful() {
+		return fmt.Errorf("site deletion workflow failed: %w", api.NewWorkflowError(workflow))
+	}
+	return nil
+}
+
+func (f fakeSites) Update(ctx context.Context, siteIdentifier string, req *api.UpdateRequest) (*models.Site, error) {
+	unlock, err := f.call(ctx, "Sites.Update")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	site, err := f.site(siteIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	if req.Label != "" {
+		site.Label = req.Label
+	}
+	if req.ServiceLevel != "" {
+		site.Service = req.ServiceLevel
+	}
+	siteCopy := *site
+	return &siteCopy, nil
+}
+
+func (f fakeSites) ListByOrganization(ctx context.Context, orgID string) ([]*models.Site, error) {
+	unlock, err := f.call(ctx, "Sites.ListByOrganization")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	var sites []*models.Site
+	for _, siteID := range f.orgSites[orgID] {
+		if site := f.findSite(siteID); site != nil {
+			sites = append(sites, site)
+		}
+	}
+	return clone(sites), nil
+}
+
+func (f fakeSites) ListByOrganizationIter(ctx context.Context, orgID string) iter.Seq2[*models.Site, error] {
+	return seq(f.ListByOrganization(ctx, orgID))
+}
+
+func (f fakeSites) GetTeam(ctx context.Context, siteIdentifier string) ([]*models.TeamMember, error) {
+	unlock, err := f.call(ctx, "Sites.GetTeam")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	site, err := f.site(siteIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	return clone(f.team[site.ID]), nil
+}
+
+func (f fakeSites) AddTeamMember(ctx context.Context, siteIdentifier string, req *api.AddTeamMemberRequest) (*models.TeamMember, error) {
+	unlock, err := f.call(ctx, "Sites.AddTeamMember")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	site, err := f.site(siteIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	for _, member := range f.team[site.ID] {
+		if strings.EqualFold(member.Email, req.Email) {
+			return nil, &api.Error{StatusCode: 409, Message: fmt.Sprintf("%s is already a team member", req.Email)}
+		}
+	}
+	memberCopy := *f.addTeamMember(site.ID, req.Email, req.Role)
+	return &memberCopy, nil
+}
+
+func (f fakeSites) RemoveTeamMember(ctx context.Context, siteIdentifier, userID string) error {
+	unlock, err := f.call(ctx, "Sites.RemoveTeamMember")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	site, err := f.site(siteIdentifier)
+	if err != nil {
+		return err
+	}
+	team := f.team[site.ID]
+	for i, member := range team {
+		if member.ID == userID {
+			f.team[site.ID] = append(team[:i], team[i+1:]...)
+			return nil
+		}
+	}
+	return notFound("User %s is not a member of the team", userID)
+}
+
+func (f fakeSites) UpdateTeamMemberRole(ctx context.Context, siteIdentifier, userID, role string) error {
+	unlock, err := f.call(ctx, "Sites.UpdateTeamMemberRole")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	site, err := f.site(siteIdentifier)
+	if err != nil {
+		return err
+	}
+	for _, member := range f.team[site.ID] {
+		if member.ID == userID {
+			member.Role = role
+			return nil
+		}
+	}
+	return notFound("User %s is not a member of the team", userID)
+}
+
+func (f fakeSites) GetTags(ctx context.Context, siteIdentifier, orgID string) ([]*models.Tag, error) {
+	unlock, err := f.call(ctx, "Sites.GetTags")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	site, err := f.site(siteIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	return clone(f.tags[site.ID+"/"+orgID]), nil
+}
+
+func (f fakeSites) AddTag(ctx context.Context, siteIdentifier, orgID, tagName string) error {
+	unlock, err := f.call(ctx, "Sites.AddTag")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	site, err := f.site(siteIdentifier)
+	if err != nil {
+		return err
+	}
+	key := site.ID + "/" + orgID
+	for _, tag := range f.tags[key] {
+		if tag.Name == tagName {
+			return nil
+		}
+	}
+	f.tags[key] = append(f.tags[key], &models.Tag{ID: tagName, Name: tagName, SiteID: site.ID, OrgID: orgID})
+	return nil
+}
+
+func (f fakeSites) RemoveTag(ctx context.Context, siteIdentifier, orgID, tagName string) error {
+	unlock, err := f.call(ctx, "Sites.RemoveTag")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	site, err := f.site(siteIdentifier)
+	if err != nil {
+		return err
+	}
+	key := site.ID + "/" + orgID
+	tags := f.tags[key]
+	for i, tag := range tags {
+		if tag.Name == tagName {
+			f.tags[key] = append(tags[:i], tags[i+1:]...)
+			return nil
+		}
+	}
+	return notFound("Tag %s not found", tagName)
+}
+
+func (f fakeSites) GetPlan(ctx context.Context, siteIdentifier string) (*models.Plan, error) {
+	unlock, err := f.call(ctx, "Sites.GetPlan")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	site, err := f.site(siteIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	return &models.Plan{ID: "plan-" + site.Service, Name: site.PlanName, Label: site.PlanName, SKU: "plan-" + site.Service}, nil
+}
+
+func (f fakeSites) ListBranches(ctx context.Context, siteIdentifier string) ([]*models.Branch, error) {
+	unlock, err := f.call(ctx, "Sites.ListBranches")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	site, err := f.site(siteIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	branches := []*models.Branch{{ID: "master", SHA: fmt.Sprintf("%040x", 1)}}
+	for _, env := range f.envs[site.ID] {
+		if env.ID != "dev" && env.ID != "test" && env.ID != "live" {
+			branches = append(branches, &models.Branch{ID: env.ID, SHA: fmt.Sprintf("%040x", len(branches)+1)})
+		}
+	}
+	return branches, nil
+}
+
+func (f fakeSites) GetPlans(ctx context.Context, siteIdentifier string) ([]*models.Plan, error) {
+	plan, err := f.GetPlan(ctx, siteIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	return []*models.Plan{plan}, nil
+}
+
+func (f fakeSites) ListOrganizations(ctx context.Context, siteIdentifier string) ([]*models.SiteOrganizationMembership, error) {
+	unlock, err := f.call(ctx, "Sites.ListOrganizations")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	site, err := f.site(siteIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	var memberships []*models.SiteOrganizationMembership
+	for _, org := range f.orgs {
+		for _, siteID := range f.orgSites[org.ID] {
+			if siteID == site.ID {
+				memberships = append(memberships, &models.SiteOrganizationMembership{OrgID: org.ID, OrgName: org.Label})
+				break
+			}
+		}
+	}
+	return memberships, nil
+}
+
+type fakeUpstreams struct{ *Fake }
+
+func (f fakeUpstreams) ResolveToID(ctx context.Context, upstreamIdentifier, _ string) (string, error) {
+	unlock, err := f.call(ctx, "Upstreams.ResolveToID")
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	return f.resolve(upstreamIdentifier)
+}
+
+func (f fakeUpstreams) Get(ctx context.Context, upstreamID string) (*models.Upstream, error) {
+	unlock, err := f.call(ctx, "Upstreams.Get")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	for _, upstream := range f.upstreams {
+		if upstream.ID == upstreamID {
+			upstreamCopy := *upstream
+			return &upstreamCopy, nil
+		}
+	}
+	return nil, notFound("Upstream %s not found", upstreamID)
+}
+
+func (f fakeUpstreams) List(ctx context.Context, _ string) ([]*models.Upstream, error) {
+	unlock, err := f.call(ctx, "Upstreams.List")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return clone(f.upstreams), nil
+}
+
+func (f fakeUpstreams) ListUpdates(ctx context.Context, siteID, envID string) ([]*models.UpstreamUpdateCommit, error) {
+	unlock, err := f.call(ctx, "Upstreams.ListUpdates")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if _, _, err := f.environment(siteID, envID); err != nil {
+		return nil, err
+	}
+	return []*models.UpstreamUpdateCommit{}, nil
+}
+
+// resolve accepts an upstream ID or machine name, like ResolveToID
+func (f fakeUpstreams) resolve(upstreamIdentifier string) (string, error) {
+	if api.IsUUID(upstreamIdentifier) {
+		return upstreamIdentifier, nil
+	}
+	for _, upstream := range f.upstreams {
+		if upstream.MachineName == upstreamIdentifier {
+			return upstream.ID, nil
+		}
+	}
+	return "", fmt.Errorf("upstream not found: %s", upstreamIdentifier)
+}
+
+type fakeUsers struct{ *Fake }
+
+func (f fakeUsers) ListMachineTokens(ctx context.Context, _ string) ([]*models.MachineToken, error) {
+	unlock, err := f.call(ctx, "Users.ListMachineTokens")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return clone(f.tokens), nil
+}
+
+func (f fakeUsers) ListSSHKeys(ctx context.Context, _ string) ([]*models.SSHKey, error) {
+	unlock, err := f.call(ctx, "Users.ListSSHKeys")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return clone(f.keys), nil
+}
+
+func (f fakeUsers) ListPaymentMethods(ctx context.Context, _ string) ([]*models.PaymentMethod, error) {
+	unlock, err := f.call(ctx, "Users.ListPaymentMethods")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return []*models.PaymentMethod{}, nil
+}
+
+type fakeWorkflows struct{ *Fake }
+
+func (f fakeWorkflows) List(ctx context.Context, siteID string) ([]*models.Workflow, error) {
+	return f.list(ctx, "Workflows.List", siteID, func(*models.Workflow) bool { return true })
+}
+
+func (f fakeWorkflows) ListForEnvironment(ctx context.Context, siteID, envID string) ([]*models.Workflow, error) {
+	return f.list(ctx, "Workflows.ListForEnvironment", siteID, func(workflow *models.Workflow) bool {
+		return workflow.EnvironmentID == envID
+	})
+}
+
+func (f fakeWorkflows) Get(ctx context.Context, siteID, workflowID string) (*models.Workflow, error) {
+	unlock, err := f.call(ctx, "Workflows.Get")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	site, err := f.site(siteID)
+	if err != nil {
+		return nil, err
+	}
+	return f.findWorkflow(workflowID, func(workflow *models.Workflow) bool { return workflow.SiteID == site.ID })
+}
+
+func (f fakeWorkflows) Wait(ctx context.Context, siteID, workflowID string, opts *api.WaitOptions) (*models.Workflow, error) {
+	workflow, err := f.Get(ctx, siteID, workflowID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check workflow status: %w", err)
+	}
+	if opts != nil && opts.OnProgress != nil {
+		opts.OnProgress(workflow)
+	}
+	return workflow, nil
+}
+
+func (f fakeWorkflows) Watch(ctx context.Context, siteID, workflowID string, opts *api.WatchOptions) error {
+	if opts == nil || opts.OnUpdate == nil {
+		return fmt.Errorf("OnUpdate callback is required")
+	}
+	workflow, err := f.Get(ctx, siteID, workflowID)
+	if err != nil {
+		return fmt.Errorf("failed to check workflow status: %w", err)
+	}
+	opts.OnUpdate(workflow)
+	return nil
+}
+
+func (f fakeWorkflows) CreateForUser(ctx context.Context, _, workflowType string, params map[string]interface{}) (*models.Workflow, error) {
+	unlock, err := f.call(ctx, "Workflows.CreateForUser")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return f.startWorkflow("", "", workflowType, params), nil
+}
+
+func (f fakeWorkflows) GetForUser(ctx context.Context, userID, workflowID string) (*models.Workflow, error) {
+	unlock, err := f.call(ctx, "Workflows.GetForUser")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return f.findWorkflow(workflowID, func(workflow *models.Workflow) bool { return workflow.UserID == userID })
+}
+
+func (f fakeWorkflows) WaitForUser(ctx context.Context, userID, workflowID string, opts *api.WaitOptions) (*models.Workflow, error) {
+	workflow, err := f.GetForUser(ctx, userID, workflowID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check workflow status: %w", err)
+	}
+	if opts != nil && opts.OnProgress != nil {
+		opts.OnProgress(workflow)
+	}
+	return workflow, nil
+}
+
+func (f fakeWorkflows) CreateForSite(ctx context.Context, siteID, workflowType string, params map[string]interface{}) (*models.Workflow, error) {
+	unlock, err := f.call(ctx, "Workflows.CreateForSite")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	site, err := f.site(siteID)
+	if err != nil {
+		return nil, err
+	}
+	return f.startWorkflow(site.ID, "", workflowType, params), nil
+}
+
+// list returns copies of a site's workflows that match, newest first
+func (f fakeWorkflows) list(ctx context.Context, method, siteID string, match func(*models.Workflow) bool) ([]*models.Workflow, error) {
+	unlock, err := f.call(ctx, method)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	site, err := f.site(siteID)
+	if err != nil {
+		return nil, err
+	}
+	var workflows []*models.Workflow
+	for i := len(f.workflows) - 1; i >= 0; i-- {
+		if workflow := f.workflows[i]; workflow.SiteID == site.ID && match(workflow) {
+			workflows = append(workflows, workflow)
+		}
+	}
+	return clone(workflows), nil
+}
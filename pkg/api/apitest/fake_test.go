@@ -0,0 +1,130 @@
+package apitest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/deviantintegral/terminus-golang/pkg/api"
+)
+
+func TestFakeSitesAndEnvironments(t *testing.T) {
+	fake := NewFake()
+	site := fake.AddSite("my-site")
+	pantheon := fake.Pantheon()
+	ctx := context.Background()
+
+	got, err := pantheon.Sites.Get(ctx, "my-site")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.ID != site.ID {
+		t.Errorf("expected site %s, got %s", site.ID, got.ID)
+	}
+
+	var names []string
+	for site, err := range pantheon.Sites.ListIter(ctx, fake.UserID) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		names = append(names, site.Name)
+	}
+	if len(names) != 1 || names[0] != "my-site" {
+		t.Errorf("unexpected sites: %v", names)
+	}
+
+	if _, err := pantheon.Environments.Get(ctx, site.ID, "missing"); !api.IsNotFound(err) {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+func TestFakeWorkflowSideEffects(t *testing.T) {
+	fake := NewFake()
+	site := fake.AddSite("my-site")
+	pantheon := fake.Pantheon()
+	ctx := context.Background()
+
+	workflow, err := pantheon.Multidev.Create(ctx, site.ID, "feature", "dev")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	finished, err := pantheon.Workflows.Wait(ctx, "my-site", workflow.ID, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !finished.IsSuccessful() {
+		t.Fatalf("expected workflow to succeed, got %q", finished.Result)
+	}
+	if _, err := pantheon.Environments.Get(ctx, site.ID, "feature"); err != nil {
+		t.Errorf("expected multidev to be created: %v", err)
+	}
+
+	if _, err := pantheon.Backups.CreateElement(ctx, site.ID, "live", "database"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	backups, err := pantheon.Backups.List(ctx, site.ID, "live")
+	if err != nil || len(backups) != 1 {
+		t.Errorf("expected one backup, got %d (%v)", len(backups), err)
+	}
+
+	if err := pantheon.Sites.Delete(ctx, "my-site"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := fake.Site(site.ID); ok {
+		t.Error("expected site to be deleted")
+	}
+}
+
+func TestFakeFailures(t *testing.T) {
+	fake := NewFake()
+	site := fake.AddSite("my-site")
+	pantheon := fake.Pantheon()
+	ctx := context.Background()
+
+	fake.FailWorkflows("deploy", "Merge conflict")
+	workflow, err := pantheon.Environments.Deploy(ctx, site.ID, "test", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !workflow.IsFailed() || workflow.GetMessage() != "Merge conflict" {
+		t.Errorf("expected failed workflow, got %q: %q", workflow.Result, workflow.GetMessage())
+	}
+
+	injected := errors.New("boom")
+	fake.FailMethod("Sites.List", injected)
+	for _, err := range pantheon.Sites.ListIter(ctx, fake.UserID) {
+		if !errors.Is(err, injected) {
+			t.Errorf("expected injected error, got %v", err)
+		}
+	}
+
+	fake.FailMethod("Sites.List", nil)
+	if _, err := pantheon.Sites.List(ctx, fake.UserID); err != nil {
+		t.Errorf("expected failure to be cleared, got %v", err)
+	}
+}
+
+func TestFakeOrganizations(t *testing.T) {
+	fake := NewFake()
+	org := fake.AddOrganization("my-org", "My Org")
+	site := fake.AddSite("my-site")
+	fake.AddSiteToOrganization(org.ID, "my-site")
+	fake.AddOrganizationMember(org.ID, "member@example.com", "team_member")
+	pantheon := fake.Pantheon()
+	ctx := context.Background()
+
+	orgID, err := pantheon.Organizations.Resolve(ctx, fake.UserID, "My Org")
+	if err != nil || orgID != org.ID {
+		t.Fatalf("expected %s, got %s (%v)", org.ID, orgID, err)
+	}
+
+	sites, err := pantheon.Sites.ListByOrganization(ctx, orgID)
+	if err != nil || len(sites) != 1 || sites[0].ID != site.ID {
+		t.Errorf("expected organization site, got %v (%v)", sites, err)
+	}
+
+	members, err := pantheon.Organizations.ListMembers(ctx, orgID)
+	if err != nil || len(members) != 1 {
+		t.Errorf("expected one member, got %v (%v)", members, err)
+	}
+}
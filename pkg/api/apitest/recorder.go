@@ -0,0 +1,343 @@
+package apitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/deviantintegral/terminus-golang/pkg/api"
+)
+
+// Mode selects whether a Recorder captures live responses or replays fixtures
+type Mode int
+
+const (
+	// ModeReplay serves responses from previously recorded fixtures
+	ModeReplay Mode = iota
+	// ModeRecord sends requests to the real API and saves the responses
+	ModeRecord
+)
+
+// RecordEnv is the environment variable that switches ModeFromEnv to recording
+const RecordEnv = "TERMINUS_RECORD_FIXTURES"
+
+// ModeFromEnv returns ModeRecord when TERMINUS_RECORD_FIXTURES is set and
+// ModeReplay otherwise
+func ModeFromEnv() Mode {
+	if os.Getenv(RecordEnv) != "" {
+		return ModeRecord
+	}
+	return ModeReplay
+}
+
+// Recorder is an http.RoundTripper that records API exchanges as fixtures and
+// replays them. Like pkg/api/testdata/fixtures, each fixture is an indented
+// JSON file with sensitive data redacted by api.RedactSensitiveData, but the
+// contents and names differ. Those fixtures hold the decoded result of a
+// command and are named after it, such as site_info.json. A Recorder works
+// below the services instead, so it saves each response body exactly as the
+// API returned it, letting replay exercise decoding, and names the file after
+// the request, since one command can make several. Error responses are saved
+// in a file with an _error suffix as {"status": <code>, "error": <body>},
+// because replay needs the status code as well as the body.
+//
+// UUIDs in responses are replaced with stable placeholders such as
+// redacted-0000-0000-0000-000000000001 before redaction, so that the
+// relationships between recorded responses survive replay. Fixture names are
+// derived from the request method, path and query, with UUIDs and their
+// placeholders replaced by "ID". Repeated requests, such as workflow polls,
+// are numbered in the order they were made; on replay the last recording is
+// served once the sequence is exhausted.
+type Recorder struct {
+	mode      Mode
+	dir       string
+	transport http.RoundTripper
+
+	mu    sync.Mutex
+	calls map[string]int
+	ids   map[string]string
+}
+
+// NewRecorder creates a recorder that reads and writes fixtures in dir.
+// In record mode requests are sent with http.DefaultTransport.
+func NewRecorder(dir string, mode Mode) *Recorder {
+	return &Recorder{
+		mode:      mode,
+		dir:       dir,
+		transport: http.DefaultTransport,
+		calls:     make(map[string]int),
+		ids:       make(map[string]string),
+	}
+}
+
+// WithTransport sets the transport used to reach the real API in record mode
+func (r *Recorder) WithTransport(transport http.RoundTripper) *Recorder {
+	r.transport = transport
+	return r
+}
+
+// Client returns an HTTP client that uses the recorder, for use with
+// api.WithHTTPClient
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// errorFixture is the saved form of a non-2xx response
+type errorFixture struct {
+	Status int             `json:"status"`
+	Error  json.RawMessage `json:"error"`
+}
+
+// RoundTrip records or replays a single exchange
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	name := r.nextName(req)
+	if r.mode == ModeRecord {
+		return r.record(req, name)
+	}
+	return r.replay(req, name)
+}
+
+// record performs the request and saves the response as a fixture
+func (r *Recorder) record(req *http.Request, name string) (*http.Response, error) {
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response for fixture %s: %w", name, err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	data := r.pseudonymize(body)
+	if resp.StatusCode >= http.StatusBadRequest {
+		name += "_error"
+		data, err = json.Marshal(errorFixture{Status: resp.StatusCode, Error: asJSON(data)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode fixture %s: %w", name, err)
+		}
+	}
+
+	if err := writeFixture(filepath.Join(r.dir, name+".json"), data); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// replay serves a recorded response
+func (r *Recorder) replay(req *http.Request, name string) (*http.Response, error) {
+	path, err := r.findFixture(name)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // Fixture path
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture: %w", err)
+	}
+
+	status := http.StatusOK
+	if strings.HasSuffix(path, "_error.json") {
+		var fixture errorFixture
+		if err := json.Unmarshal(data, &fixture); err != nil {
+			return nil, fmt.Errorf("failed to parse fixture %s: %w", path, err)
+		}
+		status = fixture.Status
+		data = fixture.Error
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(data)),
+		ContentLength: int64(len(data)),
+		Request:       req,
+	}, nil
+}
+
+// findFixture locates the fixture for name, falling back to the latest earlier
+// recording in a numbered sequence
+func (r *Recorder) findFixture(name string) (string, error) {
+	base, n := splitSequence(name)
+	for ; n >= 1; n-- {
+		candidate := base
+		if n > 1 {
+			candidate = fmt.Sprintf("%s_%d", base, n)
+		}
+		for _, suffix := range []string{".json", "_error.json"} {
+			path := filepath.Join(r.dir, candidate+suffix)
+			if _, err := os.Stat(path); err == nil {
+				return path, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no fixture recorded for %s in %s", base, r.dir)
+}
+
+// nextName returns the fixture name for the next occurrence of a request
+func (r *Recorder) nextName(req *http.Request) string {
+	name := FixtureName(req)
+
+	r.mu.Lock()
+	r.calls[name]++
+	n := r.calls[name]
+	r.mu.Unlock()
+
+	if n > 1 {
+		return fmt.Sprintf("%s_%d", name, n)
+	}
+	return name
+}
+
+// pseudonymize replaces each UUID in data with a placeholder that is stable
+// for the lifetime of the recorder
+func (r *Recorder) pseudonymize(data []byte) []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return uuidPattern.ReplaceAllFunc(data, func(id []byte) []byte {
+		key := strings.ToLower(string(id))
+		placeholder, ok := r.ids[key]
+		if !ok {
+			placeholder = fmt.Sprintf("redacted-0000-0000-0000-%012d", len(r.ids)+1)
+			r.ids[key] = placeholder
+		}
+		return []byte(placeholder)
+	})
+}
+
+// splitSequence splits a numbered fixture name into its base and number
+func splitSequence(name string) (string, int) {
+	if i := strings.LastIndex(name, "_"); i > 0 {
+		var n int
+		if _, err := fmt.Sscanf(name[i+1:], "%d", &n); err == nil && fmt.Sprint(n) == name[i+1:] && n > 1 {
+			return name[:i], n
+		}
+	}
+	return name, 1
+}
+
+var (
+	uuidPattern        = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+	placeholderPattern = regexp.MustCompile(`redacted-0000-0000-0000-[0-9]{12}`)
+	fixtureNameUnsafe  = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+	fixtureNameRepeats = regexp.MustCompile(`_+`)
+)
+
+// FixtureName returns the fixture name used for a request, such as
+// "get_sites_ID_environments" for GET /api/sites/<uuid>/environments
+func FixtureName(req *http.Request) string {
+	path := strings.TrimPrefix(req.URL.Path, "/api/")
+	parts := []string{strings.ToLower(req.Method), path}
+
+	query := req.URL.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		parts = append(parts, key+"-"+strings.Join(query[key], ","))
+	}
+
+	name := uuidPattern.ReplaceAllString(strings.Join(parts, "_"), "ID")
+	name = placeholderPattern.ReplaceAllString(name, "ID")
+	name = fixtureNameUnsafe.ReplaceAllString(name, "_")
+	name = fixtureNameRepeats.ReplaceAllString(name, "_")
+	return strings.Trim(name, "_")
+}
+
+// writeFixture redacts and saves a fixture, leaving an existing file alone if
+// only timestamps have changed
+func writeFixture(path string, data []byte) error {
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, data, "", "  "); err == nil {
+		data = indented.Bytes()
+	}
+	redacted := []byte(api.RedactSensitiveData(string(data)))
+
+	if existing, err := os.ReadFile(path); err == nil && onlyTimestampsChanged(existing, redacted) { //nolint:gosec // Fixture path
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create fixtures directory: %w", err)
+	}
+	if err := os.WriteFile(path, redacted, 0o644); err != nil { //nolint:gosec // Fixtures are committed to the repository
+		return fmt.Errorf("failed to write fixture: %w", err)
+	}
+	return nil
+}
+
+// asJSON returns body unchanged if it is valid JSON and as a JSON string otherwise
+func asJSON(body []byte) json.RawMessage {
+	if json.Valid(body) {
+		return body
+	}
+	encoded, _ := json.Marshal(string(body))
+	return encoded
+}
+
+// timestampFields are ignored when deciding whether a fixture has changed
+var timestampFields = map[string]bool{
+	"expires_at":  true,
+	"created":     true,
+	"finished_at": true,
+	"created_at":  true,
+	"started_at":  true,
+	"start_time":  true,
+	"end_time":    true,
+	"time":        true,
+	"timestamp":   true,
+	"finish_time": true,
+	"expiry_time": true,
+}
+
+// onlyTimestampsChanged reports whether two JSON documents differ only in timestamp fields
+func onlyTimestampsChanged(oldData, newData []byte) bool {
+	var oldJSON, newJSON interface{}
+	if json.Unmarshal(oldData, &oldJSON) != nil || json.Unmarshal(newData, &newJSON) != nil {
+		return bytes.Equal(oldData, newData)
+	}
+
+	oldBytes, oldErr := json.Marshal(removeTimestamps(oldJSON))
+	newBytes, newErr := json.Marshal(removeTimestamps(newJSON))
+	return oldErr == nil && newErr == nil && bytes.Equal(oldBytes, newBytes)
+}
+
+// removeTimestamps recursively removes timestamp fields from decoded JSON
+func removeTimestamps(data interface{}) interface{} {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			if !timestampFields[key] {
+				result[key] = removeTimestamps(value)
+			}
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = removeTimestamps(item)
+		}
+		return result
+	default:
+		return data
+	}
+}
@@ -0,0 +1,106 @@
+package apitest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/deviantintegral/terminus-golang/pkg/api"
+)
+
+func TestRecorderRecordAndReplay(t *testing.T) {
+	dir := t.TempDir()
+	server := NewServer(t)
+	for _, name := range []string{"site-a", "site-b", "site-c"} {
+		server.AddSite(name)
+	}
+
+	listSites := func(client *api.Client, userID string) []string {
+		t.Helper()
+		sites, err := api.NewSitesService(client).ListPager(userID).WithPageSize(2).Collect(context.Background())
+		if err != nil {
+			t.Fatalf("failed to list sites: %v", err)
+		}
+		names := make([]string, 0, len(sites))
+		for _, site := range sites {
+			names = append(names, site.Name)
+		}
+		return names
+	}
+
+	recorder := NewRecorder(dir, ModeRecord).WithTransport(server.Server.Client().Transport)
+	recorded := listSites(server.Client(api.WithHTTPClient(recorder.Client())), server.UserID)
+
+	for _, name := range []string{"get_users_ID_memberships_sites_limit-2", "get_users_ID_memberships_sites_limit-2_start-ID"} {
+		data, err := os.ReadFile(filepath.Join(dir, name+".json"))
+		if err != nil {
+			t.Fatalf("expected fixture %s: %v", name, err)
+		}
+		if strings.Contains(string(data), server.UserID) {
+			t.Errorf("expected user ID to be redacted from %s", name)
+		}
+	}
+
+	// Replay without a server, using a different user ID
+	server.Close()
+	replayer := NewRecorder(dir, ModeReplay)
+	client := api.NewClient(api.WithBaseURL("http://example.invalid"), api.WithHTTPClient(replayer.Client()), api.WithRetryPolicy(nil))
+	replayed := listSites(client, "00000000-0000-0000-0000-000000000000")
+
+	if strings.Join(replayed, ",") != strings.Join(recorded, ",") || len(replayed) != 3 {
+		t.Errorf("expected replay %v to match recording %v", replayed, recorded)
+	}
+}
+
+func TestRecorderReplaysErrors(t *testing.T) {
+	dir := t.TempDir()
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`"Site not found"`))
+	}))
+	defer upstream.Close()
+
+	recorder := NewRecorder(dir, ModeRecord).WithTransport(upstream.Client().Transport)
+	client := api.NewClient(api.WithBaseURL(upstream.URL), api.WithHTTPClient(recorder.Client()), api.WithRetryPolicy(nil))
+	if _, err := client.Get(context.Background(), "/site-names/missing"); !api.IsNotFound(err) {
+		t.Fatalf("expected not found while recording, got %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "get_site-names_missing_error.json")); err != nil {
+		t.Fatalf("expected error fixture: %v", err)
+	}
+
+	replayer := NewRecorder(dir, ModeReplay)
+	client = api.NewClient(api.WithBaseURL(upstream.URL), api.WithHTTPClient(replayer.Client()), api.WithRetryPolicy(nil))
+	_, err := client.Get(context.Background(), "/site-names/missing")
+	if !api.IsNotFound(err) {
+		t.Fatalf("expected replayed not found, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "Site not found") {
+		t.Errorf("expected replayed error message, got %v", err)
+	}
+
+	if _, err := client.Get(context.Background(), "/never-recorded"); err == nil {
+		t.Error("expected error for a request without a fixture")
+	}
+}
+
+func TestFixtureName(t *testing.T) {
+	tests := map[string]string{
+		"GET /api/sites/12345678-1234-1234-1234-123456789abc/environments":    "get_sites_ID_environments",
+		"GET /api/sites/redacted-0000-0000-0000-000000000001?site_state=true": "get_sites_ID_site_state-true",
+		"POST /api/authorize/machine-token":                                   "post_authorize_machine-token",
+	}
+
+	for input, want := range tests {
+		method, target, _ := strings.Cut(input, " ")
+		req := httptest.NewRequest(method, "http://example.com"+target, http.NoBody)
+		if got := FixtureName(req); got != want {
+			t.Errorf("FixtureName(%s) = %q, want %q", input, got, want)
+		}
+	}
+}
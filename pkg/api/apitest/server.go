@@ -0,0 +1,595 @@
+// Package apitest provides helpers for testing code built on pkg/api without
+// a live Pantheon account: an in-memory fake of the Pantheon API served over
+// HTTP, in-memory implementations of the pkg/api service interfaces, and an
+// HTTP transport that records real API exchanges as redacted fixtures and
+// replays them later.
+package apitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/deviantintegral/terminus-golang/pkg/api"
+	"github.com/deviantintegral/terminus-golang/pkg/api/models"
+	"github.com/google/uuid"
+)
+
+// DefaultEnvironments are created for every site added to a Server
+var DefaultEnvironments = []string{"dev", "test", "live"}
+
+// Server is an in-memory fake of the Pantheon API. It simulates sites,
+// environments, workflows that progress as they are polled, backups and
+// cursor pagination. A Server is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	// UserID is the ID of the authenticated user
+	UserID string
+	// Session is the session token returned by machine token logins
+	Session string
+	// WorkflowSteps is the number of times a workflow must be polled before it
+	// finishes. The default of 1 finishes workflows on the first poll so that
+	// waits return without sleeping.
+	WorkflowSteps int
+
+	mu        sync.Mutex
+	sites     []*models.Site
+	envs      map[string]map[string]*models.Environment
+	workflows map[string]*fakeWorkflow
+	order     []string
+	backups   map[string][]*models.Backup
+	failures  map[string]string
+	now       func() time.Time
+}
+
+// fakeWorkflow is a workflow and the number of times it has been polled
+type fakeWorkflow struct {
+	workflow *models.Workflow
+	polls    int
+}
+
+// NewServer starts a fake Pantheon API server that is closed when the test ends
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+
+	s := &Server{
+		UserID:        uuid.New().String(),
+		Session:       "fake-session-token",
+		WorkflowSteps: 1,
+		envs:          make(map[string]map[string]*models.Environment),
+		workflows:     make(map[string]*fakeWorkflow),
+		backups:       make(map[string][]*models.Backup),
+		failures:      make(map[string]string),
+		now:           time.Now,
+	}
+	s.Server = httptest.NewServer(s.routes())
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// Client returns an API client for the server, authenticated as UserID.
+// Retries are disabled so that failures surface immediately.
+func (s *Server) Client(options ...api.ClientOption) *api.Client {
+	defaults := []api.ClientOption{
+		api.WithBaseURL(s.URL),
+		api.WithToken(s.Session),
+		api.WithHTTPClient(s.Server.Client()),
+		api.WithRetryPolicy(nil),
+	}
+	return api.NewClient(append(defaults, options...)...)
+}
+
+// AddSite adds a site with the default environments and returns it
+func (s *Server) AddSite(name string) *models.Site {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.addSite(name, "")
+}
+
+// AddEnvironment adds an environment, such as a multidev, to a site
+func (s *Server) AddEnvironment(siteID, envID string) *models.Environment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.addEnvironment(siteID, envID)
+}
+
+// AddBackup adds a finished backup of an environment element (code,
+// database or files) and returns it
+func (s *Server) AddBackup(siteID, envID, element string) *models.Backup {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.addBackup(siteID, envID, element)
+}
+
+// FailWorkflows makes workflows of the given type finish with a failure
+// reporting message
+func (s *Server) FailWorkflows(workflowType, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures[workflowType] = message
+}
+
+// Site returns a copy of the site with the given ID, if it exists
+func (s *Server) Site(siteID string) (*models.Site, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	site := s.findSite(siteID)
+	if site == nil {
+		return nil, false
+	}
+	siteCopy := *site
+	return &siteCopy, true
+}
+
+// Workflows returns copies of every workflow started, oldest first
+func (s *Server) Workflows() []*models.Workflow {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	workflows := make([]*models.Workflow, 0, len(s.order))
+	for _, id := range s.order {
+		workflowCopy := *s.workflows[id].workflow
+		workflows = append(workflows, &workflowCopy)
+	}
+	return workflows
+}
+
+// routes registers the API endpoints served by the fake
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /authorize/machine-token", s.handleLogin)
+	mux.HandleFunc("GET /users/{user}", s.handleUser)
+	mux.HandleFunc("GET /users/{user}/memberships/sites", s.handleUserSites)
+	mux.HandleFunc("POST /users/{user}/workflows", s.handleCreateWorkflow)
+	mux.HandleFunc("GET /users/{user}/workflows/{workflow}", s.handleGetWorkflow)
+	mux.HandleFunc("GET /site-names/{name}", s.handleSiteName)
+	mux.HandleFunc("GET /sites/{site}", s.handleSite)
+	mux.HandleFunc("GET /sites/{site}/environments", s.handleEnvironments)
+	mux.HandleFunc("GET /sites/{site}/environments/{env}", s.handleEnvironment)
+	mux.HandleFunc("GET /sites/{site}/workflows", s.handleListWorkflows)
+	mux.HandleFunc("POST /sites/{site}/workflows", s.handleCreateWorkflow)
+	mux.HandleFunc("GET /sites/{site}/workflows/{workflow}", s.handleGetWorkflow)
+	mux.HandleFunc("GET /sites/{site}/environments/{env}/workflows", s.handleListWorkflows)
+	mux.HandleFunc("POST /sites/{site}/environments/{env}/workflows", s.handleCreateWorkflow)
+	mux.HandleFunc("GET /sites/{site}/environments/{env}/backups/catalog", s.handleBackups)
+	mux.HandleFunc("GET /sites/{site}/environments/{env}/backups/catalog/{backup}", s.handleBackup)
+	mux.HandleFunc("GET /sites/{site}/environments/{env}/backups/catalog/{backup}/downloads/{element}", s.handleBackupURL)
+	mux.HandleFunc("GET /downloads/{backup}", s.handleDownload)
+
+	return mux
+}
+
+func (s *Server) handleLogin(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"session":    s.Session,
+		"user_id":    s.UserID,
+		"expires_at": s.now().Add(24 * time.Hour).Unix(),
+	})
+}
+
+func (s *Server) handleUser(w http.ResponseWriter, r *http.Request) {
+	if r.PathValue("user") != s.UserID {
+		writeError(w, http.StatusNotFound, "User not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, &models.User{ID: s.UserID, Email: "user@example.com"})
+}
+
+func (s *Server) handleUserSites(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	memberships := make([]interface{}, 0, len(s.sites))
+	for _, site := range s.sites {
+		memberships = append(memberships, map[string]interface{}{
+			"id":      site.ID,
+			"user_id": s.UserID,
+			"role":    "owner",
+			"site":    site,
+		})
+	}
+	writeJSON(w, http.StatusOK, paginate(r, memberships, func(i int) string { return s.sites[i].ID }))
+}
+
+func (s *Server) handleSiteName(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, site := range s.sites {
+		if site.Name == r.PathValue("name") {
+			writeJSON(w, http.StatusOK, map[string]string{"id": site.ID, "name": site.Name})
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "Site not found")
+}
+
+func (s *Server) handleSite(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	site := s.findSite(r.PathValue("site"))
+	if site == nil {
+		writeError(w, http.StatusNotFound, "Site not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, site)
+}
+
+func (s *Server) handleEnvironments(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	envs, ok := s.envs[r.PathValue("site")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Site not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, envs)
+}
+
+func (s *Server) handleEnvironment(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	env, ok := s.envs[r.PathValue("site")][r.PathValue("env")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Environment not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, env)
+}
+
+func (s *Server) handleListWorkflows(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	siteID, envID := r.PathValue("site"), r.PathValue("env")
+	if s.findSite(siteID) == nil {
+		writeError(w, http.StatusNotFound, "Site not found")
+		return
+	}
+
+	// Newest first, as returned by the Pantheon API
+	workflows := make([]*models.Workflow, 0)
+	for i := len(s.order) - 1; i >= 0; i-- {
+		workflow := s.workflows[s.order[i]].workflow
+		if workflow.SiteID == siteID && (envID == "" || workflow.EnvironmentID == envID) {
+			workflows = append(workflows, workflow)
+		}
+	}
+	writeJSON(w, http.StatusOK, workflows)
+}
+
+func (s *Server) handleCreateWorkflow(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Type   string                 `json:"type"`
+		Params map[string]interface{} `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Type == "" {
+		writeError(w, http.StatusBadRequest, "Invalid workflow request")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	siteID, envID := r.PathValue("site"), r.PathValue("env")
+	if siteID != "" && s.findSite(siteID) == nil {
+		writeError(w, http.StatusNotFound, "Site not found")
+		return
+	}
+	if envID != "" && s.envs[siteID][envID] == nil {
+		writeError(w, http.StatusNotFound, "Environment not found")
+		return
+	}
+
+	now := float64(s.now().Unix())
+	workflow := &models.Workflow{
+		ID:               uuid.New().String(),
+		Type:             req.Type,
+		Description:      workflowDescription(req.Type),
+		SiteID:           siteID,
+		EnvironmentID:    envID,
+		UserID:           s.UserID,
+		CreatedAt:        now,
+		StartedAt:        now,
+		CurrentOperation: "Queued",
+		Params:           req.Params,
+		Active:           true,
+		HasActiveOps:     true,
+	}
+	s.workflows[workflow.ID] = &fakeWorkflow{workflow: workflow}
+	s.order = append(s.order, workflow.ID)
+
+	writeJSON(w, http.StatusOK, workflow)
+}
+
+func (s *Server) handleGetWorkflow(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fake, ok := s.workflows[r.PathValue("workflow")]
+	if !ok || (r.PathValue("site") != "" && fake.workflow.SiteID != r.PathValue("site")) {
+		writeError(w, http.StatusNotFound, "Workflow not found")
+		return
+	}
+
+	s.advance(fake)
+	writeJSON(w, http.StatusOK, fake.workflow)
+}
+
+func (s *Server) handleBackups(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	siteID, envID := r.PathValue("site"), r.PathValue("env")
+	if s.envs[siteID][envID] == nil {
+		writeError(w, http.StatusNotFound, "Environment not found")
+		return
+	}
+
+	backups := s.backups[backupKey(siteID, envID)]
+	if backups == nil {
+		backups = []*models.Backup{}
+	}
+	writeJSON(w, http.StatusOK, backups)
+}
+
+func (s *Server) handleBackup(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	backup := s.findBackup(r.PathValue("site"), r.PathValue("env"), r.PathValue("backup"))
+	if backup == nil {
+		writeError(w, http.StatusNotFound, "Backup not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, backup)
+}
+
+func (s *Server) handleBackupURL(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	backup := s.findBackup(r.PathValue("site"), r.PathValue("env"), r.PathValue("backup"))
+	if backup == nil {
+		writeError(w, http.StatusNotFound, "Backup not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"url": s.URL + "/downloads/" + backup.ID})
+}
+
+func (s *Server) handleDownload(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/octet-stream")
+	_, _ = fmt.Fprintf(w, "backup %s\n", r.PathValue("backup"))
+}
+
+// advance records a poll of a workflow, finishing it after WorkflowSteps polls
+func (s *Server) advance(fake *fakeWorkflow) {
+	workflow := fake.workflow
+	if workflow.IsFinished() {
+		return
+	}
+
+	fake.polls++
+	workflow.Step = fake.polls
+	workflow.CurrentOperation = "Running"
+	if fake.polls < s.WorkflowSteps {
+		return
+	}
+
+	now := float64(s.now().Unix())
+	workflow.FinishedAt = now
+	workflow.TotalTime = now - workflow.StartedAt
+	workflow.Active = false
+	workflow.HasActiveOps = false
+	workflow.CurrentOperation = ""
+	workflow.FinalTask = &models.Task{ID: uuid.New().String(), Type: workflow.Type, SiteID: workflow.SiteID}
+
+	if message, failed := s.failures[workflow.Type]; failed {
+		workflow.Result = "failed"
+		workflow.FinalTask.Result = "failed"
+		workflow.FinalTask.Messages = []interface{}{map[string]interface{}{"level": "ERROR", "message": message}}
+		return
+	}
+
+	workflow.Result = "succeeded"
+	workflow.FinalTask.Result = "succeeded"
+	s.apply(workflow)
+}
+
+// apply performs the side effects of a workflow that succeeded
+func (s *Server) apply(workflow *models.Workflow) {
+	switch workflow.Type {
+	case "create_site":
+		name, _ := workflow.Params["site_name"].(string)
+		label, _ := workflow.Params["label"].(string)
+		site := s.addSite(name, label)
+		workflow.FinalTask.SiteID = site.ID
+	case "delete_site":
+		for i, site := range s.sites {
+			if site.ID == workflow.SiteID {
+				s.sites = append(s.sites[:i], s.sites[i+1:]...)
+				break
+			}
+		}
+		delete(s.envs, workflow.SiteID)
+	case "do_export":
+		for _, element := range []string{"code", "database", "files"} {
+			if include, _ := workflow.Params[element].(bool); include {
+				s.addBackup(workflow.SiteID, workflow.EnvironmentID, element)
+			}
+		}
+	case "create_cloud_development_environment":
+		if envID, ok := workflow.Params["environment_id"].(string); ok {
+			s.addEnvironment(workflow.SiteID, envID)
+		}
+	case "delete_cloud_development_environment":
+		if envID, ok := workflow.Params["environment_id"].(string); ok {
+			delete(s.envs[workflow.SiteID], envID)
+		}
+	}
+}
+
+func (s *Server) addSite(name, label string) *models.Site {
+	if label == "" {
+		label = name
+	}
+
+	site := &models.Site{
+		ID:            uuid.New().String(),
+		Name:          name,
+		Label:         label,
+		Created:       s.now().Unix(),
+		Framework:     "drupal8",
+		Service:       "free",
+		PlanName:      "Sandbox",
+		PHP:           "83",
+		Holder:        "user",
+		HolderID:      s.UserID,
+		Owner:         s.UserID,
+		PreferredZone: "us-central1",
+	}
+	s.sites = append(s.sites, site)
+	s.envs[site.ID] = make(map[string]*models.Environment)
+	for _, envID := range DefaultEnvironments {
+		s.addEnvironment(site.ID, envID)
+	}
+
+	return site
+}
+
+func (s *Server) addEnvironment(siteID, envID string) *models.Environment {
+	site := s.findSite(siteID)
+	name := siteID
+	if site != nil {
+		name = site.Name
+	}
+
+	env := &models.Environment{
+		ID:             envID,
+		SiteID:         siteID,
+		Domain:         fmt.Sprintf("%s-%s.pantheonsite.io", envID, name),
+		Initialized:    true,
+		ConnectionMode: "git",
+		PHP:            "83",
+		TargetRef:      "refs/heads/master",
+	}
+	if s.envs[siteID] == nil {
+		s.envs[siteID] = make(map[string]*models.Environment)
+	}
+	s.envs[siteID][envID] = env
+
+	return env
+}
+
+func (s *Server) addBackup(siteID, envID, element string) *models.Backup {
+	now := s.now().Unix()
+	folder := fmt.Sprintf("%d_backup", now)
+	backup := &models.Backup{
+		ID:            folder + "_" + element,
+		SiteID:        siteID,
+		EnvironmentID: envID,
+		ArchiveType:   element,
+		Timestamp:     now,
+		FinishTime:    now,
+		Size:          1024,
+		Folder:        folder,
+		TTL:           365 * 86400,
+		ExpiryTime:    now + 365*86400,
+	}
+
+	key := backupKey(siteID, envID)
+	s.backups[key] = append(s.backups[key], backup)
+
+	return backup
+}
+
+func (s *Server) findSite(siteID string) *models.Site {
+	for _, site := range s.sites {
+		if site.ID == siteID {
+			return site
+		}
+	}
+	return nil
+}
+
+func (s *Server) findBackup(siteID, envID, backupID string) *models.Backup {
+	for _, backup := range s.backups[backupKey(siteID, envID)] {
+		if backup.ID == backupID {
+			return backup
+		}
+	}
+	return nil
+}
+
+func backupKey(siteID, envID string) string {
+	return siteID + "/" + envID
+}
+
+// workflowDescription returns a human readable description for a workflow type
+func workflowDescription(workflowType string) string {
+	descriptions := map[string]string{
+		"create_site":    "Create site",
+		"delete_site":    "Delete site",
+		"deploy":         "Deploy code",
+		"do_export":      "Create backup",
+		"clear_cache":    "Clear caches",
+		"restore":        "Restore backup",
+		"deploy_product": "Deploy product",
+	}
+	if description, ok := descriptions[workflowType]; ok {
+		return description
+	}
+	return workflowType
+}
+
+// paginate applies the limit and start cursor parameters used by the Pantheon
+// API, where start is the ID of the last item of the previous page
+func paginate(r *http.Request, items []interface{}, id func(int) string) []interface{} {
+	start := 0
+	if cursor := r.URL.Query().Get("start"); cursor != "" {
+		// An unknown cursor yields an empty page
+		start = len(items)
+		for i := range items {
+			if id(i) == cursor {
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	end := len(items)
+	if limit, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && limit > 0 && start+limit < end {
+		end = start + limit
+	}
+
+	return items[start:end]
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+// writeError writes an error response in the bare string form used by the
+// Pantheon API
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, message)
+}
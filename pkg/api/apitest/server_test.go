@@ -0,0 +1,177 @@
+package apitest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/deviantintegral/terminus-golang/pkg/api"
+	"github.com/deviantintegral/terminus-golang/pkg/api/models"
+)
+
+func TestServerSitesAndEnvironments(t *testing.T) {
+	server := NewServer(t)
+	site := server.AddSite("my-site")
+	server.AddEnvironment(site.ID, "feature")
+
+	client := server.Client()
+	ctx := context.Background()
+
+	got, err := api.NewSitesService(client).Get(ctx, "my-site")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.ID != site.ID {
+		t.Errorf("expected site %s, got %s", site.ID, got.ID)
+	}
+
+	envs, err := api.NewEnvironmentsService(client).List(ctx, "my-site")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ids := make([]string, 0, len(envs))
+	for _, env := range envs {
+		ids = append(ids, env.ID)
+	}
+	sort.Strings(ids)
+	if fmt.Sprint(ids) != "[dev feature live test]" {
+		t.Errorf("unexpected environments: %v", ids)
+	}
+
+	_, err = api.NewSitesService(client).Get(ctx, "missing-site")
+	if !api.IsNotFound(err) {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+func TestServerPagination(t *testing.T) {
+	server := NewServer(t)
+	for i := 0; i < 7; i++ {
+		server.AddSite(fmt.Sprintf("site-%d", i))
+	}
+
+	pager := api.NewSitesService(server.Client()).ListPager(server.UserID).WithPageSize(3)
+	sites, err := pager.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sites) != 7 {
+		t.Fatalf("expected 7 sites across pages, got %d", len(sites))
+	}
+	for i, site := range sites {
+		if want := fmt.Sprintf("site-%d", i); site.Name != want {
+			t.Errorf("site %d: expected %s, got %s", i, want, site.Name)
+		}
+	}
+}
+
+func TestServerWorkflowProgress(t *testing.T) {
+	server := NewServer(t)
+	server.WorkflowSteps = 3
+	site := server.AddSite("my-site")
+
+	client := server.Client()
+	workflow, err := api.NewEnvironmentsService(client).ClearCache(context.Background(), site.ID, "dev")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if workflow.IsFinished() {
+		t.Fatal("expected a new workflow to be running")
+	}
+
+	var polls int
+	finished, err := api.NewWorkflowsService(client).Wait(context.Background(), site.ID, workflow.ID, &api.WaitOptions{
+		PollInterval: time.Millisecond,
+		OnProgress:   func(_ *models.Workflow) { polls++ },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !finished.IsSuccessful() || polls != 3 {
+		t.Errorf("expected success after 3 polls, got result %q after %d", finished.Result, polls)
+	}
+}
+
+func TestServerFailedWorkflow(t *testing.T) {
+	server := NewServer(t)
+	server.FailWorkflows("deploy", "Merge conflict")
+	site := server.AddSite("my-site")
+
+	client := server.Client()
+	workflow, err := api.NewEnvironmentsService(client).Deploy(context.Background(), site.ID, "test", &api.DeployRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	finished, err := api.NewWorkflowsService(client).Wait(context.Background(), site.ID, workflow.ID, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !finished.IsFailed() || finished.GetMessage() != "Merge conflict" {
+		t.Errorf("expected failure with message, got %q: %q", finished.Result, finished.GetMessage())
+	}
+	if !errors.Is(api.NewWorkflowError(finished), api.ErrWorkflowFailed) {
+		t.Error("expected failed workflow error")
+	}
+}
+
+func TestServerBackups(t *testing.T) {
+	server := NewServer(t)
+	site := server.AddSite("my-site")
+
+	client := server.Client()
+	ctx := context.Background()
+	backups := api.NewBackupsService(client)
+
+	workflow, err := backups.CreateElement(ctx, site.ID, "live", "database")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := api.NewWorkflowsService(client).Wait(ctx, site.ID, workflow.ID, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	list, err := backups.List(ctx, site.ID, "live")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(list) != 1 || list[0].ArchiveType != "database" {
+		t.Fatalf("expected one database backup, got %+v", list)
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "database.sql.gz")
+	if err := backups.Download(ctx, site.ID, "live", list[0].ID, "database", outputPath); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(outputPath); err != nil {
+		t.Errorf("expected downloaded backup: %v", err)
+	}
+}
+
+func TestServerCreateAndDeleteSite(t *testing.T) {
+	server := NewServer(t)
+	client := server.Client()
+	ctx := context.Background()
+	sites := api.NewSitesService(client)
+
+	upstreamID := "bde48795-b16d-443f-af01-8b1790caa1af"
+	site, err := sites.Create(ctx, server.UserID, &api.CreateSiteRequest{SiteName: "new-site", UpstreamID: upstreamID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if site.Name != "new-site" {
+		t.Errorf("expected new-site, got %s", site.Name)
+	}
+
+	if err := sites.Delete(ctx, "new-site"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := server.Site(site.ID); ok {
+		t.Error("expected site to be deleted")
+	}
+}
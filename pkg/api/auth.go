@@ -49,7 +49,7 @@ func (s *AuthService) Login(ctx context.Context, machineToken string) (*SessionR
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("login failed with status %d", resp.StatusCode)
+		return nil, fmt.Errorf("login failed: %w", NewError(resp))
 	}
 
 	var session SessionResponse
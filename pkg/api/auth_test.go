@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -112,11 +113,14 @@ func TestAuthService_Login_InvalidStatus(t *testing.T) {
 		t.Fatal("expected error for invalid credentials")
 	}
 
-	// The error should indicate login failed with status code
-	expectedMsg := "login failed with status 401"
+	// The error should indicate login failed and carry the parsed API error
+	expectedMsg := "login failed: API error 401: invalid token"
 	if err.Error() != expectedMsg {
 		t.Errorf("expected error message %q, got %q", expectedMsg, err.Error())
 	}
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Error("expected error to match ErrUnauthorized")
+	}
 }
 
 func TestAuthService_Login_MalformedResponse(t *testing.T) {
@@ -302,11 +306,9 @@ func TestAuthService_ValidateSession_Unauthorized(t *testing.T) {
 	authService := NewAuthService(client)
 
 	// Test validate session with invalid token
-	// Note: The current implementation returns an error for 401 instead of (false, nil)
-	// because the error is wrapped and doesn't match the exact string "API error 401"
 	valid, err := authService.ValidateSession(context.Background(), "user123")
-	if err == nil {
-		t.Fatal("expected error for unauthorized response")
+	if err != nil {
+		t.Fatalf("expected no error for unauthorized response, got %v", err)
 	}
 
 	if valid {
@@ -331,11 +333,9 @@ func TestAuthService_ValidateSession_NotFound(t *testing.T) {
 	authService := NewAuthService(client)
 
 	// Test validate session with user not found
-	// Note: The current implementation returns an error for 404 instead of (false, nil)
-	// because IsNotFound checks for unwrapped errors, not wrapped ones
 	valid, err := authService.ValidateSession(context.Background(), "nonexistent")
-	if err == nil {
-		t.Fatal("expected error for not found response")
+	if err != nil {
+		t.Fatalf("expected no error for not found response, got %v", err)
 	}
 
 	if valid {
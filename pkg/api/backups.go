@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"net/http"
+	"math/rand/v2"
 	"os"
+	"path/filepath"
+	"strconv"
 
 	"github.com/deviantintegral/terminus-golang/pkg/api/models"
 )
@@ -146,36 +148,55 @@ func (s *BackupsService) Download(ctx context.Context, siteID, envID, backupID,
 	}
 
 	// Download file
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, downloadURL, http.NoBody)
-	if err != nil {
-		return fmt.Errorf("failed to create download request: %w", err)
-	}
-
-	resp, err := s.client.httpClient.Do(req)
+	resp, err := s.client.DownloadURL(ctx, downloadURL)
 	if err != nil {
 		return fmt.Errorf("failed to download backup: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("download failed with status %d", resp.StatusCode)
-	}
-
-	// Create output file
-	out, err := os.Create(outputPath) //nolint:gosec // User-specified output path
+	// Write to a temporary file next to the destination so that an
+	// interrupted download never leaves a truncated backup behind
+	out, err := createPartialFile(outputPath)
 	if err != nil {
 		return fmt.Errorf("failed to create output file: %w", err)
 	}
-	defer func() { _ = out.Close() }()
+	partialPath := out.Name()
+	defer func() {
+		_ = out.Close()
+		_ = os.Remove(partialPath)
+	}()
 
 	// Copy data
 	if _, err := io.Copy(out, resp.Body); err != nil {
 		return fmt.Errorf("failed to write backup data: %w", err)
 	}
 
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to write backup data: %w", err)
+	}
+
+	if err := os.Rename(partialPath, outputPath); err != nil { //nolint:gosec // User-specified output path
+		return fmt.Errorf("failed to save backup: %w", err)
+	}
+
 	return nil
 }
 
+// createPartialFile creates a uniquely named file next to path to download
+// into. Unlike os.CreateTemp, the file gets the permissions that creating
+// path itself would, following the umask.
+func createPartialFile(path string) (*os.File, error) {
+	prefix := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".")
+	for i := 0; i < 10000; i++ {
+		name := prefix + strconv.FormatUint(rand.Uint64(), 36) + ".part"
+		f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o666) //nolint:gosec // Backups get the same permissions as other new files
+		if !os.IsExist(err) {
+			return f, err
+		}
+	}
+	return nil, fmt.Errorf("failed to create a unique file next to %s", path)
+}
+
 // Restore restores a backup
 func (s *BackupsService) Restore(ctx context.Context, siteID, envID, backupID string) (*models.Workflow, error) {
 	path := fmt.Sprintf("/sites/%s/environments/%s/workflows", siteID, envID)
@@ -0,0 +1,155 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newDownloadServer(t *testing.T, file http.HandlerFunc) *httptest.Server {
+	t.Helper()
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/downloads/") {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]string{"url": server.URL + "/file"})
+			return
+		}
+		file(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestBackupsService_Download(t *testing.T) {
+	server := newDownloadServer(t, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("backup data"))
+	})
+
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(&http.Client{Timeout: 5 * time.Second}))
+	outputPath := filepath.Join(t.TempDir(), "backup.tar.gz")
+
+	if err := NewBackupsService(client).Download(context.Background(), "site", "dev", "backup", "files", outputPath); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("failed to read download: %v", err)
+	}
+	if string(data) != "backup data" {
+		t.Errorf("expected downloaded content, got %q", data)
+	}
+}
+
+func TestBackupsService_Download_CancelledRemovesPartialFile(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	server := newDownloadServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("partial"))
+		w.(http.Flusher).Flush()
+
+		// Simulate Ctrl-C part way through the transfer
+		cancel()
+		<-r.Context().Done()
+	})
+
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(&http.Client{Timeout: 5 * time.Second}))
+	dir := t.TempDir()
+	outputPath := filepath.Join(dir, "backup.tar.gz")
+
+	if err := NewBackupsService(client).Download(ctx, "site", "dev", "backup", "files", outputPath); err == nil {
+		t.Fatal("expected error for cancelled download")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read output directory: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected no files after cancelled download, found %d (first: %s)", len(entries), entries[0].Name())
+	}
+}
+
+func TestBackupsService_Download_ThroughClient(t *testing.T) {
+	var authorization string
+	server := newDownloadServer(t, func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte("backup data"))
+	})
+
+	var intercepted []string
+	record := func(req *http.Request, next Handler) (*http.Response, error) {
+		intercepted = append(intercepted, req.URL.Path)
+		return next(req)
+	}
+	client := NewClient(WithBaseURL(server.URL), WithToken("secret"), WithInterceptors(record))
+	dir := t.TempDir()
+	outputPath := filepath.Join(dir, "backup.tar.gz")
+
+	if err := NewBackupsService(client).Download(context.Background(), "site", "dev", "backup", "files", outputPath); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(intercepted) != 2 || intercepted[1] != "/file" {
+		t.Errorf("expected the download to go through the interceptors, got %v", intercepted)
+	}
+	if authorization != "" {
+		t.Errorf("expected no API token to be sent with the download, got %q", authorization)
+	}
+
+	// The backup gets the same permissions as any other new file
+	reference, err := os.Create(filepath.Join(dir, "reference"))
+	if err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	_ = reference.Close()
+	want, err := os.Stat(reference.Name())
+	if err != nil {
+		t.Fatalf("failed to stat file: %v", err)
+	}
+	got, err := os.Stat(outputPath)
+	if err != nil {
+		t.Fatalf("failed to stat download: %v", err)
+	}
+	if got.Mode().Perm() != want.Mode().Perm() {
+		t.Errorf("expected mode %v, got %v", want.Mode().Perm(), got.Mode().Perm())
+	}
+}
+
+func TestBackupsService_Download_LargeFile(t *testing.T) {
+	server := newDownloadServer(t, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("backup "))
+		w.(http.Flusher).Flush()
+		// Take longer to send than the request timeout allows
+		time.Sleep(200 * time.Millisecond)
+		_, _ = w.Write([]byte("data"))
+	})
+
+	var log bytes.Buffer
+	client := NewClient(WithBaseURL(server.URL), WithTimeout(50*time.Millisecond), WithLogger(NewLoggerWithWriter(VerbosityTrace, &log)))
+	outputPath := filepath.Join(t.TempDir(), "backup.tar.gz")
+
+	if err := NewBackupsService(client).Download(context.Background(), "site", "dev", "backup", "files", outputPath); err != nil {
+		t.Fatalf("expected the timeout not to cover the transfer, got %v", err)
+	}
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("failed to read download: %v", err)
+	}
+	if string(data) != "backup data" {
+		t.Errorf("expected downloaded content, got %q", data)
+	}
+	if strings.Contains(log.String(), "backup data") {
+		t.Errorf("expected the backup not to be logged, got %s", log.String())
+	}
+}
@@ -0,0 +1,259 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// CacheRule enables caching of GET responses for paths matching Pattern
+type CacheRule struct {
+	Pattern *regexp.Regexp
+	TTL     time.Duration
+}
+
+// DefaultCacheRules lists the read-only endpoints that a ResponseCache
+// stores when it isn't given other rules.
+// Patterns are matched against the request URL path, which includes any base
+// URL prefix such as /api.
+var DefaultCacheRules = []CacheRule{
+	{Pattern: regexp.MustCompile(`/upstreams$`), TTL: time.Hour},
+	{Pattern: regexp.MustCompile(`/users/[^/]+/memberships/(sites|organizations)$`), TTL: 5 * time.Minute},
+	{Pattern: regexp.MustCompile(`/organizations/[^/]+/memberships/(sites|users)$`), TTL: 5 * time.Minute},
+}
+
+// resourcePattern matches the top-level resource a request path refers to
+var resourcePattern = regexp.MustCompile(`/(sites|organizations|users)/[^/]+`)
+
+// ResponseCache stores GET responses on disk so repeated invocations can
+// avoid refetching data that rarely changes
+type ResponseCache struct {
+	dir   string
+	rules []CacheRule
+	now   func() time.Time
+}
+
+// cacheEntry is the on-disk representation of a cached response
+type cacheEntry struct {
+	URL        string      `json:"url"`
+	Path       string      `json:"path"`
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+	ETag       string      `json:"etag,omitempty"`
+	Expires    time.Time   `json:"expires"`
+}
+
+// NewResponseCache creates a cache that stores responses in dir.
+// If no rules are given, DefaultCacheRules are used.
+func NewResponseCache(dir string, rules ...CacheRule) *ResponseCache {
+	if len(rules) == 0 {
+		rules = DefaultCacheRules
+	}
+	return &ResponseCache{
+		dir:   dir,
+		rules: rules,
+		now:   time.Now,
+	}
+}
+
+// WithCache enables the on-disk response cache
+func WithCache(cache *ResponseCache) ClientOption {
+	return func(c *Client) {
+		c.cache = cache
+	}
+}
+
+// Dir returns the directory the cache is stored in
+func (rc *ResponseCache) Dir() string {
+	return rc.dir
+}
+
+// Clear removes every cached response
+func (rc *ResponseCache) Clear() error {
+	if err := os.RemoveAll(rc.dir); err != nil {
+		return fmt.Errorf("failed to clear cache: %w", err)
+	}
+	return nil
+}
+
+// Do serves cacheable GET requests from the cache, calling next for misses and
+// revalidating expired entries with If-None-Match when an ETag is known.
+// Successful mutating requests invalidate cached responses for related paths.
+func (rc *ResponseCache) Do(req *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		resp, err := next(req)
+		// Authentication requests don't change any cached data
+		if err == nil && resp.StatusCode < http.StatusBadRequest && !strings.Contains(req.URL.Path, "/authorize/") {
+			rc.invalidate(req.URL.Path)
+		}
+		return resp, err
+	}
+
+	ttl, ok := rc.ttlFor(req.URL.Path)
+	if !ok {
+		return next(req)
+	}
+
+	key := cacheKey(req)
+	entry := rc.load(key)
+	now := rc.now()
+	if entry != nil && now.Before(entry.Expires) {
+		return entry.response(req), nil
+	}
+
+	if entry != nil && entry.ETag != "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", entry.ETag)
+	}
+
+	resp, err := next(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		entry.Expires = now.Add(ttl)
+		rc.save(key, entry)
+		return entry.response(req), nil
+	}
+
+	if resp.StatusCode != http.StatusOK || strings.Contains(resp.Header.Get("Cache-Control"), "no-store") {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	rc.save(key, &cacheEntry{
+		URL:        req.URL.String(),
+		Path:       req.URL.Path,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+		ETag:       resp.Header.Get("ETag"),
+		Expires:    now.Add(ttl),
+	})
+
+	return resp, nil
+}
+
+// ttlFor returns the TTL of the first rule matching path
+func (rc *ResponseCache) ttlFor(path string) (time.Duration, bool) {
+	for _, rule := range rc.rules {
+		if rule.Pattern.MatchString(path) {
+			return rule.TTL, true
+		}
+	}
+	return 0, false
+}
+
+// invalidate removes cached responses that may be stale after a mutation of
+// path: anything under the same site, organization or user, and every
+// membership listing
+func (rc *ResponseCache) invalidate(path string) {
+	resource := resourcePattern.FindString(path)
+
+	files, err := os.ReadDir(rc.dir)
+	if err != nil {
+		return
+	}
+
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		key := strings.TrimSuffix(file.Name(), ".json")
+		entry := rc.load(key)
+		if entry == nil {
+			continue
+		}
+
+		stale := strings.Contains(entry.Path, "/memberships/") ||
+			(resource != "" && strings.Contains(entry.Path, resource))
+		if stale {
+			_ = os.Remove(rc.entryPath(key))
+		}
+	}
+}
+
+// load reads a cache entry, returning nil if it is missing or unreadable
+func (rc *ResponseCache) load(key string) *cacheEntry {
+	data, err := os.ReadFile(rc.entryPath(key))
+	if err != nil {
+		return nil
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil
+	}
+	return &entry
+}
+
+// save writes a cache entry. Failures are ignored since the cache is only an optimization.
+func (rc *ResponseCache) save(key string, entry *cacheEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(rc.dir, 0o700); err != nil {
+		return
+	}
+
+	// Write to a temporary file first so concurrent readers never see a partial entry
+	tmp, err := os.CreateTemp(rc.dir, key+".*.tmp")
+	if err != nil {
+		return
+	}
+	_, writeErr := tmp.Write(data)
+	closeErr := tmp.Close()
+	if writeErr != nil || closeErr != nil {
+		_ = os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), rc.entryPath(key)); err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+}
+
+func (rc *ResponseCache) entryPath(key string) string {
+	return filepath.Join(rc.dir, key+".json")
+}
+
+// cacheKey identifies a response by URL and credentials, so different users
+// never share cached data
+func cacheKey(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.Header.Get("Authorization") + "\n" + req.URL.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// response builds an HTTP response from a cache entry
+func (e *cacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
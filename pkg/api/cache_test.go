@@ -0,0 +1,184 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newCacheTestServer serves JSON for any GET, tagging responses with an ETag
+// and answering matching If-None-Match headers with 304 Not Modified
+func newCacheTestServer(hits, notModified *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{}`))
+			return
+		}
+
+		etag := `"v1"`
+		if r.Header.Get("If-None-Match") == etag {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", etag)
+		_, _ = fmt.Fprintf(w, `{"path": %q}`, r.URL.Path)
+	}))
+}
+
+func getBody(t *testing.T, client *Client, path string) string {
+	t.Helper()
+	resp, err := client.Get(context.Background(), path)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", path, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+	return string(body)
+}
+
+func TestResponseCacheServesFreshEntries(t *testing.T) {
+	var hits, notModified atomic.Int32
+	server := newCacheTestServer(&hits, &notModified)
+	defer server.Close()
+
+	cache := NewResponseCache(t.TempDir())
+	client := NewClient(WithBaseURL(server.URL), WithToken("token"), WithCache(cache))
+
+	first := getBody(t, client, "/organizations/org1/memberships/sites")
+	second := getBody(t, client, "/organizations/org1/memberships/sites")
+
+	if first != second {
+		t.Errorf("cached body differs: %q vs %q", first, second)
+	}
+	if hits.Load() != 1 {
+		t.Errorf("expected 1 server hit, got %d", hits.Load())
+	}
+}
+
+func TestResponseCacheSkipsUnlistedPaths(t *testing.T) {
+	var hits, notModified atomic.Int32
+	server := newCacheTestServer(&hits, &notModified)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithCache(NewResponseCache(t.TempDir())))
+
+	getBody(t, client, "/sites/abc/workflows")
+	getBody(t, client, "/sites/abc/workflows")
+
+	if hits.Load() != 2 {
+		t.Errorf("expected uncached path to hit the server twice, got %d", hits.Load())
+	}
+}
+
+func TestResponseCacheRevalidatesWithETag(t *testing.T) {
+	var hits, notModified atomic.Int32
+	server := newCacheTestServer(&hits, &notModified)
+	defer server.Close()
+
+	cache := NewResponseCache(t.TempDir())
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	client := NewClient(WithBaseURL(server.URL), WithCache(cache))
+
+	first := getBody(t, client, "/upstreams")
+
+	// Move past the TTL so the entry must be revalidated
+	now = now.Add(2 * time.Hour)
+	second := getBody(t, client, "/upstreams")
+
+	if first != second {
+		t.Errorf("revalidated body differs: %q vs %q", first, second)
+	}
+	if notModified.Load() != 1 {
+		t.Errorf("expected a 304 revalidation, got %d", notModified.Load())
+	}
+
+	// The revalidated entry is fresh again
+	getBody(t, client, "/upstreams")
+	if hits.Load() != 2 {
+		t.Errorf("expected 2 server hits, got %d", hits.Load())
+	}
+}
+
+func TestResponseCacheSeparatesTokens(t *testing.T) {
+	var hits, notModified atomic.Int32
+	server := newCacheTestServer(&hits, &notModified)
+	defer server.Close()
+
+	cache := NewResponseCache(t.TempDir())
+	client := NewClient(WithBaseURL(server.URL), WithToken("alice"), WithCache(cache))
+
+	getBody(t, client, "/users/u1/memberships/sites")
+	client.SetToken("bob")
+	getBody(t, client, "/users/u1/memberships/sites")
+
+	if hits.Load() != 2 {
+		t.Errorf("expected each token to be cached separately, got %d hits", hits.Load())
+	}
+}
+
+func TestResponseCacheInvalidatesAfterMutation(t *testing.T) {
+	var hits, notModified atomic.Int32
+	server := newCacheTestServer(&hits, &notModified)
+	defer server.Close()
+
+	cache := NewResponseCache(t.TempDir())
+	client := NewClient(WithBaseURL(server.URL), WithCache(cache))
+
+	getBody(t, client, "/users/u1/memberships/sites")
+	getBody(t, client, "/organizations/org1/memberships/sites")
+	getBody(t, client, "/organizations/org2/memberships/users")
+	getBody(t, client, "/upstreams")
+	hits.Store(0)
+
+	resp, err := client.Post(context.Background(), "/sites/abc/environments/dev/workflows", map[string]string{"type": "clear_cache"})
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	// Membership listings are refetched, unrelated entries remain cached
+	getBody(t, client, "/users/u1/memberships/sites")
+	getBody(t, client, "/organizations/org1/memberships/sites")
+	getBody(t, client, "/upstreams")
+
+	// 1 POST + 2 refetched membership listings
+	if hits.Load() != 3 {
+		t.Errorf("expected 3 server hits after invalidation, got %d", hits.Load())
+	}
+}
+
+func TestResponseCacheClear(t *testing.T) {
+	var hits, notModified atomic.Int32
+	server := newCacheTestServer(&hits, &notModified)
+	defer server.Close()
+
+	cache := NewResponseCache(t.TempDir())
+	client := NewClient(WithBaseURL(server.URL), WithCache(cache))
+
+	getBody(t, client, "/upstreams")
+	if err := cache.Clear(); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if _, err := os.Stat(cache.Dir()); !os.IsNotExist(err) {
+		t.Errorf("expected cache directory to be removed, got %v", err)
+	}
+
+	getBody(t, client, "/upstreams")
+	if hits.Load() != 2 {
+		t.Errorf("expected cleared entry to be refetched, got %d hits", hits.Load())
+	}
+}
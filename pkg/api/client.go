@@ -6,7 +6,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"math"
 	"net/http"
 	"runtime"
 	"time"
@@ -22,10 +21,10 @@ const (
 	// DefaultTimeout is the default timeout for HTTP requests
 	DefaultTimeout = 86400 * time.Second
 
-	// MaxRetries is the maximum number of retry attempts
+	// MaxRetries is the maximum number of retry attempts used by the default retry policy
 	MaxRetries = 5
 
-	// InitialBackoff is the initial backoff duration for retries
+	// InitialBackoff is the initial backoff duration used by the default retry policy
 	InitialBackoff = 1 * time.Second
 )
 
@@ -37,6 +36,21 @@ type Client struct {
 	token          string
 	logger         Logger
 	tokenRefresher TokenRefresher
+	retryPolicy    RetryPolicy
+	rateLimiter    *RateLimiter
+	inFlight       chan struct{}
+	cache          *ResponseCache
+	resolver       *Resolver
+	nameCacheFile  string
+	timeout        time.Duration
+	waitTimeout    time.Duration
+	interceptors   []Interceptor
+	readOnly       bool
+	dryRun         bool
+	dryRunPlan     *DryRunPlan
+	spanExporter   SpanExporter
+	onWorkflowDone WorkflowObserver
+	stats          clientStats
 }
 
 // Logger is an interface for logging
@@ -70,12 +84,18 @@ func NewClient(options ...ClientOption) *Client {
 		},
 		userAgent: fmt.Sprintf("Terminus-Go/%s (go_version=%s; os=%s; arch=%s)",
 			version.String(), runtime.Version(), runtime.GOOS, runtime.GOARCH),
+		retryPolicy: NewDefaultRetryPolicy(),
 	}
 
 	for _, opt := range options {
 		opt(c)
 	}
 
+	c.resolver = NewResolver(c, c.nameCacheFile)
+	if c.dryRun {
+		c.dryRunPlan = NewDryRunPlan()
+	}
+
 	return c
 }
 
@@ -122,6 +142,34 @@ func WithTokenRefresher(refresher TokenRefresher) ClientOption {
 	}
 }
 
+// WithRetryPolicy sets the policy used to decide whether failed requests are retried.
+// Passing nil disables retries.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *Client) {
+		if policy == nil {
+			policy = NoRetryPolicy{}
+		}
+		c.retryPolicy = policy
+	}
+}
+
+// WithTimeout sets the maximum time for each API request, including retries.
+// The deadline is applied to the request context and lasts until the response
+// body is closed. Zero means no limit beyond the caller's context.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithWaitTimeout sets the default maximum time to wait for workflows to
+// complete when WaitOptions doesn't specify one
+func WithWaitTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.waitTimeout = timeout
+	}
+}
+
 // SetToken updates the authentication token
 func (c *Client) SetToken(token string) {
 	c.token = token
@@ -135,25 +183,27 @@ func (c *Client) SetTokenRefresher(refresher TokenRefresher) {
 // requestOptions configures how a request is built
 type requestOptions struct {
 	includeAuth bool // Whether to include the Authorization header
+	absoluteURL bool // Whether the path is a full URL rather than relative to the base URL
 }
 
-// buildRequest creates an HTTP request with common setup (headers, logging, etc.)
-func (c *Client) buildRequest(ctx context.Context, method, path string, body interface{}, opts requestOptions) (*http.Request, []byte, error) {
+// buildRequest creates an HTTP request with common headers
+func (c *Client) buildRequest(ctx context.Context, method, path string, body interface{}, opts requestOptions) (*http.Request, error) {
 	var bodyReader io.Reader
-	var bodyBytes []byte
 	if body != nil {
-		var err error
-		bodyBytes, err = json.Marshal(body)
+		bodyBytes, err := json.Marshal(body)
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to marshal request body: %w", err)
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
 		bodyReader = bytes.NewReader(bodyBytes)
 	}
 
 	fullURL := c.baseURL + path
+	if opts.absoluteURL {
+		fullURL = path
+	}
 	req, err := http.NewRequestWithContext(ctx, method, fullURL, bodyReader)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set headers
@@ -167,118 +217,170 @@ func (c *Client) buildRequest(ctx context.Context, method, path string, body int
 	}
 
 	// Add trace ID
-	traceID := uuid.New().String()
-	req.Header.Set("X-Pantheon-Trace-Id", traceID)
+	req.Header.Set(TraceIDHeader, uuid.New().String())
 
-	if c.logger != nil {
-		c.logger.Debug("API Request: %s %s (trace: %s)", method, fullURL, traceID)
+	return req, nil
+}
 
-		// Log detailed HTTP request at trace level
-		if httpLogger, ok := AsHTTPLogger(c.logger); ok && httpLogger.IsTraceEnabled() {
-			headers := make(map[string][]string)
-			for k, v := range req.Header {
-				headers[k] = v
-			}
-			bodyStr := ""
-			if len(bodyBytes) > 0 {
-				bodyStr = string(bodyBytes)
-			}
-			httpLogger.LogHTTPRequest(method, fullURL, headers, bodyStr)
-		}
+// handler returns the client's interceptor chain wrapped around send. The
+// dry-run and read-only guards run first so that nothing sees a held-back or
+// blocked request, followed by request tracing, the interceptors added with
+// WithInterceptors, token refresh when refresh is set, and trace logging.
+func (c *Client) handler(send Handler, refresh bool) Handler {
+	interceptors := make([]Interceptor, 0, len(c.interceptors)+5)
+	if c.dryRun {
+		interceptors = append(interceptors, DryRunInterceptor(c.resolver, c.dryRunPlan))
 	}
-
-	return req, bodyBytes, nil
+	if c.readOnly {
+		interceptors = append(interceptors, ReadOnlyInterceptor())
+	}
+	interceptors = append(interceptors, TracingInterceptor(c.spanExporter))
+	interceptors = append(interceptors, c.interceptors...)
+	if refresh && c.tokenRefresher != nil {
+		interceptors = append(interceptors, TokenRefreshInterceptor(c.tokenRefresher, c.logger, c.SetToken))
+	}
+	interceptors = append(interceptors, LoggingInterceptor(c.logger))
+	return Chain(send, interceptors...)
 }
 
 // Request makes an HTTP request to the API with retry logic
 func (c *Client) Request(ctx context.Context, method, path string, body interface{}) (*http.Response, error) {
-	req, _, err := c.buildRequest(ctx, method, path, body, requestOptions{includeAuth: true})
+	// Before changing a site found through a name from the on-disk cache,
+	// make sure the name still belongs to it
+	if method != http.MethodGet && method != http.MethodHead {
+		var err error
+		if path, _, err = c.resolver.refresh(ctx, path); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := c.request(ctx, method, path, body)
+	if IsNotFound(err) {
+		// The site may have been deleted, or renamed and its name reused, so
+		// look the cached name up again and retry if it now refers to another site
+		if freshPath, changed, _ := c.resolver.refresh(ctx, path); changed {
+			path = freshPath
+			resp, err = c.request(ctx, method, path, body)
+		}
+	}
+	if IsNotFound(err) {
+		// Stop trusting cached names for the site
+		c.resolver.notFound(path)
+	}
+	return resp, err
+}
+
+// request sends a single request through the interceptors with retry logic
+func (c *Client) request(ctx context.Context, method, path string, body interface{}) (*http.Response, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	req, err := c.buildRequest(ctx, method, path, body, requestOptions{includeAuth: true})
 	if err != nil {
+		cancel()
 		return nil, err
 	}
 
-	// Execute request with retry logic
-	return c.doWithRetry(req)
+	// Execute request through the interceptors with retry logic
+	resp, err := c.handler(c.doWithRetry, true)(req)
+	if err == nil && resp.StatusCode >= 400 {
+		err = NewError(resp)
+		resp = nil
+	}
+	cancelOnClose(resp, cancel)
+	return resp, err
+}
+
+// withTimeout applies the client's request timeout to ctx
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, c.timeout)
+}
+
+// cancelOnClose defers cancel until the response body is closed, since the
+// body is still read through the request context. Without a response, cancel
+// is called immediately.
+func cancelOnClose(resp *http.Response, cancel context.CancelFunc) {
+	if resp == nil || resp.Body == nil {
+		cancel()
+		return
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+}
+
+// cancelBody is a response body that cancels its request context when closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
 }
 
-// doWithRetry executes an HTTP request with exponential backoff retry logic
-// It also handles 401 Unauthorized errors by attempting to refresh the token
-// and retrying the request once.
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// doWithRetry executes an HTTP request, retrying failures as directed by the
+// client's retry policy. Delays between attempts are interrupted as soon as the
+// request context is cancelled. Responses that aren't retried are returned
+// as-is, including error statuses.
 func (c *Client) doWithRetry(req *http.Request) (*http.Response, error) {
 	var resp *http.Response
 	var err error
-	tokenRefreshAttempted := false
+	ctx := req.Context()
+	start := time.Now()
 
-	for attempt := 0; attempt <= MaxRetries; attempt++ {
+	for attempt := 0; ; attempt++ {
 		// Clone the request body for retries
 		bodyReader, cloneErr := c.cloneRequestBody(req)
 		if cloneErr != nil {
 			return nil, cloneErr
 		}
 
-		resp, err = c.httpClient.Do(req)
+		resp, err = c.sendAttempt(req, attempt)
 
 		if c.shouldStopRetrying(resp, err) {
-			c.logResponse(resp)
-			// Check if the response indicates an error (4XX or 5XX)
-			if resp.StatusCode >= 400 {
-				body, _ := io.ReadAll(resp.Body)
-				_ = resp.Body.Close()
-				apiErr := &Error{
-					StatusCode: resp.StatusCode,
-					Message:    string(body),
-				}
-
-				// Handle 401 Unauthorized with token refresh
-				// Only attempt refresh if we haven't already tried in this call.
-				// The tokenRefreshAttempted flag prevents multiple refresh attempts
-				// within a single doWithRetry call. Recursive refresh is prevented
-				// by the token refresher using PostOnlyOnce which bypasses doWithRetry.
-				if resp.StatusCode == http.StatusUnauthorized &&
-					c.tokenRefresher != nil &&
-					!tokenRefreshAttempted {
-					tokenRefreshAttempted = true
-					if c.logger != nil {
-						c.logger.Debug("Received 401 Unauthorized, attempting token refresh")
-					}
-
-					// Get context from request
-					ctx := req.Context()
-					newToken, refreshErr := c.tokenRefresher.RefreshToken(ctx)
-
-					if refreshErr != nil {
-						if c.logger != nil {
-							c.logger.Warn("Token refresh failed: %v", refreshErr)
-						}
-						return nil, apiErr
-					}
-
-					// Update client token and retry
-					c.token = newToken
-					req.Header.Set("Authorization", "Bearer "+newToken)
-					c.restoreRequestBody(req, bodyReader)
-					if c.logger != nil {
-						c.logger.Debug("Token refreshed successfully, retrying request")
-					}
-					continue
-				}
-
-				return nil, apiErr
-			}
 			return resp, nil
 		}
 
 		c.logRetryAttempt(err, resp, attempt)
+		var apiErr *Error
+		if resp != nil {
+			apiErr = NewError(resp)
+		}
 
-		if attempt < MaxRetries {
-			c.sleepWithBackoff(attempt)
-			c.restoreRequestBody(req, bodyReader)
+		delay, retry := c.retryPolicy.NextRetry(&RetryAttempt{
+			Request:  req,
+			Response: resp,
+			Err:      err,
+			Attempt:  attempt,
+			Elapsed:  time.Since(start),
+		})
+		if !retry {
+			return nil, c.formatRetryError(err, apiErr, attempt+1)
 		}
 
-		c.closeResponseBody(resp)
+		if c.logger != nil {
+			c.logger.Debug("Retrying after %v", delay)
+		}
+		if sleepErr := sleepContext(ctx, delay); sleepErr != nil {
+			return nil, fmt.Errorf("request cancelled while waiting to retry: %w", sleepErr)
+		}
+		c.restoreRequestBody(req, bodyReader)
 	}
+}
 
-	return c.formatRetryError(err, resp)
+// sendAttempt sends one attempt at req, recording it as a child of the
+// request's span when tracing is enabled
+func (c *Client) sendAttempt(req *http.Request, attempt int) (*http.Response, error) {
+	_, span := StartSpan(req.Context(), nil, "HTTP attempt")
+	span.SetAttribute("http.attempt", attempt+1)
+	resp, err := c.send(req)
+	if err == nil {
+		span.SetAttribute("http.status_code", resp.StatusCode)
+	}
+	span.Finish(err)
+	return resp, err
 }
 
 // cloneRequestBody clones the request body for retry attempts
@@ -303,13 +405,6 @@ func (c *Client) shouldStopRetrying(resp *http.Response, err error) bool {
 	return err == nil && !shouldRetry(resp.StatusCode)
 }
 
-// logResponse logs the response if trace logging is enabled
-func (c *Client) logResponse(resp *http.Response) {
-	if httpLogger, ok := AsHTTPLogger(c.logger); ok && httpLogger.IsTraceEnabled() {
-		c.logHTTPResponse(resp, httpLogger)
-	}
-}
-
 // logRetryAttempt logs a retry attempt
 func (c *Client) logRetryAttempt(err error, resp *http.Response, attempt int) {
 	if c.logger == nil {
@@ -317,21 +412,12 @@ func (c *Client) logRetryAttempt(err error, resp *http.Response, attempt int) {
 	}
 
 	if err != nil {
-		c.logger.Warn("Request failed (attempt %d/%d): %v", attempt+1, MaxRetries+1, err)
+		c.logger.Warn("Request failed (attempt %d): %v", attempt+1, err)
 	} else {
-		c.logger.Warn("Request returned %d (attempt %d/%d)", resp.StatusCode, attempt+1, MaxRetries+1)
+		c.logger.Warn("Request returned %d (attempt %d)", resp.StatusCode, attempt+1)
 	}
 }
 
-// sleepWithBackoff sleeps with exponential backoff
-func (c *Client) sleepWithBackoff(attempt int) {
-	backoff := time.Duration(math.Pow(2, float64(attempt))) * InitialBackoff
-	if c.logger != nil {
-		c.logger.Debug("Retrying after %v", backoff)
-	}
-	time.Sleep(backoff)
-}
-
 // restoreRequestBody restores the request body for retry
 func (c *Client) restoreRequestBody(req *http.Request, bodyReader io.Reader) {
 	if bodyReader != nil {
@@ -339,23 +425,16 @@ func (c *Client) restoreRequestBody(req *http.Request, bodyReader io.Reader) {
 	}
 }
 
-// closeResponseBody safely closes a response body
-func (c *Client) closeResponseBody(resp *http.Response) {
-	if resp != nil && resp.Body != nil {
-		_ = resp.Body.Close()
-	}
-}
-
-// formatRetryError formats the final error after all retries are exhausted
-func (c *Client) formatRetryError(err error, resp *http.Response) (*http.Response, error) {
+// formatRetryError formats the final error once the retry policy gives up
+func (c *Client) formatRetryError(err error, apiErr *Error, attempts int) error {
 	if err != nil {
-		return nil, fmt.Errorf("request failed after %d attempts: %w", MaxRetries+1, err)
+		return fmt.Errorf("request failed after %d attempts: %w", attempts, err)
 	}
-	return resp, fmt.Errorf("request failed with status %d after %d attempts", resp.StatusCode, MaxRetries+1)
+	return fmt.Errorf("request failed after %d attempts: %w", attempts, apiErr)
 }
 
 // logHTTPResponse logs HTTP response details while preserving the response body
-func (c *Client) logHTTPResponse(resp *http.Response, httpLogger HTTPLogger) {
+func logHTTPResponse(resp *http.Response, httpLogger HTTPLogger) {
 	if resp == nil || resp.Body == nil {
 		return
 	}
@@ -401,22 +480,62 @@ func (c *Client) Post(ctx context.Context, path string, body interface{}) (*http
 // This is used for authentication endpoints where we don't want to send
 // an existing session token or trigger token refresh on failure.
 func (c *Client) PostOnlyOnce(ctx context.Context, path string, body interface{}) (*http.Response, error) {
-	req, _, err := c.buildRequest(ctx, http.MethodPost, path, body, requestOptions{includeAuth: false})
+	ctx, cancel := c.withTimeout(ctx)
+	req, err := c.buildRequest(ctx, http.MethodPost, path, body, requestOptions{includeAuth: false})
 	if err != nil {
+		cancel()
 		return nil, err
 	}
 
-	// Execute request directly without retry logic
-	resp, err := c.httpClient.Do(req)
+	// Execute request through the interceptors without retry logic or token refresh
+	resp, err := c.handler(c.send, false)(req)
+	cancelOnClose(resp, cancel)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
 
-	c.logResponse(resp)
-
 	return resp, nil
 }
 
+// GetURL makes a GET request to a full URL outside the API, such as a signed
+// download link. The API token isn't sent, but the request goes through the
+// same timeout, retries and interceptors as API requests.
+func (c *Client) GetURL(ctx context.Context, rawURL string) (*http.Response, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	return c.getURL(ctx, cancel, rawURL)
+}
+
+// DownloadURL is GetURL for large files. The request timeout only applies
+// until the response starts, so that reading the body isn't cut short, and
+// the body isn't trace logged.
+func (c *Client) DownloadURL(ctx context.Context, rawURL string) (*http.Response, error) {
+	ctx, cancel := context.WithCancel(withStreamedBody(ctx))
+	if c.timeout > 0 {
+		timer := time.AfterFunc(c.timeout, cancel)
+		defer timer.Stop()
+	}
+	return c.getURL(ctx, cancel, rawURL)
+}
+
+// getURL sends a GET request to a full URL without the API token. cancel is
+// called once the response body is closed.
+func (c *Client) getURL(ctx context.Context, cancel context.CancelFunc, rawURL string) (*http.Response, error) {
+	req, err := c.buildRequest(ctx, http.MethodGet, rawURL, nil, requestOptions{absoluteURL: true})
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	req.Header.Del("Accept")
+
+	resp, err := c.handler(c.doWithRetry, false)(req)
+	if err == nil && resp.StatusCode >= 400 {
+		err = NewError(resp)
+		resp = nil
+	}
+	cancelOnClose(resp, cancel)
+	return resp, err
+}
+
 // Put makes a PUT request
 func (c *Client) Put(ctx context.Context, path string, body interface{}) (*http.Response, error) {
 	return c.Request(ctx, http.MethodPut, path, body)
@@ -476,53 +595,10 @@ func processPageResults(results []json.RawMessage, seenIDs map[string]bool, allR
 
 // GetPaged makes paginated GET requests using cursor-based pagination and returns all results
 // The Pantheon API uses cursor-based pagination with 'start' parameter (ID of last item)
-// rather than page-based pagination
+// rather than page-based pagination. Use NewPager to process results page by page instead
+// of buffering them all.
 func (c *Client) GetPaged(ctx context.Context, basePath string) ([]json.RawMessage, error) {
-	var allResults []json.RawMessage
-	seenIDs := make(map[string]bool) // Track IDs to detect duplicates
-	limit := 100
-	var start string // Cursor for pagination (ID of last item from previous page)
-
-	for {
-		path := buildPagedPath(basePath, limit, start)
-
-		resp, err := c.Get(ctx, path)
-		if err != nil {
-			return nil, err
-		}
-
-		var results []json.RawMessage
-		if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
-			_ = resp.Body.Close()
-			return nil, fmt.Errorf("failed to decode response: %w", err)
-		}
-		_ = resp.Body.Close()
-
-		if len(results) == 0 {
-			break
-		}
-
-		// Process results and check for duplicates
-		lastID, foundDuplicate := processPageResults(results, seenIDs, &allResults)
-		if foundDuplicate {
-			break
-		}
-
-		// If we got fewer results than the limit, we're done
-		if len(results) < limit {
-			break
-		}
-
-		// If we couldn't extract any IDs, we can't paginate further
-		if lastID == "" {
-			break
-		}
-
-		// Update cursor to the last ID for next page
-		start = lastID
-	}
-
-	return allResults, nil
+	return NewRawPager(c, basePath).Collect(ctx)
 }
 
 // DecodeResponse decodes a JSON response into a target struct
@@ -530,11 +606,7 @@ func DecodeResponse(resp *http.Response, target interface{}) error {
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(resp.Body)
-		return &Error{
-			StatusCode: resp.StatusCode,
-			Message:    string(body),
-		}
+		return NewError(resp)
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
@@ -543,37 +615,3 @@ func DecodeResponse(resp *http.Response, target interface{}) error {
 
 	return nil
 }
-
-// Error represents an API error response
-type Error struct {
-	StatusCode int
-	Message    string
-}
-
-func (e *Error) Error() string {
-	return fmt.Sprintf("API error %d: %s", e.StatusCode, e.Message)
-}
-
-// IsNotFound returns true if the error is a 404 Not Found
-func IsNotFound(err error) bool {
-	if apiErr, ok := err.(*Error); ok {
-		return apiErr.StatusCode == http.StatusNotFound
-	}
-	return false
-}
-
-// IsConflict returns true if the error is a 409 Conflict
-func IsConflict(err error) bool {
-	if apiErr, ok := err.(*Error); ok {
-		return apiErr.StatusCode == http.StatusConflict
-	}
-	return false
-}
-
-// IsUnauthorized returns true if the error is a 401 Unauthorized
-func IsUnauthorized(err error) bool {
-	if apiErr, ok := err.(*Error); ok {
-		return apiErr.StatusCode == http.StatusUnauthorized
-	}
-	return false
-}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -401,3 +402,39 @@ func TestClientRequestUnauthorizedRefreshOnlyOnce(t *testing.T) {
 		t.Errorf("expected server to be called twice, got %d", serverCallCount)
 	}
 }
+
+func TestClientRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"ok": true}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithRetryPolicy(nil),
+		WithTimeout(50*time.Millisecond),
+	)
+
+	_, err := client.Get(context.Background(), "/slow")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	// The deadline must not expire while the caller is still reading the body
+	resp, err := client.Get(context.Background(), "/fast")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var result map[string]bool
+	if err := DecodeResponse(resp, &result); err != nil || !result["ok"] {
+		t.Errorf("expected decoded body, got %v (%v)", result, err)
+	}
+}
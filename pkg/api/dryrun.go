@@ -0,0 +1,200 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrDryRun is matched by errors for requests held back by dry-run mode
+var ErrDryRun = errors.New("not sent in dry-run mode")
+
+// DryRunSiteID stands in for the ID of a site created during a dry run, so
+// that the steps that follow its creation can be planned too
+const DryRunSiteID = "00000000-0000-0000-0000-000000000000"
+
+// dryRunTargetPattern extracts the site and optional environment a request
+// path refers to
+var dryRunTargetPattern = regexp.MustCompile(`/sites/([^/?]+)(?:/environments/([^/?]+))?`)
+
+// dryRunWorkflowPattern matches requests that start a workflow
+var dryRunWorkflowPattern = regexp.MustCompile(`/(sites|users)/([^/?]*)(?:/environments/([^/?]+))?/workflows$`)
+
+// PlannedRequest is a change that dry-run mode recorded instead of sending.
+// It is tagged for output so that callers can print the plan in any format.
+type PlannedRequest struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Body   interface{} `json:"body,omitempty"`
+}
+
+// DryRunError describes a change that dry-run mode can't stand in for, such
+// as an interactive session, so the command stops before making it
+type DryRunError PlannedRequest
+
+func (e *DryRunError) Error() string {
+	return fmt.Sprintf("%s %s %s", e.Method, e.Path, ErrDryRun)
+}
+
+// Is reports whether target is ErrDryRun
+func (e *DryRunError) Is(target error) bool { return target == ErrDryRun }
+
+// WithDryRun makes the client record each request that could change
+// something instead of sending it, and answer it with a made-up success.
+// Reads are still sent, so identifiers are resolved and validated as usual.
+// The recorded requests are returned by PlannedRequests.
+func WithDryRun(enabled bool) ClientOption {
+	return func(c *Client) {
+		c.dryRun = enabled
+	}
+}
+
+// PlannedRequests returns the requests recorded in dry-run mode, in the order
+// they were made
+func (c *Client) PlannedRequests() []PlannedRequest {
+	return c.dryRunPlan.Requests()
+}
+
+// DryRunPlan records the requests held back in dry-run mode, along with the
+// workflows made up in reply so that waiting for them succeeds
+type DryRunPlan struct {
+	mu        sync.Mutex
+	requests  []PlannedRequest
+	workflows map[string][]byte
+}
+
+// NewDryRunPlan creates an empty plan
+func NewDryRunPlan() *DryRunPlan {
+	return &DryRunPlan{workflows: make(map[string][]byte)}
+}
+
+// Requests returns the recorded requests in the order they were made
+func (p *DryRunPlan) Requests() []PlannedRequest {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]PlannedRequest(nil), p.requests...)
+}
+
+// record adds a request to the plan and returns the body to answer it with.
+// A request that starts a workflow is answered with a workflow that has
+// already succeeded; anything else gets its own body back.
+func (p *DryRunPlan) record(planned PlannedRequest, path string, body []byte) []byte {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.requests = append(p.requests, planned)
+
+	match := dryRunWorkflowPattern.FindStringSubmatch(path)
+	if planned.Method != http.MethodPost || match == nil {
+		if len(body) == 0 {
+			return []byte(`{}`)
+		}
+		return body
+	}
+
+	workflow := map[string]interface{}{
+		"id":          fmt.Sprintf("dry-run-%d", len(p.workflows)+1),
+		"result":      "succeeded",
+		"finished_at": float64(time.Now().Unix()),
+	}
+	if request, ok := planned.Body.(map[string]interface{}); ok {
+		workflow["type"] = request["type"]
+		workflow["params"] = request["params"]
+	}
+	if match[1] == "sites" {
+		workflow["site_id"] = match[2]
+		workflow["environment"] = match[3]
+	} else {
+		// A user workflow may create a site, which the steps that follow
+		// refer to by ID
+		workflow["user_id"] = match[2]
+		workflow["site_id"] = DryRunSiteID
+	}
+
+	data, _ := json.Marshal(workflow)
+	p.workflows[workflow["id"].(string)] = data
+	return data
+}
+
+// lookup returns a made-up response body for a read of something that only
+// exists in the plan: a workflow it started or a site it created
+func (p *DryRunPlan) lookup(path string) ([]byte, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if i := strings.LastIndex(path, "/workflows/"); i >= 0 {
+		if data, ok := p.workflows[path[i+len("/workflows/"):]]; ok {
+			return data, true
+		}
+	}
+	if strings.HasSuffix(path, "/sites/"+DryRunSiteID) {
+		return []byte(`{"id": "` + DryRunSiteID + `"}`), true
+	}
+	return nil, false
+}
+
+// DryRunInterceptor records the requests that ReadOnlyInterceptor would
+// refuse in plan, capturing their method, path and body, and answers them
+// with a made-up success instead of sending them. Reads of the workflows and
+// sites made up this way are answered from plan too. When resolver is not
+// nil, the site and environment in the path are looked up first, so that a
+// request for one that doesn't exist fails as it would if it were sent.
+func DryRunInterceptor(resolver *Resolver, plan *DryRunPlan) Interceptor {
+	return func(req *http.Request, next Handler) (*http.Response, error) {
+		if !isMutating(req) {
+			if data, ok := plan.lookup(req.URL.Path); ok {
+				return dryRunResponse(req, data), nil
+			}
+			return next(req)
+		}
+
+		match := dryRunTargetPattern.FindStringSubmatch(req.URL.Path)
+		if match != nil && resolver != nil && match[1] != DryRunSiteID {
+			var err error
+			if match[2] != "" {
+				_, _, err = resolver.Environment(req.Context(), match[1], match[2])
+			} else {
+				_, err = resolver.Site(req.Context(), match[1])
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		data, err := peekBody(req)
+		if err != nil {
+			return nil, err
+		}
+		planned := PlannedRequest{Method: req.Method, Path: req.URL.RequestURI()}
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &planned.Body); err != nil {
+				planned.Body = string(data)
+			}
+		}
+		return dryRunResponse(req, plan.record(planned, req.URL.Path, data)), nil
+	}
+}
+
+// dryRunResponse builds a successful JSON response to req
+func dryRunResponse(req *http.Request, body []byte) *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
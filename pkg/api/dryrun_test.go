@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientDryRun(t *testing.T) {
+	const siteID = "11111111-2222-3333-4444-555555555555"
+
+	var methods []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method+" "+r.URL.Path)
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("GET /sites/"+siteID+"/environments", func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method+" "+r.URL.Path)
+		_, _ = w.Write([]byte(`{"dev": {"id": "dev"}}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	// Dry-run takes precedence over read-only so that planned changes are shown
+	client := NewClient(WithBaseURL(server.URL), WithRetryPolicy(nil), WithDryRun(true), WithReadOnly(true))
+	ctx := context.Background()
+
+	// Starting a workflow succeeds, and so does waiting for it
+	workflows := NewWorkflowsService(client)
+	workflow, err := workflows.CreateForSite(ctx, siteID, "clear_cache", map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	workflow, err = workflows.Wait(ctx, siteID, workflow.ID, nil)
+	if err != nil {
+		t.Fatalf("unexpected error waiting for a planned workflow: %v", err)
+	}
+	if !workflow.IsSuccessful() || workflow.Type != "clear_cache" {
+		t.Errorf("expected a successful clear_cache workflow, got %+v", workflow)
+	}
+
+	path := "/sites/" + siteID + "/environments/dev/workflows"
+	if _, err := client.Post(ctx, path, map[string]interface{}{"type": "wipe", "params": map[string]interface{}{}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := client.Delete(ctx, "/sites/"+siteID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = client.Post(ctx, "/sites/"+siteID+"/environments/missing/workflows", nil)
+	if err == nil {
+		t.Error("expected an unknown environment to fail")
+	}
+
+	planned := client.PlannedRequests()
+	want := []string{
+		"POST /sites/" + siteID + "/workflows",
+		"POST " + path,
+		"DELETE /sites/" + siteID,
+	}
+	if len(planned) != len(want) {
+		t.Fatalf("expected %d planned requests, got %+v", len(want), planned)
+	}
+	for i, w := range want {
+		if got := planned[i].Method + " " + planned[i].Path; got != w {
+			t.Errorf("planned request %d = %s, want %s", i, got, w)
+		}
+	}
+	body, ok := planned[1].Body.(map[string]interface{})
+	if !ok || body["type"] != "wipe" {
+		t.Errorf("expected workflow body, got %#v", planned[1].Body)
+	}
+
+	// Only environment lookups reach the server
+	for _, method := range methods {
+		if method != "GET /sites/"+siteID+"/environments" {
+			t.Errorf("expected only environment lookups to reach the server, got %v", methods)
+		}
+	}
+}
+
+func TestClientDryRun_CreatedSite(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithRetryPolicy(nil), WithDryRun(true))
+	ctx := context.Background()
+
+	// The steps after creating a site refer to a stand-in for its ID
+	workflows := NewWorkflowsService(client)
+	workflow, err := workflows.CreateForUser(ctx, "user-1", "create_site", map[string]interface{}{"site_name": "new-site"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	workflow, err = workflows.WaitForUser(ctx, "user-1", workflow.ID, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if workflow.SiteID != DryRunSiteID {
+		t.Fatalf("expected the stand-in site ID, got %q", workflow.SiteID)
+	}
+	if _, err := workflows.CreateForSite(ctx, workflow.SiteID, "deploy_product", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := NewSitesService(client).Get(ctx, workflow.SiteID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if planned := client.PlannedRequests(); len(planned) != 2 {
+		t.Errorf("expected 2 planned requests, got %+v", planned)
+	}
+}
+
+func TestDryRunError(t *testing.T) {
+	err := error(&DryRunError{Method: "SSH", Path: "dev.site@example.com", Body: "drush status"})
+	if !errors.Is(err, ErrDryRun) {
+		t.Errorf("expected %v to match ErrDryRun", err)
+	}
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/deviantintegral/terminus-golang/pkg/api/models"
@@ -46,6 +47,13 @@ func (s *EnvironmentsService) List(ctx context.Context, siteIdentifier string) (
 	return envs, nil
 }
 
+// ListForSites returns the environments for several sites, fetching up to
+// workers sites at a time. Results are returned in the same order as the sites
+// and a failure for one site does not prevent the others from being listed.
+func (s *EnvironmentsService) ListForSites(ctx context.Context, siteIdentifiers []string, workers int) []Result[[]*models.Environment] {
+	return ForEach(ctx, siteIdentifiers, workers, s.List)
+}
+
 // Get returns a specific environment
 func (s *EnvironmentsService) Get(ctx context.Context, siteID, envID string) (*models.Environment, error) {
 	path := fmt.Sprintf("/sites/%s/environments/%s", siteID, envID)
@@ -241,6 +249,36 @@ func (s *EnvironmentsService) GetConnectionInfo(ctx context.Context, siteID, env
 	return &info, nil
 }
 
+// Wake wakes an environment that may be asleep by requesting the health
+// check page on its platform domain, as PHP Terminus does. The request goes
+// through the client like any other, without the API token; an error status
+// from the health check is returned as an error.
+func (s *EnvironmentsService) Wake(ctx context.Context, siteID, envID string) error {
+	domains, err := NewDomainsService(s.client).List(ctx, siteID, envID)
+	if err != nil {
+		return fmt.Errorf("failed to wake environment: %w", err)
+	}
+
+	var host string
+	for _, domain := range domains {
+		if domain.Type == "platform" {
+			host = domain.Domain
+			break
+		}
+	}
+	if host == "" {
+		return fmt.Errorf("failed to wake environment: %s.%s has no platform domain", siteID, envID)
+	}
+
+	resp, err := s.client.GetURL(ctx, "https://"+host+"/pantheon_healthcheck")
+	if err != nil {
+		return fmt.Errorf("failed to wake environment: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
 // GetUpstreamUpdates returns upstream update information
 func (s *EnvironmentsService) GetUpstreamUpdates(ctx context.Context, siteID, envID string) (*models.UpstreamUpdate, error) {
 	path := fmt.Sprintf("/sites/%s/environments/%s/upstream-updates", siteID, envID)
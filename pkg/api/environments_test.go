@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -400,3 +401,66 @@ func TestEnvironmentsService_GetMetrics_EmptyTimeseries(t *testing.T) {
 		t.Errorf("expected 0 metrics for empty timeseries, got %d", len(metrics))
 	}
 }
+
+func TestEnvironmentsService_Wake(t *testing.T) {
+	healthChecks := 0
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sites/site-id/environments/dev/domains":
+			host := r.Host
+			_ = json.NewEncoder(w).Encode([]map[string]interface{}{
+				{"id": "www.example.com", "domain": "www.example.com", "type": "custom"},
+				{"id": host, "domain": host, "type": "platform"},
+			})
+		case "/pantheon_healthcheck":
+			healthChecks++
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	if err := NewEnvironmentsService(client).Wake(context.Background(), "site-id", "dev"); err != nil {
+		t.Fatalf("Wake failed: %v", err)
+	}
+	if healthChecks != 1 {
+		t.Errorf("expected one health check, got %d", healthChecks)
+	}
+}
+
+func TestEnvironmentsService_Wake_ThroughClient(t *testing.T) {
+	var authorization string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sites/site-id/environments/dev/domains":
+			_ = json.NewEncoder(w).Encode([]map[string]interface{}{
+				{"id": r.Host, "domain": r.Host, "type": "platform"},
+			})
+		case "/pantheon_healthcheck":
+			// A locked environment asks for credentials
+			authorization = r.Header.Get("Authorization")
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	var intercepted []string
+	record := func(req *http.Request, next Handler) (*http.Response, error) {
+		intercepted = append(intercepted, req.URL.Path)
+		return next(req)
+	}
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(server.Client()), WithToken("secret"), WithInterceptors(record))
+	err := NewEnvironmentsService(client).Wake(context.Background(), "site-id", "dev")
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("expected the health check status as an error, got %v", err)
+	}
+	if len(intercepted) != 2 || intercepted[1] != "/pantheon_healthcheck" {
+		t.Errorf("expected the health check to go through the interceptors, got %v", intercepted)
+	}
+	if authorization != "" {
+		t.Errorf("expected no API token to be sent with the health check, got %q", authorization)
+	}
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// TraceIDHeader is the header used to correlate a request with Pantheon's logs
+const TraceIDHeader = "X-Pantheon-Trace-Id"
+
+// Sentinel errors matched by *Error through errors.Is, based on the HTTP status
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrServer       = errors.New("server error")
+)
+
+// Error represents an API error response
+type Error struct {
+	// StatusCode is the HTTP status of the response
+	StatusCode int
+	// Code is the machine-readable error code from the response body, if any
+	Code string
+	// Message is the human-readable error message, or the raw body if it couldn't be parsed
+	Message string
+	// Details holds any additional structured information from the response body
+	Details json.RawMessage
+	// TraceID identifies the request in Pantheon's logs
+	TraceID string
+}
+
+func (e *Error) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("API error %d (%s): %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("API error %d: %s", e.StatusCode, e.Message)
+}
+
+// Is reports whether the error matches one of the sentinel errors for its status code
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound || e.StatusCode == http.StatusGone
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServer:
+		return e.StatusCode >= http.StatusInternalServerError
+	}
+	return false
+}
+
+// IsNotFound returns true if the error is, or wraps, a 404 Not Found
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsConflict returns true if the error is, or wraps, a 409 Conflict
+func IsConflict(err error) bool {
+	return errors.Is(err, ErrConflict)
+}
+
+// IsUnauthorized returns true if the error is, or wraps, a 401 Unauthorized
+func IsUnauthorized(err error) bool {
+	return errors.Is(err, ErrUnauthorized)
+}
+
+// IsForbidden returns true if the error is, or wraps, a 403 Forbidden
+func IsForbidden(err error) bool {
+	return errors.Is(err, ErrForbidden)
+}
+
+// IsRateLimited returns true if the error is, or wraps, a 429 Too Many Requests
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrRateLimited)
+}
+
+// TraceID returns the Pantheon trace ID of the API error wrapped by err, if any
+func TraceID(err error) string {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.TraceID
+	}
+	return ""
+}
+
+// NewError builds an *Error from an unsuccessful response, reading and closing its body
+func NewError(resp *http.Response) *Error {
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	apiErr := &Error{
+		StatusCode: resp.StatusCode,
+		TraceID:    resp.Header.Get(TraceIDHeader),
+	}
+	if apiErr.TraceID == "" && resp.Request != nil {
+		apiErr.TraceID = resp.Request.Header.Get(TraceIDHeader)
+	}
+
+	apiErr.Code, apiErr.Message, apiErr.Details = parseErrorBody(body)
+	return apiErr
+}
+
+// parseErrorBody extracts the code, message and details from a Pantheon error body.
+// The API returns errors either as a bare JSON string or as an object using one
+// of several field names; anything else is returned as the message verbatim.
+func parseErrorBody(body []byte) (code, message string, details json.RawMessage) {
+	trimmed := bytes.TrimSpace(body)
+
+	var text string
+	if err := json.Unmarshal(trimmed, &text); err == nil {
+		return "", text, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(trimmed, &fields); err != nil {
+		return "", string(trimmed), nil
+	}
+
+	// Some endpoints nest the error in an object under "error"
+	if nested, ok := fields["error"]; ok && bytes.HasPrefix(bytes.TrimSpace(nested), []byte("{")) {
+		var inner map[string]json.RawMessage
+		if err := json.Unmarshal(nested, &inner); err == nil {
+			fields = inner
+		}
+	}
+
+	code = firstField(fields, "code", "error_code")
+	message = firstField(fields, "message", "error", "reason", "detail", "error_description")
+	for _, key := range []string{"details", "errors"} {
+		if value, ok := fields[key]; ok {
+			details = value
+			break
+		}
+	}
+
+	if message == "" {
+		message = string(trimmed)
+	}
+	return code, message, details
+}
+
+// firstField returns the first of keys present in fields as a string,
+// accepting either JSON strings or numbers
+func firstField(fields map[string]json.RawMessage, keys ...string) string {
+	for _, key := range keys {
+		value, ok := fields[key]
+		if !ok {
+			continue
+		}
+
+		var text string
+		if err := json.Unmarshal(value, &text); err == nil && text != "" {
+			return text
+		}
+
+		var number json.Number
+		if err := json.Unmarshal(value, &number); err == nil {
+			return strings.TrimSpace(number.String())
+		}
+	}
+	return ""
+}
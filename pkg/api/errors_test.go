@@ -0,0 +1,162 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseErrorBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantCode    string
+		wantMessage string
+		wantDetails string
+	}{
+		{
+			name:        "bare string",
+			body:        `"Site not found"`,
+			wantMessage: "Site not found",
+		},
+		{
+			name:        "error field",
+			body:        `{"error": "not found"}`,
+			wantMessage: "not found",
+		},
+		{
+			name:        "code message and details",
+			body:        `{"code": "invalid_site_name", "message": "Site name is invalid", "details": {"field": "site_name"}}`,
+			wantCode:    "invalid_site_name",
+			wantMessage: "Site name is invalid",
+			wantDetails: `{"field": "site_name"}`,
+		},
+		{
+			name:        "nested error object with numeric code",
+			body:        `{"error": {"code": 1042, "reason": "Quota exceeded"}, "ignored": true}`,
+			wantCode:    "1042",
+			wantMessage: "Quota exceeded",
+		},
+		{
+			name:        "errors list",
+			body:        `{"message": "Validation failed", "errors": ["label is required"]}`,
+			wantMessage: "Validation failed",
+			wantDetails: `["label is required"]`,
+		},
+		{
+			name:        "plain text",
+			body:        "  Internal Server Error\n",
+			wantMessage: "Internal Server Error",
+		},
+		{
+			name:        "object without message",
+			body:        `{"foo": "bar"}`,
+			wantMessage: `{"foo": "bar"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, message, details := parseErrorBody([]byte(tt.body))
+			if code != tt.wantCode {
+				t.Errorf("code = %q, want %q", code, tt.wantCode)
+			}
+			if message != tt.wantMessage {
+				t.Errorf("message = %q, want %q", message, tt.wantMessage)
+			}
+			if string(details) != tt.wantDetails {
+				t.Errorf("details = %s, want %s", details, tt.wantDetails)
+			}
+		})
+	}
+}
+
+func TestErrorIs(t *testing.T) {
+	tests := []struct {
+		status   int
+		sentinel error
+	}{
+		{http.StatusBadRequest, ErrBadRequest},
+		{http.StatusUnprocessableEntity, ErrBadRequest},
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusForbidden, ErrForbidden},
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusGone, ErrNotFound},
+		{http.StatusConflict, ErrConflict},
+		{http.StatusTooManyRequests, ErrRateLimited},
+		{http.StatusBadGateway, ErrServer},
+	}
+
+	sentinels := []error{ErrBadRequest, ErrUnauthorized, ErrForbidden, ErrNotFound, ErrConflict, ErrRateLimited, ErrServer}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d", tt.status), func(t *testing.T) {
+			err := fmt.Errorf("outer: %w", &Error{StatusCode: tt.status})
+			for _, sentinel := range sentinels {
+				if got := errors.Is(err, sentinel); got != (sentinel == tt.sentinel) {
+					t.Errorf("errors.Is(%d, %v) = %v", tt.status, sentinel, got)
+				}
+			}
+		})
+	}
+}
+
+func TestClientErrorCarriesTraceID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		_ = json.NewEncoder(w).Encode(map[string]string{"code": "forbidden", "message": "You do not have access"})
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(&http.Client{Timeout: 5 * time.Second}))
+	_, err := NewSitesService(client).GetPlan(context.Background(), "12345678-1234-1234-1234-123456789abc")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected wrapped *Error, got %T", err)
+	}
+	if apiErr.Code != "forbidden" || apiErr.Message != "You do not have access" {
+		t.Errorf("unexpected parsed error: %+v", apiErr)
+	}
+	if !IsForbidden(err) {
+		t.Error("expected IsForbidden to match wrapped error")
+	}
+	if TraceID(err) == "" {
+		t.Error("expected trace ID from the request")
+	}
+}
+
+func TestClientRetriesExhaustedIsRateLimited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set(TraceIDHeader, "server-trace")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`"Slow down"`))
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithHTTPClient(&http.Client{Timeout: 5 * time.Second}),
+		WithRetryPolicy(&DefaultRetryPolicy{MaxRetries: 1, InitialBackoff: time.Millisecond}),
+	)
+
+	resp, err := client.Get(context.Background(), "/test")
+	if resp != nil {
+		t.Error("expected no response once retries are exhausted")
+	}
+	if !IsRateLimited(err) {
+		t.Fatalf("expected rate limited error, got %v", err)
+	}
+	if TraceID(err) != "server-trace" {
+		t.Errorf("expected trace ID echoed by the server, got %q", TraceID(err))
+	}
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// DefaultConcurrency is the number of workers ForEach uses when none is specified
+const DefaultConcurrency = 4
+
+// Result holds the outcome of processing a single item with ForEach
+type Result[T any] struct {
+	Value T
+	Err   error
+}
+
+// ForEach calls fn for every item using at most workers concurrent goroutines
+// and returns the results in the same order as items. A failing item does not
+// stop the others; its error is recorded in its Result. Once ctx is done, items
+// that have not started yet are skipped and their Result holds the context error.
+func ForEach[In, Out any](ctx context.Context, items []In, workers int, fn func(ctx context.Context, item In) (Out, error)) []Result[Out] {
+	results := make([]Result[Out], len(items))
+	if len(items) == 0 {
+		return results
+	}
+
+	if workers <= 0 {
+		workers = DefaultConcurrency
+	}
+	if workers > len(items) {
+		workers = len(items)
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := ctx.Err(); err != nil {
+					results[i].Err = err
+					continue
+				}
+				value, err := fn(ctx, items[i])
+				results[i] = Result[Out]{Value: value, Err: err}
+			}
+		}()
+	}
+
+feed:
+	for i := range items {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			for j := i; j < len(items); j++ {
+				results[j].Err = ctx.Err()
+			}
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
+
+// ResultErrors joins the errors from a set of results, returning nil if every item succeeded
+func ResultErrors[T any](results []Result[T]) error {
+	var errs []error
+	for _, result := range results {
+		if result.Err != nil {
+			errs = append(errs, result.Err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newParallelismTestServer returns a server that records the maximum number of
+// requests it handled at once. Requests for paths containing "fail" return 404.
+func newParallelismTestServer(delay time.Duration, maxInFlight *atomic.Int32) *httptest.Server {
+	var inFlight atomic.Int32
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			seen := maxInFlight.Load()
+			if current <= seen || maxInFlight.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+
+		time.Sleep(delay)
+
+		if strings.Contains(r.URL.Path, "fail") {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error": "not found"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"id": %q}`, strings.TrimPrefix(r.URL.Path, "/items/"))
+	}))
+}
+
+func fetchTestItem(client *Client) func(ctx context.Context, id string) (string, error) {
+	return func(ctx context.Context, id string) (string, error) {
+		resp, err := client.Get(ctx, "/items/"+id) //nolint:bodyclose // DecodeResponse closes body
+		if err != nil {
+			return "", err
+		}
+		var item struct {
+			ID string `json:"id"`
+		}
+		if err := DecodeResponse(resp, &item); err != nil {
+			return "", err
+		}
+		return item.ID, nil
+	}
+}
+
+func TestForEachBoundsParallelism(t *testing.T) {
+	var maxInFlight atomic.Int32
+	server := newParallelismTestServer(30*time.Millisecond, &maxInFlight)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(&http.Client{Timeout: 5 * time.Second}))
+
+	items := make([]string, 12)
+	for i := range items {
+		items[i] = fmt.Sprintf("item-%02d", i)
+	}
+
+	start := time.Now()
+	results := ForEach(context.Background(), items, 3, fetchTestItem(client))
+	elapsed := time.Since(start)
+
+	if got := maxInFlight.Load(); got != 3 {
+		t.Errorf("expected exactly 3 concurrent requests, saw %d", got)
+	}
+	// 12 items over 3 workers is 4 rounds; sequential would take 12
+	if elapsed > 12*30*time.Millisecond {
+		t.Errorf("expected requests to run in parallel, took %v", elapsed)
+	}
+
+	for i, result := range results {
+		if result.Err != nil {
+			t.Errorf("item %d: unexpected error: %v", i, result.Err)
+		}
+		if result.Value != items[i] {
+			t.Errorf("item %d: expected %s, got %s", i, items[i], result.Value)
+		}
+	}
+}
+
+func TestForEachCollectsErrors(t *testing.T) {
+	var maxInFlight atomic.Int32
+	server := newParallelismTestServer(0, &maxInFlight)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(&http.Client{Timeout: 5 * time.Second}))
+
+	items := []string{"a", "fail-b", "c", "fail-d", "e"}
+	results := ForEach(context.Background(), items, 2, fetchTestItem(client))
+
+	for i, result := range results {
+		shouldFail := strings.HasPrefix(items[i], "fail")
+		if shouldFail && !IsNotFound(result.Err) {
+			t.Errorf("item %d: expected not found error, got %v", i, result.Err)
+		}
+		if !shouldFail && (result.Err != nil || result.Value != items[i]) {
+			t.Errorf("item %d: expected %s, got %q (err %v)", i, items[i], result.Value, result.Err)
+		}
+	}
+
+	if err := ResultErrors(results); err == nil {
+		t.Error("expected joined error")
+	}
+}
+
+func TestForEachContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var calls atomic.Int32
+	results := ForEach(ctx, []int{1, 2, 3, 4, 5, 6}, 1, func(_ context.Context, item int) (int, error) {
+		calls.Add(1)
+		if item == 2 {
+			cancel()
+		}
+		return item * 10, nil
+	})
+
+	if calls.Load() > 3 {
+		t.Errorf("expected processing to stop soon after cancel, got %d calls", calls.Load())
+	}
+	if results[0].Err != nil || results[0].Value != 10 {
+		t.Errorf("expected first item to succeed, got %+v", results[0])
+	}
+	if !errors.Is(results[5].Err, context.Canceled) {
+		t.Errorf("expected last item to be cancelled, got %+v", results[5])
+	}
+}
+
+func TestForEachEmpty(t *testing.T) {
+	results := ForEach(context.Background(), []string{}, 0, func(_ context.Context, s string) (string, error) {
+		return s, nil
+	})
+	if len(results) != 0 {
+		t.Errorf("expected no results, got %d", len(results))
+	}
+	if err := ResultErrors(results); err != nil {
+		t.Errorf("expected nil error, got %v", err)
+	}
+}
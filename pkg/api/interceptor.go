@@ -0,0 +1,158 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Handler sends a request and returns its response
+type Handler func(req *http.Request) (*http.Response, error)
+
+// Interceptor wraps the sending of an API request. It may inspect or modify
+// the request, call next to continue down the chain (or not, to short-circuit
+// it), and inspect or replace the response. An interceptor sees each call
+// once: retries happen further down the chain, and error statuses arrive as
+// responses that the client turns into *Error after the chain returns.
+type Interceptor func(req *http.Request, next Handler) (*http.Response, error)
+
+// WithInterceptors appends interceptors to the client's chain. They run in the
+// order given, after the dry-run and read-only guards and request tracing, and
+// before the built-in token refresh and trace logging interceptors.
+func WithInterceptors(interceptors ...Interceptor) ClientOption {
+	return func(c *Client) {
+		c.interceptors = append(c.interceptors, interceptors...)
+	}
+}
+
+// Chain returns a handler that passes requests through interceptors in order
+// before calling handler
+func Chain(handler Handler, interceptors ...Interceptor) Handler {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], handler
+		handler = func(req *http.Request) (*http.Response, error) {
+			return interceptor(req, next)
+		}
+	}
+	return handler
+}
+
+// LoggingInterceptor logs each request at debug level, and its headers, body
+// and response when the logger has trace logging enabled. A nil logger
+// disables it.
+func LoggingInterceptor(logger Logger) Interceptor {
+	return func(req *http.Request, next Handler) (*http.Response, error) {
+		if logger == nil {
+			return next(req)
+		}
+
+		traceID := req.Header.Get(TraceIDHeader)
+		logger.Debug("API Request: %s %s (trace: %s)", req.Method, req.URL.String(), traceID)
+
+		httpLogger, trace := AsHTTPLogger(logger)
+		trace = trace && httpLogger.IsTraceEnabled()
+		if trace {
+			body, err := peekBody(req)
+			if err != nil {
+				return nil, err
+			}
+			headers := make(map[string][]string)
+			for k, v := range req.Header {
+				headers[k] = v
+			}
+			httpLogger.LogHTTPRequest(req.Method, req.URL.String(), headers, string(body))
+		}
+
+		resp, err := next(req)
+		if trace && err == nil {
+			if isStreamedBody(req.Context()) {
+				httpLogger.LogHTTPResponse(resp.StatusCode, resp.Status, resp.Header, "(body not logged)")
+			} else {
+				logHTTPResponse(resp, httpLogger)
+			}
+		}
+		return resp, err
+	}
+}
+
+// streamedBodyKey marks a request context whose response body may be too
+// large to hold in memory
+type streamedBodyKey struct{}
+
+// withStreamedBody marks ctx so that response bodies aren't read for logging
+func withStreamedBody(ctx context.Context) context.Context {
+	return context.WithValue(ctx, streamedBodyKey{}, true)
+}
+
+// isStreamedBody reports whether ctx was marked by withStreamedBody
+func isStreamedBody(ctx context.Context) bool {
+	streamed, _ := ctx.Value(streamedBodyKey{}).(bool)
+	return streamed
+}
+
+// TokenRefreshInterceptor renews the session when a request is rejected with
+// 401 Unauthorized, then sends the request once more with the new token.
+// onRefresh is called with the new token so that later requests use it. If
+// the refresh fails, the 401 response is returned unchanged.
+func TokenRefreshInterceptor(refresher TokenRefresher, logger Logger, onRefresh func(token string)) Interceptor {
+	return func(req *http.Request, next Handler) (*http.Response, error) {
+		resp, err := next(req)
+		if err != nil || refresher == nil || resp.StatusCode != http.StatusUnauthorized {
+			return resp, err
+		}
+
+		if logger != nil {
+			logger.Debug("Received 401 Unauthorized, attempting token refresh")
+		}
+		ctx, span := StartSpan(req.Context(), nil, "token refresh")
+		newToken, refreshErr := refresher.RefreshToken(ctx)
+		span.Finish(refreshErr)
+		if refreshErr != nil {
+			if logger != nil {
+				logger.Warn("Token refresh failed: %v", refreshErr)
+			}
+			return resp, nil
+		}
+		_ = resp.Body.Close()
+
+		if onRefresh != nil {
+			onRefresh(newToken)
+		}
+		retry := req.Clone(req.Context())
+		if req.GetBody != nil {
+			if retry.Body, err = req.GetBody(); err != nil {
+				return nil, fmt.Errorf("failed to read request body: %w", err)
+			}
+		}
+		retry.Header.Set("Authorization", "Bearer "+newToken)
+		if logger != nil {
+			logger.Debug("Token refreshed successfully, retrying request")
+		}
+		return next(retry)
+	}
+}
+
+// peekBody returns a copy of the request body, leaving the body readable
+func peekBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		defer func() { _ = body.Close() }()
+		return io.ReadAll(body)
+	}
+
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	_ = req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
@@ -0,0 +1,170 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestChainOrder(t *testing.T) {
+	var calls []string
+	record := func(name string) Interceptor {
+		return func(req *http.Request, next Handler) (*http.Response, error) {
+			calls = append(calls, name+" before")
+			resp, err := next(req)
+			calls = append(calls, name+" after")
+			return resp, err
+		}
+	}
+
+	handler := Chain(func(_ *http.Request) (*http.Response, error) {
+		calls = append(calls, "send")
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}, record("first"), record("second"))
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com", http.NoBody)
+	if _, err := handler(req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "first before,second before,send,second after,first after"
+	if got := strings.Join(calls, ","); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestClientInterceptors(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("X-Test") != "yes" {
+			t.Errorf("expected header from interceptor, got %q", r.Header.Get("X-Test"))
+		}
+		_, _ = w.Write([]byte(`{"id": "test"}`))
+	}))
+	defer server.Close()
+
+	var statuses []int
+	addHeader := func(req *http.Request, next Handler) (*http.Response, error) {
+		req.Header.Set("X-Test", "yes")
+		return next(req)
+	}
+	metrics := func(req *http.Request, next Handler) (*http.Response, error) {
+		resp, err := next(req)
+		if err == nil {
+			statuses = append(statuses, resp.StatusCode)
+		}
+		return resp, err
+	}
+	fault := func(req *http.Request, next Handler) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/broken") {
+			return &http.Response{
+				StatusCode: http.StatusForbidden,
+				Body:       io.NopCloser(strings.NewReader(`"Injected fault"`)),
+				Request:    req,
+			}, nil
+		}
+		return next(req)
+	}
+
+	client := NewClient(WithBaseURL(server.URL), WithRetryPolicy(nil), WithInterceptors(addHeader, metrics, fault))
+
+	resp, err := client.Get(context.Background(), "/test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	_, err = client.Get(context.Background(), "/broken")
+	if !IsForbidden(err) || !strings.Contains(err.Error(), "Injected fault") {
+		t.Errorf("expected injected forbidden error, got %v", err)
+	}
+
+	if requests != 1 {
+		t.Errorf("expected the fault to short-circuit the server, got %d requests", requests)
+	}
+	if len(statuses) != 2 || statuses[0] != http.StatusOK || statuses[1] != http.StatusForbidden {
+		t.Errorf("expected metrics to see both responses, got %v", statuses)
+	}
+}
+
+func TestTokenRefreshInterceptor(t *testing.T) {
+	var tokens []string
+	next := func(req *http.Request) (*http.Response, error) {
+		tokens = append(tokens, req.Header.Get("Authorization"))
+		body, _ := io.ReadAll(req.Body)
+		if string(body) != `{"a":1}` {
+			t.Errorf("expected request body on every attempt, got %q", body)
+		}
+		status := http.StatusUnauthorized
+		if len(tokens) > 1 {
+			status = http.StatusOK
+		}
+		return &http.Response{StatusCode: status, Body: http.NoBody}, nil
+	}
+
+	var refreshed string
+	interceptor := TokenRefreshInterceptor(&mockTokenRefresher{token: "new-token"}, nil, func(token string) { refreshed = token })
+
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, "http://example.com", bytes.NewReader([]byte(`{"a":1}`)))
+	req.Header.Set("Authorization", "Bearer old-token")
+	resp, err := interceptor(req, next)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK || refreshed != "new-token" {
+		t.Errorf("expected refreshed success, got %d with token %q", resp.StatusCode, refreshed)
+	}
+	if strings.Join(tokens, ",") != "Bearer old-token,Bearer new-token" {
+		t.Errorf("unexpected tokens sent: %v", tokens)
+	}
+}
+
+func TestTokenRefreshInterceptorPassesErrorsThrough(t *testing.T) {
+	sendErr := errors.New("connection refused")
+	refresher := &mockTokenRefresher{token: "new-token"}
+	interceptor := TokenRefreshInterceptor(refresher, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com", http.NoBody)
+	_, err := interceptor(req, func(_ *http.Request) (*http.Response, error) { return nil, sendErr })
+	if !errors.Is(err, sendErr) || refresher.callCount != 0 {
+		t.Errorf("expected error without refresh, got %v after %d refreshes", err, refresher.callCount)
+	}
+}
+
+func TestLoggingInterceptor(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLoggerWithWriter(VerbosityTrace, &buf)
+
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, "http://example.com/api/test", strings.NewReader(`{"name":"value"}`))
+	req.Header.Set(TraceIDHeader, "trace-123")
+
+	resp, err := LoggingInterceptor(logger)(req, func(req *http.Request) (*http.Response, error) {
+		body, _ := io.ReadAll(req.Body)
+		if string(body) != `{"name":"value"}` {
+			t.Errorf("expected body to survive logging, got %q", body)
+		}
+		return &http.Response{StatusCode: http.StatusOK, Status: "200 OK", Body: io.NopCloser(strings.NewReader(`{"ok":true}`))}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != `{"ok":true}` {
+		t.Errorf("expected response body to survive logging, got %q", body)
+	}
+
+	output := buf.String()
+	for _, want := range []string{"trace-123", `{"name":"value"}`, `{"ok":true}`} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected log to contain %s, got: %s", want, output)
+		}
+	}
+}
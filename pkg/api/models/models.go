@@ -536,6 +536,15 @@ type TeamMember struct {
 	Role      string `json:"role"`
 }
 
+// OrganizationMember represents a user's membership of an organization
+type OrganizationMember struct {
+	ID        string `json:"id"`
+	Email     string `json:"email"`
+	FirstName string `json:"firstname"`
+	LastName  string `json:"lastname"`
+	Role      string `json:"role"`
+}
+
 // SSHKey represents an SSH key
 type SSHKey struct {
 	ID  string `json:"id"`
@@ -637,6 +646,7 @@ type ConnectionInfo struct {
 	MySQLHost     string `json:"mysql_host"`
 	MySQLPort     int    `json:"mysql_port"`
 	MySQLUsername string `json:"mysql_username"`
+	MySQLPassword string `json:"mysql_password"`
 	MySQLDatabase string `json:"mysql_database"`
 	MySQLCommand  string `json:"mysql_command"`
 	RedisHost     string `json:"redis_host"`
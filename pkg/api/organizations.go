@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"iter"
 
 	"github.com/deviantintegral/terminus-golang/pkg/api/models"
 )
@@ -20,26 +21,37 @@ func NewOrganizationsService(client *Client) *OrganizationsService {
 
 // List returns all organizations for the authenticated user
 func (s *OrganizationsService) List(ctx context.Context, userID string) ([]*models.Organization, error) {
-	path := fmt.Sprintf("/users/%s/memberships/organizations", userID)
-
-	rawResults, err := s.client.GetPaged(ctx, path)
+	orgs, err := s.ListPager(userID).Collect(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list organizations: %w", err)
 	}
+	return orgs, nil
+}
 
-	orgs := make([]*models.Organization, 0, len(rawResults))
-	for _, raw := range rawResults {
+// ListPager returns a pager over the user's organizations, decoding one page at a time
+func (s *OrganizationsService) ListPager(userID string) *Pager[*models.Organization] {
+	path := fmt.Sprintf("/users/%s/memberships/organizations", userID)
+	return NewPager(s.client, path, func(raw json.RawMessage) (*models.Organization, bool, error) {
 		var membership struct {
 			Organization *models.Organization `json:"organization"`
 		}
 		if err := json.Unmarshal(raw, &membership); err != nil {
-			return nil, err
-		} else if membership.Organization != nil {
-			orgs = append(orgs, membership.Organization)
+			return nil, false, err
 		}
-	}
+		return membership.Organization, membership.Organization != nil, nil
+	})
+}
 
-	return orgs, nil
+// ListIter returns an iterator over the user's organizations, fetching pages
+// as they are consumed
+func (s *OrganizationsService) ListIter(ctx context.Context, userID string) iter.Seq2[*models.Organization, error] {
+	return s.ListPager(userID).All(ctx)
+}
+
+// Resolve converts an organization ID, name or label to an ID using the
+// client's shared resolver
+func (s *OrganizationsService) Resolve(ctx context.Context, userID, orgIdentifier string) (string, error) {
+	return s.client.Resolver().Organization(ctx, userID, orgIdentifier)
 }
 
 // Get returns a specific organization
@@ -60,30 +72,134 @@ func (s *OrganizationsService) Get(ctx context.Context, orgID string) (*models.O
 
 // ListMembers returns members of an organization
 func (s *OrganizationsService) ListMembers(ctx context.Context, orgID string) ([]*models.User, error) {
-	path := fmt.Sprintf("/organizations/%s/memberships/users", orgID)
-
-	rawResults, err := s.client.GetPaged(ctx, path)
+	members, err := s.ListMembersPager(orgID).Collect(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list organization members: %w", err)
 	}
+	return members, nil
+}
 
-	members := make([]*models.User, 0, len(rawResults))
-	for _, raw := range rawResults {
+// ListMembersIter returns an iterator over the members of an organization,
+// fetching pages as they are consumed
+func (s *OrganizationsService) ListMembersIter(ctx context.Context, orgID string) iter.Seq2[*models.User, error] {
+	return s.ListMembersPager(orgID).All(ctx)
+}
+
+// ListMembersPager returns a pager over the members of an organization,
+// decoding one page at a time
+func (s *OrganizationsService) ListMembersPager(orgID string) *Pager[*models.User] {
+	path := fmt.Sprintf("/organizations/%s/memberships/users", orgID)
+	return NewPager(s.client, path, func(raw json.RawMessage) (*models.User, bool, error) {
 		var membership struct {
 			User *models.User `json:"user"`
 			Role string       `json:"role"`
 		}
 		if err := json.Unmarshal(raw, &membership); err != nil {
-			return nil, fmt.Errorf("failed to decode member: %w", err)
-		}
-		if membership.User != nil {
-			members = append(members, membership.User)
+			return nil, false, fmt.Errorf("failed to decode member: %w", err)
 		}
-	}
+		return membership.User, membership.User != nil, nil
+	})
+}
 
+// ListMemberships returns the members of an organization with their roles
+func (s *OrganizationsService) ListMemberships(ctx context.Context, orgID string) ([]*models.OrganizationMember, error) {
+	path := fmt.Sprintf("/organizations/%s/memberships/users", orgID)
+	pager := NewPager(s.client, path, func(raw json.RawMessage) (*models.OrganizationMember, bool, error) {
+		member, err := decodeOrganizationMember(raw)
+		return member, member != nil, err
+	})
+
+	members, err := pager.Collect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organization members: %w", err)
+	}
 	return members, nil
 }
 
+// AddOrganizationMemberRequest represents a request to add an organization member
+type AddOrganizationMemberRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+// AddMember adds a user to an organization
+func (s *OrganizationsService) AddMember(ctx context.Context, orgID string, req *AddOrganizationMemberRequest) (*models.OrganizationMember, error) {
+	path := fmt.Sprintf("/organizations/%s/memberships/users", orgID)
+	resp, err := s.client.Post(ctx, path, req) //nolint:bodyclose // DecodeResponse closes body
+	if err != nil {
+		return nil, fmt.Errorf("failed to add organization member: %w", err)
+	}
+
+	var raw json.RawMessage
+	if err := DecodeResponse(resp, &raw); err != nil {
+		return nil, err
+	}
+
+	member, err := decodeOrganizationMember(raw)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		member = &models.OrganizationMember{Email: req.Email, Role: req.Role}
+	}
+	return member, nil
+}
+
+// RemoveMember removes a user from an organization
+func (s *OrganizationsService) RemoveMember(ctx context.Context, orgID, userID string) error {
+	path := fmt.Sprintf("/organizations/%s/memberships/users/%s", orgID, userID)
+	resp, err := s.client.Delete(ctx, path)
+	if err != nil {
+		return fmt.Errorf("failed to remove organization member: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("remove organization member failed with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// UpdateMemberRole changes the role of an organization member
+func (s *OrganizationsService) UpdateMemberRole(ctx context.Context, orgID, userID, role string) error {
+	path := fmt.Sprintf("/organizations/%s/memberships/users/%s", orgID, userID)
+	resp, err := s.client.Put(ctx, path, map[string]string{"role": role})
+	if err != nil {
+		return fmt.Errorf("failed to update organization member role: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("update organization member role failed with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// decodeOrganizationMember flattens a membership, which holds the user and
+// their role, into an OrganizationMember. It returns nil for memberships
+// without a user.
+func decodeOrganizationMember(raw json.RawMessage) (*models.OrganizationMember, error) {
+	var membership struct {
+		User *models.User `json:"user"`
+		Role string       `json:"role"`
+	}
+	if err := json.Unmarshal(raw, &membership); err != nil {
+		return nil, fmt.Errorf("failed to decode member: %w", err)
+	}
+	if membership.User == nil {
+		return nil, nil
+	}
+	return &models.OrganizationMember{
+		ID:        membership.User.ID,
+		Email:     membership.User.Email,
+		FirstName: membership.User.FirstName,
+		LastName:  membership.User.LastName,
+		Role:      membership.Role,
+	}, nil
+}
+
 // ListUpstreams returns upstreams for an organization
 func (s *OrganizationsService) ListUpstreams(ctx context.Context, orgID string) ([]*models.Upstream, error) {
 	path := fmt.Sprintf("/organizations/%s/upstreams", orgID)
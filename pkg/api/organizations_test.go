@@ -0,0 +1,144 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newOrganizationsTestService(t *testing.T, handler http.HandlerFunc) *OrganizationsService {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithToken("test-token"),
+		WithHTTPClient(&http.Client{Timeout: 5 * time.Second}),
+	)
+	return NewOrganizationsService(client)
+}
+
+func TestOrganizationsService_ListMemberships(t *testing.T) {
+	orgID := "org-123"
+
+	service := newOrganizationsTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		expectedPath := "/organizations/" + orgID + "/memberships/users"
+		if r.URL.Path != expectedPath {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]map[string]interface{}{
+			{"role": "admin", "user": map[string]interface{}{"id": "user1", "email": "admin@example.com"}},
+			{"role": "developer", "user": map[string]interface{}{"id": "user2", "email": "dev@example.com"}},
+		})
+	})
+
+	members, err := service.ListMemberships(context.Background(), orgID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(members) != 2 {
+		t.Fatalf("expected 2 members, got %d", len(members))
+	}
+
+	if members[1].ID != "user2" || members[1].Email != "dev@example.com" || members[1].Role != "developer" {
+		t.Errorf("unexpected member: %+v", members[1])
+	}
+}
+
+func TestOrganizationsService_AddMember(t *testing.T) {
+	orgID := "org-123"
+
+	service := newOrganizationsTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expected POST request, got %s", r.Method)
+		}
+
+		expectedPath := "/organizations/" + orgID + "/memberships/users"
+		if r.URL.Path != expectedPath {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+
+		var req AddOrganizationMemberRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"role": req.Role,
+			"user": map[string]interface{}{"id": "user123", "email": req.Email},
+		})
+	})
+
+	member, err := service.AddMember(context.Background(), orgID, &AddOrganizationMemberRequest{
+		Email: "new@example.com",
+		Role:  "developer",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if member.ID != "user123" || member.Email != "new@example.com" || member.Role != "developer" {
+		t.Errorf("unexpected member: %+v", member)
+	}
+}
+
+func TestOrganizationsService_RemoveMember(t *testing.T) {
+	orgID := "org-123"
+	userID := "user123"
+
+	service := newOrganizationsTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			t.Errorf("expected DELETE request, got %s", r.Method)
+		}
+
+		expectedPath := "/organizations/" + orgID + "/memberships/users/" + userID
+		if r.URL.Path != expectedPath {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+
+	if err := service.RemoveMember(context.Background(), orgID, userID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestOrganizationsService_UpdateMemberRole(t *testing.T) {
+	orgID := "org-123"
+	userID := "user123"
+
+	service := newOrganizationsTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			t.Errorf("expected PUT request, got %s", r.Method)
+		}
+
+		expectedPath := "/organizations/" + orgID + "/memberships/users/" + userID
+		if r.URL.Path != expectedPath {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if body["role"] != "admin" {
+			t.Errorf("expected role 'admin', got '%s'", body["role"])
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+
+	if err := service.UpdateMemberRole(context.Background(), orgID, userID, "admin"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
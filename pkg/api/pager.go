@@ -0,0 +1,164 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+)
+
+// DefaultPageSize is the number of items requested per page from cursor-paginated endpoints
+const DefaultPageSize = 100
+
+// PageDecoder decodes a single raw item from a page into a typed value.
+// Returning false for ok skips the item without treating it as an error.
+type PageDecoder[T any] func(raw json.RawMessage) (item T, ok bool, err error)
+
+// Pager walks a cursor-paginated endpoint one page at a time, decoding each
+// item as it arrives. The Pantheon API uses the ID of the last item of the
+// previous page as the 'start' cursor for the next one.
+//
+// A Pager is not safe for concurrent use.
+type Pager[T any] struct {
+	client   *Client
+	basePath string
+	limit    int
+	decode   PageDecoder[T]
+
+	cursor  string
+	seenIDs map[string]bool
+	done    bool
+}
+
+// NewPager creates a pager for the given endpoint using the supplied decoder
+func NewPager[T any](client *Client, basePath string, decode PageDecoder[T]) *Pager[T] {
+	return &Pager[T]{
+		client:   client,
+		basePath: basePath,
+		limit:    DefaultPageSize,
+		decode:   decode,
+		seenIDs:  make(map[string]bool),
+	}
+}
+
+// NewRawPager creates a pager that yields undecoded items
+func NewRawPager(client *Client, basePath string) *Pager[json.RawMessage] {
+	return NewPager(client, basePath, func(raw json.RawMessage) (json.RawMessage, bool, error) {
+		return raw, true, nil
+	})
+}
+
+// WithPageSize sets the number of items requested per page
+func (p *Pager[T]) WithPageSize(limit int) *Pager[T] {
+	if limit > 0 {
+		p.limit = limit
+	}
+	return p
+}
+
+// WithCursor resumes pagination after the item with the given ID, as
+// previously returned by Cursor
+func (p *Pager[T]) WithCursor(cursor string) *Pager[T] {
+	p.cursor = cursor
+	return p
+}
+
+// Cursor returns the ID of the last item yielded. Passing it to WithCursor on
+// a new pager resumes iteration immediately after that item.
+func (p *Pager[T]) Cursor() string {
+	return p.cursor
+}
+
+// Done reports whether the last page has been fetched
+func (p *Pager[T]) Done() bool {
+	return p.done
+}
+
+// All returns an iterator over every remaining item. Iteration stops at the
+// first error, which is yielded along with the zero value of T. Breaking out
+// of the loop early leaves the cursor at the last item received.
+func (p *Pager[T]) All(ctx context.Context) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		for !p.done {
+			raws, err := p.fetchPage(ctx)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+
+			for _, raw := range raws {
+				item, ok, err := p.decode(raw)
+				if err != nil {
+					yield(zero, err)
+					return
+				}
+				p.advance(raw)
+				if !ok {
+					continue
+				}
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Collect fetches every remaining item into a slice. The slice is empty
+// rather than nil when there are no items.
+func (p *Pager[T]) Collect(ctx context.Context) ([]T, error) {
+	items := make([]T, 0)
+	for item, err := range p.All(ctx) {
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// fetchPage requests the next page and returns its new items, marking the
+// pager done when pagination is complete
+func (p *Pager[T]) fetchPage(ctx context.Context) ([]json.RawMessage, error) {
+	path := buildPagedPath(p.basePath, p.limit, p.cursor)
+
+	resp, err := p.client.Get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	_ = resp.Body.Close()
+
+	var fresh []json.RawMessage
+	lastID, foundDuplicate := processPageResults(results, p.seenIDs, &fresh)
+
+	switch {
+	case len(results) == 0, foundDuplicate:
+		// An empty page or a repeated ID means pagination is complete
+		p.done = true
+	case len(results) < p.limit:
+		// A short page is the last one
+		p.done = true
+	case lastID == "":
+		// Without IDs we can't build the next cursor
+		p.done = true
+	}
+
+	return fresh, nil
+}
+
+// advance moves the cursor past a consumed item
+func (p *Pager[T]) advance(raw json.RawMessage) {
+	var item struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(raw, &item); err == nil && item.ID != "" {
+		p.cursor = item.ID
+	}
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newPagedTestServer serves total items with IDs item-000..item-N in pages,
+// honoring the limit and start parameters
+func newPagedTestServer(t *testing.T, total int, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	ids := make([]string, total)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("item-%03d", i)
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		start := r.URL.Query().Get("start")
+
+		offset := 0
+		if start != "" {
+			for i, id := range ids {
+				if id == start {
+					offset = i + 1
+					break
+				}
+			}
+		}
+
+		end := offset + limit
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		page := make([]map[string]string, 0, end-offset)
+		for _, id := range ids[offset:end] {
+			page = append(page, map[string]string{"id": id, "name": "name-" + id})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(page)
+	}))
+}
+
+type pagerTestItem struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func decodePagerTestItem(raw json.RawMessage) (*pagerTestItem, bool, error) {
+	var item pagerTestItem
+	if err := json.Unmarshal(raw, &item); err != nil {
+		return nil, false, err
+	}
+	return &item, true, nil
+}
+
+func TestPagerAll(t *testing.T) {
+	var requests atomic.Int32
+	server := newPagedTestServer(t, 25, &requests)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(&http.Client{Timeout: 5 * time.Second}))
+	pager := NewPager(client, "/items", decodePagerTestItem).WithPageSize(10)
+
+	count := 0
+	for item, err := range pager.All(context.Background()) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if expected := fmt.Sprintf("item-%03d", count); item.ID != expected {
+			t.Errorf("expected %s, got %s", expected, item.ID)
+		}
+		count++
+	}
+
+	if count != 25 {
+		t.Errorf("expected 25 items, got %d", count)
+	}
+	if requests.Load() != 3 {
+		t.Errorf("expected 3 page requests, got %d", requests.Load())
+	}
+	if !pager.Done() {
+		t.Error("expected pager to be done")
+	}
+}
+
+func TestPagerEarlyBreakAndResume(t *testing.T) {
+	var requests atomic.Int32
+	server := newPagedTestServer(t, 25, &requests)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(&http.Client{Timeout: 5 * time.Second}))
+	pager := NewPager(client, "/items", decodePagerTestItem).WithPageSize(10)
+
+	for item, err := range pager.All(context.Background()) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if item.ID == "item-004" {
+			break
+		}
+	}
+
+	if requests.Load() != 1 {
+		t.Errorf("expected only the first page to be fetched, got %d requests", requests.Load())
+	}
+	if pager.Cursor() != "item-004" {
+		t.Fatalf("expected cursor item-004, got %q", pager.Cursor())
+	}
+
+	resumed := NewPager(client, "/items", decodePagerTestItem).WithPageSize(10).WithCursor(pager.Cursor())
+	items, err := resumed.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 20 {
+		t.Fatalf("expected 20 remaining items, got %d", len(items))
+	}
+	if items[0].ID != "item-005" {
+		t.Errorf("expected resume at item-005, got %s", items[0].ID)
+	}
+}
+
+func TestPagerDecodeError(t *testing.T) {
+	var requests atomic.Int32
+	server := newPagedTestServer(t, 3, &requests)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(&http.Client{Timeout: 5 * time.Second}))
+	pager := NewPager(client, "/items", func(_ json.RawMessage) (string, bool, error) {
+		return "", false, fmt.Errorf("boom")
+	})
+
+	_, err := pager.Collect(context.Background())
+	if err == nil {
+		t.Fatal("expected decode error")
+	}
+}
+
+func TestPagerSkipsItems(t *testing.T) {
+	var requests atomic.Int32
+	server := newPagedTestServer(t, 6, &requests)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(&http.Client{Timeout: 5 * time.Second}))
+	index := 0
+	pager := NewPager(client, "/items", func(raw json.RawMessage) (json.RawMessage, bool, error) {
+		index++
+		return raw, index%2 == 0, nil
+	})
+
+	items, err := pager.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 3 {
+		t.Errorf("expected 3 items after skipping, got %d", len(items))
+	}
+}
+
+func TestPagerCollectEmpty(t *testing.T) {
+	var requests atomic.Int32
+	server := newPagedTestServer(t, 0, &requests)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(&http.Client{Timeout: 5 * time.Second}))
+	items, err := NewRawPager(client, "/items").Collect(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if items == nil || len(items) != 0 {
+		t.Errorf("expected an empty, non-nil slice, got %#v", items)
+	}
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RateLimiter is a token bucket limiter shared by all requests made through a Client.
+// Tokens are added at a fixed rate up to the burst size, and each request consumes one.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a limiter allowing requestsPerSecond sustained requests
+// with bursts of up to burst requests. A burst below one is treated as one.
+func NewRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:   requestsPerSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until a token is available or the context is done.
+// It returns the time spent waiting.
+func (l *RateLimiter) Wait(ctx context.Context) (time.Duration, error) {
+	delay := l.reserve(time.Now())
+	if delay <= 0 {
+		return 0, nil
+	}
+
+	start := time.Now()
+	if err := sleepContext(ctx, delay); err != nil {
+		// Hand the token back so cancelled callers don't slow down everyone else
+		l.mu.Lock()
+		l.tokens = math.Min(l.burst, l.tokens+1)
+		l.mu.Unlock()
+		return time.Since(start), err
+	}
+	return time.Since(start), nil
+}
+
+// reserve takes a token and returns how long the caller must wait before using it
+func (l *RateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rate <= 0 {
+		return 0
+	}
+
+	elapsed := now.Sub(l.last).Seconds()
+	if elapsed > 0 {
+		l.tokens = math.Min(l.burst, l.tokens+elapsed*l.rate)
+		l.last = now
+	}
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// ClientStats reports how much time a Client has spent throttling requests
+type ClientStats struct {
+	// Requests is the number of HTTP requests sent, including retries
+	Requests int64
+	// RateLimitWait is the total time spent waiting on the rate limiter
+	RateLimitWait time.Duration
+	// ConcurrencyWait is the total time spent waiting for an in-flight slot
+	ConcurrencyWait time.Duration
+}
+
+// clientStats holds the live counters behind ClientStats
+type clientStats struct {
+	requests        atomic.Int64
+	rateLimitWait   atomic.Int64
+	concurrencyWait atomic.Int64
+}
+
+// WithRateLimit limits the client to requestsPerSecond sustained requests with
+// bursts of up to burst requests. A rate of zero or less disables the limit.
+func WithRateLimit(requestsPerSecond float64, burst int) ClientOption {
+	return func(c *Client) {
+		if requestsPerSecond <= 0 {
+			c.rateLimiter = nil
+			return
+		}
+		c.rateLimiter = NewRateLimiter(requestsPerSecond, burst)
+	}
+}
+
+// WithMaxConcurrency limits the number of requests the client has in flight at
+// once. Zero or less means unlimited.
+func WithMaxConcurrency(n int) ClientOption {
+	return func(c *Client) {
+		if n <= 0 {
+			c.inFlight = nil
+			return
+		}
+		c.inFlight = make(chan struct{}, n)
+	}
+}
+
+// Stats returns a snapshot of the client's request and throttling counters
+func (c *Client) Stats() ClientStats {
+	return ClientStats{
+		Requests:        c.stats.requests.Load(),
+		RateLimitWait:   time.Duration(c.stats.rateLimitWait.Load()),
+		ConcurrencyWait: time.Duration(c.stats.concurrencyWait.Load()),
+	}
+}
+
+// send performs a single HTTP round trip, serving it from the response cache
+// when one is configured
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	if c.cache != nil {
+		return c.cache.Do(req, c.transmit)
+	}
+	return c.transmit(req)
+}
+
+// transmit performs a single HTTP request once the concurrency and rate limits allow it
+func (c *Client) transmit(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	if c.inFlight != nil {
+		start := time.Now()
+		select {
+		case c.inFlight <- struct{}{}:
+		case <-ctx.Done():
+			c.stats.concurrencyWait.Add(int64(time.Since(start)))
+			return nil, ctx.Err()
+		}
+		c.stats.concurrencyWait.Add(int64(time.Since(start)))
+		defer func() { <-c.inFlight }()
+	}
+
+	if c.rateLimiter != nil {
+		waited, err := c.rateLimiter.Wait(ctx)
+		c.stats.rateLimitWait.Add(int64(waited))
+		if err != nil {
+			return nil, err
+		}
+		if waited > 0 && c.logger != nil {
+			c.logger.Debug("Rate limiter delayed request by %v", waited)
+		}
+	}
+
+	c.stats.requests.Add(1)
+	return c.httpClient.Do(req)
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterReserve(t *testing.T) {
+	limiter := NewRateLimiter(10, 2)
+	now := limiter.last
+
+	// The initial burst is available immediately
+	if d := limiter.reserve(now); d != 0 {
+		t.Errorf("expected first token to be free, got %v", d)
+	}
+	if d := limiter.reserve(now); d != 0 {
+		t.Errorf("expected second token to be free, got %v", d)
+	}
+
+	// The third token must wait one refill interval (1/10s)
+	if d := limiter.reserve(now); d != 100*time.Millisecond {
+		t.Errorf("expected 100ms wait, got %v", d)
+	}
+
+	// After a full second the bucket refills to the burst size, less the debt
+	if d := limiter.reserve(now.Add(time.Second)); d != 0 {
+		t.Errorf("expected refilled token to be free, got %v", d)
+	}
+}
+
+func TestRateLimiterWaitCancelled(t *testing.T) {
+	limiter := NewRateLimiter(0.001, 1)
+	if _, err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := limiter.Wait(ctx); err == nil {
+		t.Fatal("expected context error")
+	}
+
+	// The cancelled reservation is returned so the bucket is not left in debt
+	limiter.mu.Lock()
+	tokens := limiter.tokens
+	limiter.mu.Unlock()
+	if tokens < -0.01 {
+		t.Errorf("expected cancelled token to be returned, bucket has %v", tokens)
+	}
+}
+
+func TestClientRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithHTTPClient(&http.Client{Timeout: 5 * time.Second}),
+		WithRateLimit(20, 1),
+	)
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		resp, err := client.Get(context.Background(), "/test")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_ = resp.Body.Close()
+	}
+
+	// 5 requests at 20/s with a burst of 1 need at least 4 refill intervals
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("expected requests to be throttled, took %v", elapsed)
+	}
+
+	stats := client.Stats()
+	if stats.Requests != 5 {
+		t.Errorf("expected 5 requests, got %d", stats.Requests)
+	}
+	if stats.RateLimitWait == 0 {
+		t.Error("expected rate limit wait time to be recorded")
+	}
+}
+
+func TestClientMaxConcurrency(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			seen := maxInFlight.Load()
+			if current <= seen || maxInFlight.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithHTTPClient(&http.Client{Timeout: 5 * time.Second}),
+		WithMaxConcurrency(2),
+	)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get(context.Background(), "/test")
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			_ = resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	if maxInFlight.Load() > 2 {
+		t.Errorf("expected at most 2 requests in flight, saw %d", maxInFlight.Load())
+	}
+	if client.Stats().ConcurrencyWait == 0 {
+		t.Error("expected concurrency wait time to be recorded")
+	}
+}
+
+func TestClientThrottlingDisabledByDefault(t *testing.T) {
+	client := NewClient()
+	if client.rateLimiter != nil {
+		t.Error("expected no rate limiter by default")
+	}
+	if client.inFlight != nil {
+		t.Error("expected no concurrency limit by default")
+	}
+
+	client = NewClient(WithRateLimit(0, 5), WithMaxConcurrency(0))
+	if client.rateLimiter != nil || client.inFlight != nil {
+		t.Error("expected zero values to disable throttling")
+	}
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrReadOnly is matched by errors for requests refused by read-only mode
+var ErrReadOnly = errors.New("blocked by read-only mode")
+
+// ReadOnlyError reports a request that read-only mode refused to send
+type ReadOnlyError struct {
+	Method string
+	Path   string
+}
+
+func (e *ReadOnlyError) Error() string {
+	return fmt.Sprintf("%s %s %s", e.Method, e.Path, ErrReadOnly)
+}
+
+// Is reports whether target is ErrReadOnly
+func (e *ReadOnlyError) Is(target error) bool { return target == ErrReadOnly }
+
+// readOnlyExempt lists the auth endpoints that may be called in read-only
+// mode, since logging in and renewing a session don't change the account
+var readOnlyExempt = []string{"/authorize/machine-token"}
+
+// WithReadOnly makes the client refuse every request that could change
+// something, before it is sent. See ReadOnlyInterceptor.
+func WithReadOnly(enabled bool) ClientOption {
+	return func(c *Client) {
+		c.readOnly = enabled
+	}
+}
+
+// ReadOnlyInterceptor refuses POST, PUT, PATCH and DELETE requests with a
+// *ReadOnlyError, except for the auth endpoints used by AuthService.Login and
+// SessionTokenRefresher
+func ReadOnlyInterceptor() Interceptor {
+	return func(req *http.Request, next Handler) (*http.Response, error) {
+		if !isMutating(req) {
+			return next(req)
+		}
+		return nil, &ReadOnlyError{Method: req.Method, Path: req.URL.Path}
+	}
+}
+
+// isMutating reports whether req could change something, ignoring the
+// readOnlyExempt auth endpoints
+func isMutating(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+
+	for _, path := range readOnlyExempt {
+		if strings.HasSuffix(req.URL.Path, path) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientReadOnly(t *testing.T) {
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method+" "+r.URL.Path)
+		_, _ = w.Write([]byte(`{"session": "token", "user_id": "user-1", "expires_at": 9999999999}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithRetryPolicy(nil), WithReadOnly(true))
+	ctx := context.Background()
+
+	resp, err := client.Get(ctx, "/sites/site-1")
+	if err != nil {
+		t.Fatalf("expected GET to be allowed: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		_, err := client.Request(ctx, method, "/sites/site-1/workflows", nil)
+		var readOnlyErr *ReadOnlyError
+		if !errors.As(err, &readOnlyErr) || !errors.Is(err, ErrReadOnly) {
+			t.Fatalf("expected %s to be blocked, got %v", method, err)
+		}
+		if readOnlyErr.Method != method {
+			t.Errorf("expected error to name %s, got %s", method, readOnlyErr.Method)
+		}
+	}
+
+	if _, err := NewAuthService(client).Login(ctx, "machine-token"); err != nil {
+		t.Errorf("expected login to be allowed: %v", err)
+	}
+
+	if len(methods) != 2 || methods[0] != "GET /sites/site-1" || methods[1] != "POST /authorize/machine-token" {
+		t.Errorf("expected only the GET and login to reach the server, got %v", methods)
+	}
+}
@@ -0,0 +1,365 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/deviantintegral/terminus-golang/pkg/api/models"
+)
+
+// siteIDPattern extracts the site UUID from a request path
+var siteIDPattern = regexp.MustCompile(`/sites/([0-9a-fA-F-]{36})`)
+
+// Resolver maps site, environment and organization names to their IDs.
+// Results are remembered for the life of the process and, when a file is
+// configured, persisted so later invocations can skip the lookups entirely.
+// Cached site names are forgotten when the API reports the site as not found.
+// Since a site can be renamed and its old name given to another site, a name
+// taken from the on-disk cache is looked up again before the site it points
+// at is changed, or when a request for it returns 404.
+type Resolver struct {
+	client *Client
+	file   string
+
+	mu     sync.Mutex
+	loaded bool
+	names  resolvedNames
+
+	// lookedUp holds the site names resolved through the API by this resolver
+	lookedUp map[string]bool
+	// unchecked maps site UUIDs taken from the on-disk cache to their cached
+	// names, until the name is looked up again
+	unchecked map[string]string
+}
+
+// resolvedNames is the on-disk representation of the resolver cache
+type resolvedNames struct {
+	// Sites maps site names to UUIDs
+	Sites map[string]string `json:"sites"`
+	// Environments maps site UUIDs to their environment IDs
+	Environments map[string][]string `json:"environments"`
+	// Organizations maps user IDs to a map of lowercased org names and labels to UUIDs
+	Organizations map[string]map[string]string `json:"organizations"`
+}
+
+// NewResolver creates a resolver that persists resolved names to file.
+// If file is empty, names are only remembered in memory.
+func NewResolver(client *Client, file string) *Resolver {
+	return &Resolver{
+		client:    client,
+		file:      file,
+		lookedUp:  make(map[string]bool),
+		unchecked: make(map[string]string),
+	}
+}
+
+// WithNameCache persists resolved site, environment and organization names to file
+func WithNameCache(file string) ClientOption {
+	return func(c *Client) {
+		c.nameCacheFile = file
+	}
+}
+
+// Resolver returns the name resolver shared by all services using this client
+func (c *Client) Resolver() *Resolver {
+	return c.resolver
+}
+
+// Site converts a site identifier (name or UUID) to a UUID
+func (r *Resolver) Site(ctx context.Context, siteIdentifier string) (string, error) {
+	if IsUUID(siteIdentifier) {
+		return siteIdentifier, nil
+	}
+
+	r.mu.Lock()
+	r.load()
+	siteID, ok := r.names.Sites[siteIdentifier]
+	if ok && !r.lookedUp[siteIdentifier] {
+		r.unchecked[siteID] = siteIdentifier
+	}
+	r.mu.Unlock()
+	if ok {
+		return siteID, nil
+	}
+
+	siteID, err := ResolveSiteNameToID(ctx, r.client, siteIdentifier)
+	if err != nil {
+		return "", err
+	}
+
+	r.mu.Lock()
+	r.load()
+	r.names.Sites[siteIdentifier] = siteID
+	r.lookedUp[siteIdentifier] = true
+	r.save()
+	r.mu.Unlock()
+
+	return siteID, nil
+}
+
+// Environment converts a site identifier and environment name to their IDs,
+// matching the environment case-insensitively. The site's environment list is
+// refetched when the environment isn't in the cached list, so new multidevs
+// are found.
+func (r *Resolver) Environment(ctx context.Context, siteIdentifier, envIdentifier string) (siteID, envID string, err error) {
+	siteID, err = r.Site(ctx, siteIdentifier)
+	if err != nil {
+		return "", "", err
+	}
+
+	r.mu.Lock()
+	r.load()
+	envID, ok := matchName(r.names.Environments[siteID], envIdentifier)
+	r.mu.Unlock()
+	if ok {
+		return siteID, envID, nil
+	}
+
+	envs, err := NewEnvironmentsService(r.client).List(ctx, siteID)
+	if err != nil {
+		return "", "", err
+	}
+
+	envIDs := make([]string, 0, len(envs))
+	for _, env := range envs {
+		envIDs = append(envIDs, env.ID)
+	}
+
+	r.mu.Lock()
+	r.load()
+	r.names.Environments[siteID] = envIDs
+	r.save()
+	r.mu.Unlock()
+
+	envID, ok = matchName(envIDs, envIdentifier)
+	if !ok {
+		return "", "", fmt.Errorf("environment not found: %s.%s", siteIdentifier, envIdentifier)
+	}
+	return siteID, envID, nil
+}
+
+// Organization converts an organization name, label or UUID to a UUID using
+// the organizations the user belongs to
+func (r *Resolver) Organization(ctx context.Context, userID, orgIdentifier string) (string, error) {
+	if IsUUID(orgIdentifier) {
+		return orgIdentifier, nil
+	}
+
+	key := strings.ToLower(orgIdentifier)
+
+	r.mu.Lock()
+	r.load()
+	orgID, ok := r.names.Organizations[userID][key]
+	r.mu.Unlock()
+	if ok {
+		return orgID, nil
+	}
+
+	orgs, err := NewOrganizationsService(r.client).List(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to list organizations: %w", err)
+	}
+
+	orgIDs := make(map[string]string, len(orgs))
+	for _, org := range orgs {
+		if org.Name != "" {
+			orgIDs[strings.ToLower(org.Name)] = org.ID
+		}
+		if org.Label != "" {
+			orgIDs[strings.ToLower(org.Label)] = org.ID
+		}
+	}
+
+	r.mu.Lock()
+	r.load()
+	r.names.Organizations[userID] = orgIDs
+	r.save()
+	r.mu.Unlock()
+
+	if orgID, ok := orgIDs[key]; ok {
+		return orgID, nil
+	}
+	return "", organizationNotFoundError(orgIdentifier, orgs)
+}
+
+// Forget removes any cached names for a site, given its name or UUID
+func (r *Resolver) Forget(siteIdentifier string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.load()
+
+	if r.forgetSite(siteIdentifier) {
+		r.save()
+	}
+}
+
+// Clear removes all cached names, including the on-disk copy
+func (r *Resolver) Clear() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.names = newResolvedNames()
+	r.loaded = true
+	r.lookedUp = make(map[string]bool)
+	r.unchecked = make(map[string]string)
+	if r.file == "" {
+		return nil
+	}
+	if err := os.Remove(r.file); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to clear name cache: %w", err)
+	}
+	return nil
+}
+
+// refresh looks up a site name from the on-disk cache again, if the site UUID
+// in path was found through one that hasn't been checked yet. It returns path
+// with the UUID the name now refers to, and whether that changed.
+func (r *Resolver) refresh(ctx context.Context, path string) (string, bool, error) {
+	match := siteIDPattern.FindStringSubmatch(path)
+	if match == nil {
+		return path, false, nil
+	}
+	cachedID := match[1]
+
+	r.mu.Lock()
+	name, ok := r.unchecked[cachedID]
+	if ok {
+		delete(r.unchecked, cachedID)
+		delete(r.names.Sites, name)
+	}
+	r.mu.Unlock()
+	if !ok {
+		return path, false, nil
+	}
+
+	siteID, err := r.Site(ctx, name)
+	if err != nil {
+		return "", false, err
+	}
+	if siteID == cachedID {
+		return path, false, nil
+	}
+	return strings.Replace(path, cachedID, siteID, 1), true, nil
+}
+
+// notFound is called when a request returns 404 so that cached names for the
+// site in the request path are looked up again next time
+func (r *Resolver) notFound(path string) {
+	match := siteIDPattern.FindStringSubmatch(path)
+	if match == nil {
+		return
+	}
+	r.Forget(match[1])
+}
+
+// forgetSite removes cached names for a site. The caller must hold r.mu.
+func (r *Resolver) forgetSite(siteIdentifier string) bool {
+	changed := false
+	for name, id := range r.names.Sites {
+		if name == siteIdentifier || id == siteIdentifier {
+			delete(r.names.Sites, name)
+			delete(r.names.Environments, id)
+			changed = true
+		}
+	}
+	if _, ok := r.names.Environments[siteIdentifier]; ok {
+		delete(r.names.Environments, siteIdentifier)
+		changed = true
+	}
+	return changed
+}
+
+// load reads the on-disk cache the first time it is needed. The caller must hold r.mu.
+func (r *Resolver) load() {
+	if r.loaded {
+		return
+	}
+	r.loaded = true
+
+	r.names = newResolvedNames()
+	if r.file != "" {
+		if data, err := os.ReadFile(r.file); err == nil {
+			var names resolvedNames
+			if json.Unmarshal(data, &names) == nil {
+				maps.Copy(r.names.Sites, names.Sites)
+				maps.Copy(r.names.Environments, names.Environments)
+				maps.Copy(r.names.Organizations, names.Organizations)
+			}
+		}
+	}
+}
+
+// newResolvedNames returns an empty cache
+func newResolvedNames() resolvedNames {
+	return resolvedNames{
+		Sites:         make(map[string]string),
+		Environments:  make(map[string][]string),
+		Organizations: make(map[string]map[string]string),
+	}
+}
+
+// save writes the cache to disk. Failures are ignored since the cache is only
+// an optimization. The caller must hold r.mu.
+func (r *Resolver) save() {
+	if r.file == "" {
+		return
+	}
+
+	data, err := json.Marshal(r.names)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(r.file), 0o700); err != nil {
+		return
+	}
+
+	// Write to a unique file and rename it into place, so that concurrent
+	// invocations never see or produce a partly written cache
+	tmp, err := os.CreateTemp(filepath.Dir(r.file), filepath.Base(r.file)+".*.tmp")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), r.file)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+}
+
+// matchName finds name in names, ignoring case
+func matchName(names []string, name string) (string, bool) {
+	for _, candidate := range names {
+		if strings.EqualFold(candidate, name) {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// organizationNotFoundError builds an error listing the organizations that are available
+func organizationNotFoundError(orgIdentifier string, orgs []*models.Organization) error {
+	var availableOrgs []string
+	for _, org := range orgs {
+		if org.Name != "" {
+			availableOrgs = append(availableOrgs, org.Name)
+		} else if org.Label != "" {
+			availableOrgs = append(availableOrgs, org.Label)
+		}
+	}
+	if len(availableOrgs) > 0 {
+		return fmt.Errorf("organization not found: %s (available: %s)", orgIdentifier, strings.Join(availableOrgs, ", "))
+	}
+	return fmt.Errorf("organization not found: %s", orgIdentifier)
+}
@@ -0,0 +1,391 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	resolverSiteID    = "11111111-1111-1111-1111-111111111111"
+	resolverNewSiteID = "22222222-2222-2222-2222-222222222222"
+	resolverOrgID     = "33333333-3333-3333-3333-333333333333"
+)
+
+// resolverTestServer is a fake API whose site name mapping can be changed
+// between requests, recording the paths it was asked for
+type resolverTestServer struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	siteNames map[string]string
+	sites     map[string]string // site ID -> name
+	envs      []string
+	paths     []string
+	deleted   []string
+}
+
+func newResolverTestServer() *resolverTestServer {
+	s := &resolverTestServer{
+		siteNames: map[string]string{"my-site": resolverSiteID},
+		sites:     map[string]string{resolverSiteID: "my-site"},
+		envs:      []string{"dev", "test", "live"},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+func (s *resolverTestServer) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paths = append(s.paths, r.URL.Path)
+
+	w.Header().Set("Content-Type", "application/json")
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	switch {
+	case r.Method == http.MethodDelete && len(parts) >= 2 && parts[0] == "sites":
+		if _, ok := s.sites[parts[1]]; ok {
+			s.deleted = append(s.deleted, r.URL.Path)
+			_, _ = w.Write([]byte(`{}`))
+			return
+		}
+	case len(parts) == 2 && parts[0] == "site-names":
+		if id, ok := s.siteNames[parts[1]]; ok {
+			_ = json.NewEncoder(w).Encode(map[string]string{"id": id})
+			return
+		}
+	case len(parts) == 2 && parts[0] == "sites":
+		if name, ok := s.sites[parts[1]]; ok {
+			_ = json.NewEncoder(w).Encode(map[string]string{"id": parts[1], "name": name})
+			return
+		}
+	case len(parts) == 3 && parts[0] == "sites" && parts[2] == "environments":
+		envs := make(map[string]map[string]string)
+		for _, env := range s.envs {
+			envs[env] = map[string]string{"id": env}
+		}
+		_ = json.NewEncoder(w).Encode(envs)
+		return
+	case len(parts) == 4 && parts[0] == "users" && parts[3] == "organizations":
+		_ = json.NewEncoder(w).Encode([]map[string]interface{}{
+			{"id": "m1", "organization": map[string]interface{}{
+				"id":      resolverOrgID,
+				"profile": map[string]string{"name": "Agency Inc", "machine_name": "agency"},
+			}},
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNotFound)
+	_, _ = w.Write([]byte(`{"error": "not found"}`))
+}
+
+func (s *resolverTestServer) count(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, p := range s.paths {
+		if p == path {
+			n++
+		}
+	}
+	return n
+}
+
+func TestResolverSiteMemoizesLookups(t *testing.T) {
+	server := newResolverTestServer()
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+	for i := 0; i < 3; i++ {
+		id, err := EnsureSiteUUID(context.Background(), client, "my-site")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if id != resolverSiteID {
+			t.Errorf("expected %s, got %s", resolverSiteID, id)
+		}
+	}
+
+	if n := server.count("/site-names/my-site"); n != 1 {
+		t.Errorf("expected 1 name lookup, got %d", n)
+	}
+}
+
+func TestResolverPersistsNamesToDisk(t *testing.T) {
+	server := newResolverTestServer()
+	defer server.Close()
+
+	file := filepath.Join(t.TempDir(), "names.json")
+
+	first := NewClient(WithBaseURL(server.URL), WithNameCache(file))
+	if _, err := first.Resolver().Site(context.Background(), "my-site"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	second := NewClient(WithBaseURL(server.URL), WithNameCache(file))
+	id, err := second.Resolver().Site(context.Background(), "my-site")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != resolverSiteID {
+		t.Errorf("expected %s, got %s", resolverSiteID, id)
+	}
+	if n := server.count("/site-names/my-site"); n != 1 {
+		t.Errorf("expected the second client to use the on-disk cache, got %d lookups", n)
+	}
+
+	if err := second.Resolver().Clear(); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if _, err := second.Resolver().Site(context.Background(), "my-site"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := server.count("/site-names/my-site"); n != 2 {
+		t.Errorf("expected a fresh lookup after Clear, got %d lookups", n)
+	}
+}
+
+func TestResolverRefreshesAfterNotFound(t *testing.T) {
+	server := newResolverTestServer()
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+	service := NewSitesService(client)
+
+	if _, err := service.Get(context.Background(), "my-site"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The site is deleted and a new one is created with the same name
+	server.mu.Lock()
+	delete(server.sites, resolverSiteID)
+	server.sites[resolverNewSiteID] = "my-site"
+	server.siteNames["my-site"] = resolverNewSiteID
+	server.mu.Unlock()
+
+	site, err := service.Get(context.Background(), "my-site")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if site.ID != resolverNewSiteID {
+		t.Errorf("expected the new site %s, got %s", resolverNewSiteID, site.ID)
+	}
+}
+
+func TestResolverRefreshesAfterRename(t *testing.T) {
+	server := newResolverTestServer()
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+	service := NewSitesService(client)
+
+	if _, err := service.Get(context.Background(), "my-site"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The site is renamed and another site takes over its old name
+	server.mu.Lock()
+	server.sites[resolverSiteID] = "renamed-site"
+	server.sites[resolverNewSiteID] = "my-site"
+	server.siteNames["my-site"] = resolverNewSiteID
+	server.mu.Unlock()
+
+	site, err := service.Get(context.Background(), "my-site")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if site.ID != resolverNewSiteID {
+		t.Errorf("expected the site now named my-site (%s), got %s", resolverNewSiteID, site.ID)
+	}
+}
+
+// cacheSiteName resolves my-site with a client using file as its name cache
+func cacheSiteName(t *testing.T, server *resolverTestServer, file string) {
+	t.Helper()
+
+	client := NewClient(WithBaseURL(server.URL), WithNameCache(file))
+	if _, err := client.Resolver().Site(context.Background(), "my-site"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestResolverChecksCachedNameBeforeChanges(t *testing.T) {
+	server := newResolverTestServer()
+	defer server.Close()
+
+	file := filepath.Join(t.TempDir(), "names.json")
+	cacheSiteName(t, server, file)
+
+	// The site is renamed and another site takes over its old name
+	server.mu.Lock()
+	server.sites[resolverSiteID] = "renamed-site"
+	server.sites[resolverNewSiteID] = "my-site"
+	server.siteNames["my-site"] = resolverNewSiteID
+	server.mu.Unlock()
+
+	client := NewClient(WithBaseURL(server.URL), WithNameCache(file))
+	ctx := context.Background()
+	siteID, err := EnsureSiteUUID(ctx, client, "my-site")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if siteID != resolverSiteID {
+		t.Fatalf("expected the cached ID for reads, got %s", siteID)
+	}
+
+	resp, err := client.Delete(ctx, "/sites/"+siteID+"/environments/dev")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	want := "/sites/" + resolverNewSiteID + "/environments/dev"
+	if len(server.deleted) != 1 || server.deleted[0] != want {
+		t.Errorf("expected only %s to be deleted, got %v", want, server.deleted)
+	}
+
+	// The name has been checked, so later changes don't look it up again
+	if id, _ := EnsureSiteUUID(ctx, client, "my-site"); id != resolverNewSiteID {
+		t.Errorf("expected the fresh ID, got %s", id)
+	}
+	resp, err = client.Delete(ctx, "/sites/"+resolverNewSiteID+"/environments/test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = resp.Body.Close()
+	if n := server.count("/site-names/my-site"); n != 2 {
+		t.Errorf("expected 2 name lookups, got %d", n)
+	}
+}
+
+func TestResolverRetriesCachedNameAfterNotFound(t *testing.T) {
+	server := newResolverTestServer()
+	defer server.Close()
+
+	file := filepath.Join(t.TempDir(), "names.json")
+	cacheSiteName(t, server, file)
+
+	// The site is deleted and a new one is created with the same name
+	server.mu.Lock()
+	delete(server.sites, resolverSiteID)
+	server.sites[resolverNewSiteID] = "my-site"
+	server.siteNames["my-site"] = resolverNewSiteID
+	server.mu.Unlock()
+
+	client := NewClient(WithBaseURL(server.URL), WithNameCache(file))
+	ctx := context.Background()
+	siteID, err := EnsureSiteUUID(ctx, client, "my-site")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resp, err := client.Get(ctx, "/sites/"+siteID)
+	if err != nil {
+		t.Fatalf("expected the request to be retried for the new site, got %v", err)
+	}
+	var site struct {
+		ID string `json:"id"`
+	}
+	if err := DecodeResponse(resp, &site); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if site.ID != resolverNewSiteID {
+		t.Errorf("expected %s, got %s", resolverNewSiteID, site.ID)
+	}
+}
+
+func TestResolverClearWhileResolving(t *testing.T) {
+	server := newResolverTestServer()
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithNameCache(filepath.Join(t.TempDir(), "names.json")))
+	resolver := client.Resolver()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, _, err := resolver.Environment(context.Background(), "my-site", "dev"); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if err := resolver.Clear(); err != nil {
+				t.Errorf("Clear failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestResolverEnvironment(t *testing.T) {
+	server := newResolverTestServer()
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+	resolver := client.Resolver()
+	envsPath := "/sites/" + resolverSiteID + "/environments"
+
+	siteID, envID, err := resolver.Environment(context.Background(), "my-site", "DEV")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if siteID != resolverSiteID || envID != "dev" {
+		t.Errorf("expected %s.dev, got %s.%s", resolverSiteID, siteID, envID)
+	}
+
+	if _, _, err := resolver.Environment(context.Background(), "my-site", "live"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := server.count(envsPath); n != 1 {
+		t.Errorf("expected environments to be listed once, got %d", n)
+	}
+
+	// A new multidev isn't in the cached list, so the list is refetched
+	server.mu.Lock()
+	server.envs = append(server.envs, "feature")
+	server.mu.Unlock()
+	if _, envID, err := resolver.Environment(context.Background(), "my-site", "feature"); err != nil || envID != "feature" {
+		t.Fatalf("expected feature environment, got %q (err %v)", envID, err)
+	}
+
+	if _, _, err := resolver.Environment(context.Background(), "my-site", "missing"); err == nil {
+		t.Error("expected error for unknown environment")
+	}
+}
+
+func TestResolverOrganization(t *testing.T) {
+	server := newResolverTestServer()
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+	resolver := client.Resolver()
+	orgsPath := "/users/user-1/memberships/organizations"
+
+	for _, name := range []string{"agency", "AGENCY", "agency inc", resolverOrgID} {
+		id, err := resolver.Organization(context.Background(), "user-1", name)
+		if err != nil {
+			t.Fatalf("unexpected error resolving %s: %v", name, err)
+		}
+		if id != resolverOrgID {
+			t.Errorf("expected %s for %s, got %s", resolverOrgID, name, id)
+		}
+	}
+	if n := server.count(orgsPath); n != 1 {
+		t.Errorf("expected organizations to be listed once, got %d", n)
+	}
+
+	_, err := resolver.Organization(context.Background(), "user-1", "unknown")
+	if err == nil || !strings.Contains(err.Error(), "available: agency") {
+		t.Errorf("expected not found error listing available orgs, got %v", err)
+	}
+}
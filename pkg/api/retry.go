@@ -0,0 +1,202 @@
+package api
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultMaxBackoff is the longest single delay the default retry policy will wait
+	DefaultMaxBackoff = 30 * time.Second
+
+	// DefaultMaxRetryElapsed caps the total time spent retrying a single request
+	DefaultMaxRetryElapsed = 2 * time.Minute
+
+	// DefaultRetryJitter is the fraction of each backoff that is randomized
+	DefaultRetryJitter = 0.2
+)
+
+// RetryAttempt describes the outcome of a single request attempt.
+// It is passed to a RetryPolicy to decide whether the request should be retried.
+type RetryAttempt struct {
+	// Request is the request that was sent
+	Request *http.Request
+	// Response is the response received, or nil if the request failed
+	Response *http.Response
+	// Err is the transport error, or nil if a response was received
+	Err error
+	// Attempt is the zero-based number of the attempt that just completed
+	Attempt int
+	// Elapsed is the total time spent on the request so far, including previous delays
+	Elapsed time.Duration
+}
+
+// RetryPolicy decides whether and when a failed request should be retried.
+// Implementations must be safe for concurrent use.
+type RetryPolicy interface {
+	// NextRetry returns how long to wait before retrying the request and
+	// whether a retry should happen at all.
+	NextRetry(attempt *RetryAttempt) (time.Duration, bool)
+}
+
+// DefaultRetryPolicy retries rate limited (429) and server error (5xx) responses
+// as well as transport errors using exponential backoff with jitter. It honors
+// the Retry-After header and never retries workflow-creating POSTs after a
+// response that may have been processed, unless RetryNonIdempotent is set.
+type DefaultRetryPolicy struct {
+	// MaxRetries is the maximum number of retries after the first attempt
+	MaxRetries int
+	// InitialBackoff is the delay before the first retry
+	InitialBackoff time.Duration
+	// MaxBackoff caps any single delay, including one requested by Retry-After
+	MaxBackoff time.Duration
+	// MaxElapsed caps the total time spent on a request including retries.
+	// Zero disables the cap.
+	MaxElapsed time.Duration
+	// Jitter is the fraction (0-1) of each computed backoff that is randomized
+	Jitter float64
+	// RetryNonIdempotent allows retrying POST requests that create workflows
+	// after a server error or transport failure. Such retries may start the
+	// same workflow twice.
+	RetryNonIdempotent bool
+}
+
+// NewDefaultRetryPolicy creates a retry policy with the package defaults
+func NewDefaultRetryPolicy() *DefaultRetryPolicy {
+	return &DefaultRetryPolicy{
+		MaxRetries:     MaxRetries,
+		InitialBackoff: InitialBackoff,
+		MaxBackoff:     DefaultMaxBackoff,
+		MaxElapsed:     DefaultMaxRetryElapsed,
+		Jitter:         DefaultRetryJitter,
+	}
+}
+
+// NextRetry implements the RetryPolicy interface
+func (p *DefaultRetryPolicy) NextRetry(attempt *RetryAttempt) (time.Duration, bool) {
+	if attempt.Attempt >= p.MaxRetries {
+		return 0, false
+	}
+
+	if attempt.Request != nil && attempt.Request.Context().Err() != nil {
+		return 0, false
+	}
+
+	rateLimited := attempt.Err == nil && attempt.Response != nil &&
+		attempt.Response.StatusCode == http.StatusTooManyRequests
+
+	switch {
+	case attempt.Err != nil:
+	case attempt.Response != nil && shouldRetry(attempt.Response.StatusCode):
+	default:
+		return 0, false
+	}
+
+	// A 429 means the request was rejected before it was processed, so it is
+	// always safe to retry. Anything else may have started a workflow.
+	if !rateLimited && !p.RetryNonIdempotent && isWorkflowCreation(attempt.Request) {
+		return 0, false
+	}
+
+	delay := p.backoff(attempt.Attempt)
+	if attempt.Response != nil {
+		if retryAfter, ok := ParseRetryAfter(attempt.Response.Header.Get("Retry-After"), time.Now()); ok {
+			delay = retryAfter
+		}
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+
+	if p.MaxElapsed > 0 && attempt.Elapsed+delay > p.MaxElapsed {
+		return 0, false
+	}
+
+	return delay, true
+}
+
+// backoff computes the jittered exponential backoff for an attempt
+func (p *DefaultRetryPolicy) backoff(attempt int) time.Duration {
+	backoff := time.Duration(math.Pow(2, float64(attempt))) * p.InitialBackoff
+	if p.Jitter <= 0 {
+		return backoff
+	}
+
+	jitter := math.Min(p.Jitter, 1)
+	// Scale the backoff into [1-jitter, 1+jitter) of its nominal value
+	factor := 1 - jitter + 2*jitter*rand.Float64() //nolint:gosec // Jitter does not need a secure source
+	return time.Duration(float64(backoff) * factor)
+}
+
+// NoRetryPolicy never retries requests
+type NoRetryPolicy struct{}
+
+// NextRetry implements the RetryPolicy interface
+func (NoRetryPolicy) NextRetry(_ *RetryAttempt) (time.Duration, bool) {
+	return 0, false
+}
+
+// ParseRetryAfter parses the value of a Retry-After header, which may be
+// either a number of seconds or an HTTP date. It returns false if the header
+// is empty or malformed. Dates in the past yield a zero duration.
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	delay := date.Sub(now)
+	if delay < 0 {
+		delay = 0
+	}
+	return delay, true
+}
+
+// workflowCreationPattern matches endpoints that start workflows when POSTed to.
+// Multidev creation posts to the environments collection rather than a workflows path.
+var workflowCreationPattern = regexp.MustCompile(`/(workflows|sites/[^/]+/environments)/?$`)
+
+// isWorkflowCreation reports whether a request starts a workflow and therefore
+// must not be blindly repeated
+func isWorkflowCreation(req *http.Request) bool {
+	if req == nil || req.Method != http.MethodPost || req.URL == nil {
+		return false
+	}
+	return workflowCreationPattern.MatchString(req.URL.Path)
+}
+
+// sleepContext waits for the given duration or until the context is done,
+// returning the context error in the latter case
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,310 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{"empty", "", 0, false},
+		{"seconds", "120", 120 * time.Second, true},
+		{"seconds with whitespace", " 5 ", 5 * time.Second, true},
+		{"negative seconds", "-1", 0, false},
+		{"http date", now.Add(30 * time.Second).Format(http.TimeFormat), 30 * time.Second, true},
+		{"http date in the past", now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		{"garbage", "soon", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseRetryAfter(tt.value, now)
+			if ok != tt.ok {
+				t.Fatalf("ParseRetryAfter(%q) ok = %v, expected %v", tt.value, ok, tt.ok)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseRetryAfter(%q) = %v, expected %v", tt.value, got, tt.expected)
+			}
+		})
+	}
+}
+
+func newRetryTestRequest(t *testing.T, method, path string) *http.Request {
+	t.Helper()
+	req, err := http.NewRequestWithContext(context.Background(), method, "https://example.com/api"+path, http.NoBody)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	return req
+}
+
+func newRetryTestResponse(status int, retryAfter string) *http.Response {
+	resp := &http.Response{StatusCode: status, Header: make(http.Header)}
+	if retryAfter != "" {
+		resp.Header.Set("Retry-After", retryAfter)
+	}
+	return resp
+}
+
+func TestDefaultRetryPolicy_NextRetry(t *testing.T) {
+	policy := &DefaultRetryPolicy{
+		MaxRetries:     3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+		MaxElapsed:     time.Minute,
+	}
+
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		resp          *http.Response
+		err           error
+		attempt       int
+		elapsed       time.Duration
+		expectRetry   bool
+		expectedDelay time.Duration
+	}{
+		{
+			name:          "server error uses exponential backoff",
+			method:        http.MethodGet,
+			path:          "/sites/abc",
+			resp:          newRetryTestResponse(http.StatusBadGateway, ""),
+			attempt:       2,
+			expectRetry:   true,
+			expectedDelay: 400 * time.Millisecond,
+		},
+		{
+			name:          "retry after header wins over backoff",
+			method:        http.MethodGet,
+			path:          "/sites/abc",
+			resp:          newRetryTestResponse(http.StatusTooManyRequests, "3"),
+			expectRetry:   true,
+			expectedDelay: 3 * time.Second,
+		},
+		{
+			name:          "retry after is capped by max backoff",
+			method:        http.MethodGet,
+			path:          "/sites/abc",
+			resp:          newRetryTestResponse(http.StatusTooManyRequests, "3600"),
+			expectRetry:   true,
+			expectedDelay: 10 * time.Second,
+		},
+		{
+			name:        "client errors are not retried",
+			method:      http.MethodGet,
+			path:        "/sites/abc",
+			resp:        newRetryTestResponse(http.StatusBadRequest, ""),
+			expectRetry: false,
+		},
+		{
+			name:        "max retries reached",
+			method:      http.MethodGet,
+			path:        "/sites/abc",
+			resp:        newRetryTestResponse(http.StatusInternalServerError, ""),
+			attempt:     3,
+			expectRetry: false,
+		},
+		{
+			name:        "elapsed cap reached",
+			method:      http.MethodGet,
+			path:        "/sites/abc",
+			resp:        newRetryTestResponse(http.StatusInternalServerError, ""),
+			elapsed:     time.Minute,
+			expectRetry: false,
+		},
+		{
+			name:          "transport errors are retried",
+			method:        http.MethodGet,
+			path:          "/sites/abc",
+			err:           errors.New("connection reset"),
+			expectRetry:   true,
+			expectedDelay: 100 * time.Millisecond,
+		},
+		{
+			name:        "workflow creation is not retried after a server error",
+			method:      http.MethodPost,
+			path:        "/sites/abc/environments/dev/workflows",
+			resp:        newRetryTestResponse(http.StatusInternalServerError, ""),
+			expectRetry: false,
+		},
+		{
+			name:        "multidev creation is not retried after a transport error",
+			method:      http.MethodPost,
+			path:        "/sites/abc/environments",
+			err:         errors.New("connection reset"),
+			expectRetry: false,
+		},
+		{
+			name:          "workflow creation is retried when rate limited",
+			method:        http.MethodPost,
+			path:          "/sites/abc/workflows",
+			resp:          newRetryTestResponse(http.StatusTooManyRequests, "1"),
+			expectRetry:   true,
+			expectedDelay: time.Second,
+		},
+		{
+			name:          "other POSTs are retried",
+			method:        http.MethodPost,
+			path:          "/sites/abc/memberships/users",
+			resp:          newRetryTestResponse(http.StatusServiceUnavailable, ""),
+			expectRetry:   true,
+			expectedDelay: 100 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delay, retry := policy.NextRetry(&RetryAttempt{
+				Request:  newRetryTestRequest(t, tt.method, tt.path),
+				Response: tt.resp,
+				Err:      tt.err,
+				Attempt:  tt.attempt,
+				Elapsed:  tt.elapsed,
+			})
+			if retry != tt.expectRetry {
+				t.Fatalf("expected retry=%v, got %v", tt.expectRetry, retry)
+			}
+			if retry && delay != tt.expectedDelay {
+				t.Errorf("expected delay %v, got %v", tt.expectedDelay, delay)
+			}
+		})
+	}
+}
+
+func TestDefaultRetryPolicy_RetryNonIdempotent(t *testing.T) {
+	policy := NewDefaultRetryPolicy()
+	policy.RetryNonIdempotent = true
+
+	_, retry := policy.NextRetry(&RetryAttempt{
+		Request:  newRetryTestRequest(t, http.MethodPost, "/sites/abc/workflows"),
+		Response: newRetryTestResponse(http.StatusInternalServerError, ""),
+	})
+	if !retry {
+		t.Error("expected workflow creation to be retried when opted in")
+	}
+}
+
+func TestDefaultRetryPolicy_Jitter(t *testing.T) {
+	policy := &DefaultRetryPolicy{
+		MaxRetries:     5,
+		InitialBackoff: time.Second,
+		Jitter:         0.5,
+	}
+
+	for i := 0; i < 50; i++ {
+		delay, retry := policy.NextRetry(&RetryAttempt{
+			Request:  newRetryTestRequest(t, http.MethodGet, "/sites"),
+			Response: newRetryTestResponse(http.StatusInternalServerError, ""),
+		})
+		if !retry {
+			t.Fatal("expected retry")
+		}
+		if delay < 500*time.Millisecond || delay >= 1500*time.Millisecond {
+			t.Fatalf("delay %v outside of jitter range", delay)
+		}
+	}
+}
+
+func TestClientRetryHonorsRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithHTTPClient(&http.Client{Timeout: 5 * time.Second}),
+	)
+
+	start := time.Now()
+	resp, err := client.Get(context.Background(), "/test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if calls.Load() != 2 {
+		t.Errorf("expected 2 calls, got %d", calls.Load())
+	}
+	// Retry-After: 0 should override the default one second backoff
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected Retry-After to shorten the delay, took %v", elapsed)
+	}
+}
+
+func TestClientRetryStopsOnContextCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithHTTPClient(&http.Client{Timeout: 5 * time.Second}),
+		WithRetryPolicy(&DefaultRetryPolicy{MaxRetries: 5, InitialBackoff: time.Minute}),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	resp, err := client.Get(ctx, "/test")
+	if resp != nil {
+		_ = resp.Body.Close()
+	}
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context deadline error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected retry sleep to be interrupted, took %v", elapsed)
+	}
+}
+
+func TestClientWithNilRetryPolicyDisablesRetries(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithHTTPClient(&http.Client{Timeout: 5 * time.Second}),
+		WithRetryPolicy(nil),
+	)
+
+	resp, err := client.Get(context.Background(), "/test")
+	if resp != nil {
+		_ = resp.Body.Close()
+	}
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected a single call, got %d", calls.Load())
+	}
+}
@@ -0,0 +1,192 @@
+package api
+
+import (
+	"context"
+	"iter"
+
+	"github.com/deviantintegral/terminus-golang/pkg/api/models"
+)
+
+// The interfaces below describe each service so that callers can substitute
+// fakes in tests; pkg/api/apitest provides in-memory implementations. Pager
+// methods are only available on the concrete services since a Pager is tied
+// to a Client; use the Iter methods to stream results through an interface.
+
+// Auth authenticates with the Pantheon API
+type Auth interface {
+	Login(ctx context.Context, machineToken string) (*SessionResponse, error)
+	Whoami(ctx context.Context, userID string) (*models.User, error)
+	ValidateSession(ctx context.Context, userID string) (bool, error)
+}
+
+// Backups manages environment backups
+type Backups interface {
+	List(ctx context.Context, siteID, envID string) ([]*models.Backup, error)
+	Get(ctx context.Context, siteID, envID, backupID string) (*models.Backup, error)
+	Create(ctx context.Context, siteID, envID string, req *CreateBackupRequest) (*models.Workflow, error)
+	CreateElement(ctx context.Context, siteID, envID, element string) (*models.Workflow, error)
+	GetDownloadURL(ctx context.Context, siteID, envID, backupID, element string) (string, error)
+	Download(ctx context.Context, siteID, envID, backupID, element, outputPath string) error
+	Restore(ctx context.Context, siteID, envID, backupID string) (*models.Workflow, error)
+	GetSchedule(ctx context.Context, siteID, envID string) (map[string]interface{}, error)
+	SetSchedule(ctx context.Context, siteID, envID string, enabled bool, day int) error
+}
+
+// Domains manages the domains attached to environments
+type Domains interface {
+	List(ctx context.Context, siteID, envID string) ([]*models.Domain, error)
+	Get(ctx context.Context, siteID, envID, domainID string) (*models.Domain, error)
+	Add(ctx context.Context, siteID, envID, domain string) (*models.Domain, error)
+	Remove(ctx context.Context, siteID, envID, domainID string) error
+	GetDNS(ctx context.Context, siteID, envID, domainID string) ([]*models.DNSRecord, error)
+}
+
+// Environments manages site environments
+type Environments interface {
+	List(ctx context.Context, siteIdentifier string) ([]*models.Environment, error)
+	ListForSites(ctx context.Context, siteIdentifiers []string, workers int) []Result[[]*models.Environment]
+	Get(ctx context.Context, siteID, envID string) (*models.Environment, error)
+	ClearCache(ctx context.Context, siteID, envID string) (*models.Workflow, error)
+	Deploy(ctx context.Context, siteID, envID string, req *DeployRequest) (*models.Workflow, error)
+	CloneContent(ctx context.Context, siteID, envID string, req *CloneContentRequest) (*models.Workflow, error)
+	ChangeConnectionMode(ctx context.Context, siteID, envID, mode string) (*models.Workflow, error)
+	Commit(ctx context.Context, siteID, envID string, req *CommitRequest) (*models.Workflow, error)
+	Wipe(ctx context.Context, siteID, envID string) (*models.Workflow, error)
+	GetConnectionInfo(ctx context.Context, siteID, envID string) (*models.ConnectionInfo, error)
+	Wake(ctx context.Context, siteID, envID string) error
+	GetUpstreamUpdates(ctx context.Context, siteID, envID string) (*models.UpstreamUpdate, error)
+	ApplyUpstreamUpdates(ctx context.Context, siteID, envID string, updateDB, acceptUpstream bool) (*models.Workflow, error)
+	GetLock(ctx context.Context, siteID, envID string) (*models.Lock, error)
+	SetLock(ctx context.Context, siteID, envID, username, password string) error
+	RemoveLock(ctx context.Context, siteID, envID string) error
+	GetMetrics(ctx context.Context, siteIdentifier, envID, duration string) ([]*models.Metrics, error)
+}
+
+// Multidev manages multidev environments
+type Multidev interface {
+	Create(ctx context.Context, siteID, envName, fromEnv string) (*models.Workflow, error)
+	Delete(ctx context.Context, siteID, envID string, deleteBranch bool) (*models.Workflow, error)
+	MergeToDev(ctx context.Context, siteID, envID string, updateDB bool) (*models.Workflow, error)
+	MergeFromDev(ctx context.Context, siteID, envID string, updateDB bool) (*models.Workflow, error)
+	List(ctx context.Context, siteID string) ([]*models.Environment, error)
+}
+
+// Organizations reads organizations and their members
+type Organizations interface {
+	List(ctx context.Context, userID string) ([]*models.Organization, error)
+	ListIter(ctx context.Context, userID string) iter.Seq2[*models.Organization, error]
+	Resolve(ctx context.Context, userID, orgIdentifier string) (string, error)
+	Get(ctx context.Context, orgID string) (*models.Organization, error)
+	ListMembers(ctx context.Context, orgID string) ([]*models.User, error)
+	ListMembersIter(ctx context.Context, orgID string) iter.Seq2[*models.User, error]
+	ListMemberships(ctx context.Context, orgID string) ([]*models.OrganizationMember, error)
+	AddMember(ctx context.Context, orgID string, req *AddOrganizationMemberRequest) (*models.OrganizationMember, error)
+	RemoveMember(ctx context.Context, orgID, userID string) error
+	UpdateMemberRole(ctx context.Context, orgID, userID, role string) error
+	ListUpstreams(ctx context.Context, orgID string) ([]*models.Upstream, error)
+}
+
+// Redis enables and disables Redis for sites
+type Redis interface {
+	Enable(ctx context.Context, siteID string) (*models.Workflow, error)
+	Disable(ctx context.Context, siteID string) (*models.Workflow, error)
+}
+
+// Sites manages sites and their teams, tags and plans
+type Sites interface {
+	List(ctx context.Context, userID string) ([]*models.Site, error)
+	ListIter(ctx context.Context, userID string) iter.Seq2[*models.Site, error]
+	Get(ctx context.Context, siteIdentifier string) (*models.Site, error)
+	Create(ctx context.Context, userID string, req *CreateSiteRequest) (*models.Site, error)
+	Delete(ctx context.Context, siteIdentifier string) error
+	Update(ctx context.Context, siteIdentifier string, req *UpdateRequest) (*models.Site, error)
+	ListByOrganization(ctx context.Context, orgID string) ([]*models.Site, error)
+	ListByOrganizationIter(ctx context.Context, orgID string) iter.Seq2[*models.Site, error]
+	GetTeam(ctx context.Context, siteIdentifier string) ([]*models.TeamMember, error)
+	AddTeamMember(ctx context.Context, siteIdentifier string, req *AddTeamMemberRequest) (*models.TeamMember, error)
+	RemoveTeamMember(ctx context.Context, siteIdentifier, userID string) error
+	UpdateTeamMemberRole(ctx context.Context, siteIdentifier, userID, role string) error
+	GetTags(ctx context.Context, siteIdentifier, orgID string) ([]*models.Tag, error)
+	AddTag(ctx context.Context, siteIdentifier, orgID, tagName string) error
+	RemoveTag(ctx context.Context, siteIdentifier, orgID, tagName string) error
+	GetPlan(ctx context.Context, siteIdentifier string) (*models.Plan, error)
+	ListBranches(ctx context.Context, siteIdentifier string) ([]*models.Branch, error)
+	GetPlans(ctx context.Context, siteIdentifier string) ([]*models.Plan, error)
+	ListOrganizations(ctx context.Context, siteIdentifier string) ([]*models.SiteOrganizationMembership, error)
+}
+
+// Upstreams reads upstreams and pending upstream updates
+type Upstreams interface {
+	ResolveToID(ctx context.Context, upstreamIdentifier, userID string) (string, error)
+	Get(ctx context.Context, upstreamID string) (*models.Upstream, error)
+	List(ctx context.Context, userID string) ([]*models.Upstream, error)
+	ListUpdates(ctx context.Context, siteID, envID string) ([]*models.UpstreamUpdateCommit, error)
+}
+
+// Users reads a user's machine tokens, SSH keys and payment methods
+type Users interface {
+	ListMachineTokens(ctx context.Context, userID string) ([]*models.MachineToken, error)
+	ListSSHKeys(ctx context.Context, userID string) ([]*models.SSHKey, error)
+	ListPaymentMethods(ctx context.Context, userID string) ([]*models.PaymentMethod, error)
+}
+
+// Workflows starts, inspects and waits for workflows
+type Workflows interface {
+	List(ctx context.Context, siteID string) ([]*models.Workflow, error)
+	ListForEnvironment(ctx context.Context, siteID, envID string) ([]*models.Workflow, error)
+	Get(ctx context.Context, siteID, workflowID string) (*models.Workflow, error)
+	Wait(ctx context.Context, siteID, workflowID string, opts *WaitOptions) (*models.Workflow, error)
+	Watch(ctx context.Context, siteID, workflowID string, opts *WatchOptions) error
+	CreateForUser(ctx context.Context, userID, workflowType string, params map[string]interface{}) (*models.Workflow, error)
+	GetForUser(ctx context.Context, userID, workflowID string) (*models.Workflow, error)
+	WaitForUser(ctx context.Context, userID, workflowID string, opts *WaitOptions) (*models.Workflow, error)
+	CreateForSite(ctx context.Context, siteID, workflowType string, params map[string]interface{}) (*models.Workflow, error)
+}
+
+// Compile-time checks that the HTTP services implement the interfaces
+var (
+	_ Auth          = (*AuthService)(nil)
+	_ Backups       = (*BackupsService)(nil)
+	_ Domains       = (*DomainsService)(nil)
+	_ Environments  = (*EnvironmentsService)(nil)
+	_ Multidev      = (*MultidevService)(nil)
+	_ Organizations = (*OrganizationsService)(nil)
+	_ Redis         = (*RedisService)(nil)
+	_ Sites         = (*SitesService)(nil)
+	_ Upstreams     = (*UpstreamsService)(nil)
+	_ Users         = (*UsersService)(nil)
+	_ Workflows     = (*WorkflowsService)(nil)
+)
+
+// Pantheon groups every API service behind its interface. Use NewPantheon for
+// the HTTP implementation, or build one from fakes in tests.
+type Pantheon struct {
+	Auth          Auth
+	Backups       Backups
+	Domains       Domains
+	Environments  Environments
+	Multidev      Multidev
+	Organizations Organizations
+	Redis         Redis
+	Sites         Sites
+	Upstreams     Upstreams
+	Users         Users
+	Workflows     Workflows
+}
+
+// NewPantheon creates a Pantheon backed by the HTTP services for client
+func NewPantheon(client *Client) *Pantheon {
+	return &Pantheon{
+		Auth:          NewAuthService(client),
+		Backups:       NewBackupsService(client),
+		Domains:       NewDomainsService(client),
+		Environments:  NewEnvironmentsService(client),
+		Multidev:      NewMultidevService(client),
+		Organizations: NewOrganizationsService(client),
+		Redis:         NewRedisService(client),
+		Sites:         NewSitesService(client),
+		Upstreams:     NewUpstreamsService(client),
+		Users:         NewUsersService(client),
+		Workflows:     NewWorkflowsService(client),
+	}
+}
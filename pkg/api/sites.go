@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"iter"
 
 	"github.com/deviantintegral/terminus-golang/pkg/api/models"
 )
@@ -37,12 +38,10 @@ func ResolveSiteNameToID(ctx context.Context, client *Client, siteName string) (
 	return result.ID, nil
 }
 
-// EnsureSiteUUID converts a site identifier (name or UUID) to a UUID
+// EnsureSiteUUID converts a site identifier (name or UUID) to a UUID using
+// the client's shared resolver
 func EnsureSiteUUID(ctx context.Context, client *Client, siteIdentifier string) (string, error) {
-	if IsUUID(siteIdentifier) {
-		return siteIdentifier, nil
-	}
-	return ResolveSiteNameToID(ctx, client, siteIdentifier)
+	return client.Resolver().Site(ctx, siteIdentifier)
 }
 
 // SitesService handles site-related operations
@@ -57,38 +56,47 @@ func NewSitesService(client *Client) *SitesService {
 
 // List returns all sites accessible to the authenticated user
 func (s *SitesService) List(ctx context.Context, userID string) ([]*models.Site, error) {
+	return s.ListPager(userID).Collect(ctx)
+}
+
+// ListPager returns a pager over the sites the user is a direct member of,
+// decoding one page at a time
+func (s *SitesService) ListPager(userID string) *Pager[*models.Site] {
 	// Get user sites using memberships endpoint
 	path := fmt.Sprintf("/users/%s/memberships/sites", userID)
+	return NewPager(s.client, path, decodeUserSiteMembership)
+}
 
-	rawResults, err := s.client.GetPaged(ctx, path)
-	if err != nil {
-		return nil, err
-	}
+// ListIter returns an iterator over the sites the user is a direct member of,
+// fetching pages as they are consumed
+func (s *SitesService) ListIter(ctx context.Context, userID string) iter.Seq2[*models.Site, error] {
+	return s.ListPager(userID).All(ctx)
+}
 
-	sites := make([]*models.Site, 0, len(rawResults))
-	for _, raw := range rawResults {
-		var membership struct {
-			Site   *models.Site `json:"site"`
-			UserID string       `json:"user_id"`
-			Role   string       `json:"role"`
-		}
-		if err := json.Unmarshal(raw, &membership); err != nil {
-			// Try direct unmarshal in case the API returns sites directly
-			var site models.Site
-			if err := json.Unmarshal(raw, &site); err != nil {
-				return nil, fmt.Errorf("failed to decode site: %w", err)
-			}
-			sites = append(sites, &site)
-		} else if membership.Site != nil {
-			// Populate membership information
-			membership.Site.MembershipUserID = membership.UserID
-			membership.Site.MembershipRole = membership.Role
-			membership.Site.MembershipIsTeam = true // Direct user membership
-			sites = append(sites, membership.Site)
+// decodeUserSiteMembership decodes an entry from a user's site memberships
+func decodeUserSiteMembership(raw json.RawMessage) (*models.Site, bool, error) {
+	var membership struct {
+		Site   *models.Site `json:"site"`
+		UserID string       `json:"user_id"`
+		Role   string       `json:"role"`
+	}
+	if err := json.Unmarshal(raw, &membership); err != nil {
+		// Try direct unmarshal in case the API returns sites directly
+		var site models.Site
+		if err := json.Unmarshal(raw, &site); err != nil {
+			return nil, false, fmt.Errorf("failed to decode site: %w", err)
 		}
+		return &site, true, nil
+	}
+	if membership.Site == nil {
+		return nil, false, nil
 	}
 
-	return sites, nil
+	// Populate membership information
+	membership.Site.MembershipUserID = membership.UserID
+	membership.Site.MembershipRole = membership.Role
+	membership.Site.MembershipIsTeam = true // Direct user membership
+	return membership.Site, true, nil
 }
 
 // Get returns a specific site by ID or name
@@ -99,6 +107,31 @@ func (s *SitesService) Get(ctx context.Context, siteIdentifier string) (*models.
 		return nil, fmt.Errorf("failed to resolve site identifier: %w", err)
 	}
 
+	site, err := s.getByID(ctx, siteID)
+	if IsUUID(siteIdentifier) {
+		return site, err
+	}
+
+	// A cached name may point at a site that has since been deleted or renamed.
+	// Resolve the name again and retry if it now refers to a different site.
+	renamed := err == nil && site.Name != "" && site.Name != siteIdentifier
+	if !IsNotFound(err) && !renamed {
+		return site, err
+	}
+
+	s.client.Resolver().Forget(siteIdentifier)
+	freshID, resolveErr := EnsureSiteUUID(ctx, s.client, siteIdentifier)
+	if resolveErr != nil {
+		return nil, fmt.Errorf("failed to resolve site identifier: %w", resolveErr)
+	}
+	if freshID == siteID {
+		return site, err
+	}
+	return s.getByID(ctx, freshID)
+}
+
+// getByID fetches a site by UUID
+func (s *SitesService) getByID(ctx context.Context, siteID string) (*models.Site, error) {
 	// Use site_state=true to get full site state including upstream information
 	path := fmt.Sprintf("/sites/%s?site_state=true", siteID)
 	resp, err := s.client.Get(ctx, path) //nolint:bodyclose // DecodeResponse closes body
@@ -230,9 +263,11 @@ func (s *SitesService) Delete(ctx context.Context, siteIdentifier string) error
 	}
 
 	if !completedWorkflow.IsSuccessful() {
-		return fmt.Errorf("site deletion workflow failed: %s", completedWorkflow.GetMessage())
+		return fmt.Errorf("site deletion workflow failed: %w", NewWorkflowError(completedWorkflow))
 	}
 
+	s.client.Resolver().Forget(siteID)
+
 	return nil
 }
 
@@ -265,47 +300,59 @@ func (s *SitesService) Update(ctx context.Context, siteIdentifier string, req *U
 
 // ListByOrganization returns sites for a specific organization
 func (s *SitesService) ListByOrganization(ctx context.Context, orgID string) ([]*models.Site, error) {
-	path := fmt.Sprintf("/organizations/%s/memberships/sites", orgID)
-
-	rawResults, err := s.client.GetPaged(ctx, path)
+	sites, err := s.ListByOrganizationPager(orgID).Collect(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list organization sites: %w", err)
 	}
+	return sites, nil
+}
 
-	sites := make([]*models.Site, 0, len(rawResults))
-	for _, raw := range rawResults {
-		var membership struct {
-			Site *models.Site `json:"site"`
-			User struct {
-				ID string `json:"id"`
-			} `json:"user"`
-			Organization struct {
-				ID string `json:"id"`
-			} `json:"organization"`
-			Role string `json:"role"`
-		}
-		if err := json.Unmarshal(raw, &membership); err != nil {
-			return nil, fmt.Errorf("failed to decode site membership: %w", err)
-		}
-		if membership.Site != nil {
-			// Populate membership information
-			// For org memberships, we might have either user or organization
-			// If user.id is present, this is a direct site-level team membership (even within an org)
-			// If only organization.id is present, this is an org-wide membership
-			if membership.User.ID != "" {
-				membership.Site.MembershipUserID = membership.User.ID
-				membership.Site.MembershipRole = membership.Role
-				membership.Site.MembershipIsTeam = true // Direct site-level team membership
-			} else if membership.Organization.ID != "" {
-				membership.Site.MembershipUserID = membership.Organization.ID
-				membership.Site.MembershipRole = membership.Role
-				membership.Site.MembershipIsTeam = false // Organization-wide membership
-			}
-			sites = append(sites, membership.Site)
-		}
-	}
+// ListByOrganizationPager returns a pager over the sites of an organization,
+// decoding one page at a time
+func (s *SitesService) ListByOrganizationPager(orgID string) *Pager[*models.Site] {
+	path := fmt.Sprintf("/organizations/%s/memberships/sites", orgID)
+	return NewPager(s.client, path, decodeOrgSiteMembership)
+}
 
-	return sites, nil
+// ListByOrganizationIter returns an iterator over the sites of an
+// organization, fetching pages as they are consumed
+func (s *SitesService) ListByOrganizationIter(ctx context.Context, orgID string) iter.Seq2[*models.Site, error] {
+	return s.ListByOrganizationPager(orgID).All(ctx)
+}
+
+// decodeOrgSiteMembership decodes an entry from an organization's site memberships
+func decodeOrgSiteMembership(raw json.RawMessage) (*models.Site, bool, error) {
+	var membership struct {
+		Site *models.Site `json:"site"`
+		User struct {
+			ID string `json:"id"`
+		} `json:"user"`
+		Organization struct {
+			ID string `json:"id"`
+		} `json:"organization"`
+		Role string `json:"role"`
+	}
+	if err := json.Unmarshal(raw, &membership); err != nil {
+		return nil, false, fmt.Errorf("failed to decode site membership: %w", err)
+	}
+	if membership.Site == nil {
+		return nil, false, nil
+	}
+
+	// Populate membership information
+	// For org memberships, we might have either user or organization
+	// If user.id is present, this is a direct site-level team membership (even within an org)
+	// If only organization.id is present, this is an org-wide membership
+	if membership.User.ID != "" {
+		membership.Site.MembershipUserID = membership.User.ID
+		membership.Site.MembershipRole = membership.Role
+		membership.Site.MembershipIsTeam = true // Direct site-level team membership
+	} else if membership.Organization.ID != "" {
+		membership.Site.MembershipUserID = membership.Organization.ID
+		membership.Site.MembershipRole = membership.Role
+		membership.Site.MembershipIsTeam = false // Organization-wide membership
+	}
+	return membership.Site, true, nil
 }
 
 // GetTeam returns team members for a site
@@ -377,6 +424,27 @@ func (s *SitesService) RemoveTeamMember(ctx context.Context, siteIdentifier, use
 	return nil
 }
 
+// UpdateTeamMemberRole changes the role of a site team member
+func (s *SitesService) UpdateTeamMemberRole(ctx context.Context, siteIdentifier, userID, role string) error {
+	siteID, err := s.ensureSiteUUID(ctx, siteIdentifier)
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/sites/%s/memberships/users/%s", siteID, userID)
+	resp, err := s.client.Put(ctx, path, map[string]string{"role": role})
+	if err != nil {
+		return fmt.Errorf("failed to update team member role: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("update team member role failed with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
 // GetTags returns tags for a site
 func (s *SitesService) GetTags(ctx context.Context, siteIdentifier, orgID string) ([]*models.Tag, error) {
 	siteID, err := s.ensureSiteUUID(ctx, siteIdentifier)
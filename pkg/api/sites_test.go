@@ -759,6 +759,44 @@ func TestSitesService_RemoveTeamMember(t *testing.T) {
 	}
 }
 
+func TestSitesService_UpdateTeamMemberRole(t *testing.T) {
+	siteID := "12345678-1234-1234-1234-123456789abc"
+	userID := "user123"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			t.Errorf("expected PUT request, got %s", r.Method)
+		}
+
+		expectedPath := "/sites/" + siteID + "/memberships/users/" + userID
+		if r.URL.Path != expectedPath {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if body["role"] != "developer" {
+			t.Errorf("expected role 'developer', got '%s'", body["role"])
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithToken("test-token"),
+		WithHTTPClient(&http.Client{Timeout: 5 * time.Second}),
+	)
+
+	service := NewSitesService(client)
+	if err := service.UpdateTeamMemberRole(context.Background(), siteID, userID, "developer"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func TestSitesService_GetTags(t *testing.T) {
 	siteID := "12345678-1234-1234-1234-123456789abc"
 	orgID := "org-123"
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Span records the timing of one operation, such as an API request, a retry
+// attempt or a workflow wait. Spans started while another is in the context
+// become its children and share its trace ID. All methods are safe to call on
+// a nil span, which is what StartSpan returns when tracing is disabled.
+type Span struct {
+	TraceID    string                 `json:"trace_id"`
+	SpanID     string                 `json:"span_id"`
+	ParentID   string                 `json:"parent_id,omitempty"`
+	Name       string                 `json:"name"`
+	Start      time.Time              `json:"start"`
+	End        time.Time              `json:"end"`
+	DurationMS float64                `json:"duration_ms"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+
+	exporter SpanExporter
+}
+
+// SpanExporter receives each span when it ends
+type SpanExporter interface {
+	ExportSpan(span *Span) error
+}
+
+type spanContextKey struct{}
+
+// WithSpanExporter records a span for every request, retry attempt, token
+// refresh and workflow wait, and passes them to exporter as they end
+func WithSpanExporter(exporter SpanExporter) ClientOption {
+	return func(c *Client) {
+		c.spanExporter = exporter
+	}
+}
+
+// SpanFromContext returns the span in ctx, or nil if there isn't one
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanContextKey{}).(*Span)
+	return span
+}
+
+// StartSpan starts a span named name. It is a child of the span in ctx if
+// there is one, and otherwise starts a new trace exported to exporter. If
+// there is neither, it returns ctx and a nil span.
+func StartSpan(ctx context.Context, exporter SpanExporter, name string) (context.Context, *Span) {
+	span := &Span{
+		SpanID: randomHex(8),
+		Name:   name,
+		Start:  time.Now(),
+	}
+	if parent := SpanFromContext(ctx); parent != nil {
+		span.TraceID, span.ParentID, span.exporter = parent.TraceID, parent.SpanID, parent.exporter
+	} else if exporter != nil {
+		span.TraceID, span.exporter = randomHex(16), exporter
+	} else {
+		return ctx, nil
+	}
+	return context.WithValue(ctx, spanContextKey{}, span), span
+}
+
+// SetAttribute records a value describing the operation
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	if s.Attributes == nil {
+		s.Attributes = make(map[string]interface{})
+	}
+	s.Attributes[key] = value
+}
+
+// Finish ends the span, recording err if the operation failed, and exports it
+func (s *Span) Finish(err error) {
+	if s == nil {
+		return
+	}
+	s.End = time.Now()
+	s.DurationMS = float64(s.End.Sub(s.Start)) / float64(time.Millisecond)
+	if err != nil {
+		s.Error = err.Error()
+	}
+	_ = s.exporter.ExportSpan(s)
+}
+
+// JSONSpanExporter writes each span as a line of JSON
+type JSONSpanExporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSONSpanExporter creates an exporter that writes to w, or to standard
+// output if w is nil
+func NewJSONSpanExporter(w io.Writer) *JSONSpanExporter {
+	if w == nil {
+		w = os.Stdout
+	}
+	return &JSONSpanExporter{w: w}
+}
+
+// ExportSpan writes span as a single line
+func (e *JSONSpanExporter) ExportSpan(span *Span) error {
+	data, err := json.Marshal(span)
+	if err != nil {
+		return fmt.Errorf("failed to marshal span: %w", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err = e.w.Write(append(data, '\n'))
+	return err
+}
+
+// TracingInterceptor records a span for each request, with its method, path
+// template, status code and Pantheon trace ID. Requests made without a span
+// in their context start a new trace exported to exporter.
+func TracingInterceptor(exporter SpanExporter) Interceptor {
+	return func(req *http.Request, next Handler) (*http.Response, error) {
+		ctx, span := StartSpan(req.Context(), exporter, "HTTP "+req.Method)
+		if span == nil {
+			return next(req)
+		}
+
+		span.SetAttribute("http.method", req.Method)
+		span.SetAttribute("http.path_template", PathTemplate(req.URL.Path))
+		span.SetAttribute("pantheon.trace_id", req.Header.Get(TraceIDHeader))
+
+		resp, err := next(req.WithContext(ctx))
+		if err == nil {
+			span.SetAttribute("http.status_code", resp.StatusCode)
+		}
+		span.Finish(err)
+		return resp, err
+	}
+}
+
+// pathParameters maps collections in API paths to the placeholder used for
+// the identifier that follows them
+var pathParameters = map[string]string{
+	"sites":         "{site}",
+	"site-names":    "{site}",
+	"environments":  "{env}",
+	"organizations": "{org}",
+	"users":         "{user}",
+	"workflows":     "{workflow}",
+	"catalog":       "{backup}",
+	"downloads":     "{element}",
+	"domains":       "{domain}",
+	"upstreams":     "{upstream}",
+	"tags":          "{tag}",
+}
+
+// PathTemplate replaces the identifiers in an API path with placeholders, so
+// that requests for different sites and environments can be grouped, e.g.
+// /api/sites/{site}/environments/{env}/workflows
+func PathTemplate(path string) string {
+	segments := strings.Split(path, "/")
+	for i := 1; i < len(segments); i++ {
+		_, isCollection := pathParameters[segments[i]]
+		if placeholder, ok := pathParameters[segments[i-1]]; ok && segments[i] != "" && !isCollection {
+			segments[i] = placeholder
+		} else if IsUUID(segments[i]) {
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// randomHex returns n random bytes as hex, as used for trace and span IDs
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
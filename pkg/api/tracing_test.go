@@ -0,0 +1,186 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// spanRecorder collects exported spans
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []*Span
+}
+
+func (r *spanRecorder) ExportSpan(span *Span) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, span)
+	return nil
+}
+
+// named returns the recorded spans with the given name
+func (r *spanRecorder) named(name string) []*Span {
+	var spans []*Span
+	for _, span := range r.spans {
+		if span.Name == name {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+func TestClientTracing(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch {
+		case r.URL.Path == "/authorize/machine-token":
+			_, _ = w.Write([]byte(`{"session": "new-token", "expires_at": 9999999999}`))
+		case requests == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.Header.Get("Authorization") != "Bearer new-token":
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			_, _ = w.Write([]byte(`{"id": "site-1"}`))
+		}
+	}))
+	defer server.Close()
+
+	recorder := &spanRecorder{}
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithRetryPolicy(&DefaultRetryPolicy{MaxRetries: 1, InitialBackoff: time.Millisecond}),
+		WithSpanExporter(recorder),
+	)
+	client.SetTokenRefresher(NewSessionTokenRefresher(func() (string, error) { return "machine-token", nil }, client))
+
+	resp, err := client.Get(context.Background(), "/sites/11111111-2222-3333-4444-555555555555/environments/dev")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	requestSpans := recorder.named("HTTP GET")
+	if len(requestSpans) != 1 {
+		t.Fatalf("expected one GET span, got %d", len(requestSpans))
+	}
+	request := requestSpans[0]
+	if request.ParentID != "" || request.TraceID == "" {
+		t.Errorf("expected a root span, got %+v", request)
+	}
+	if got := request.Attributes["http.path_template"]; got != "/sites/{site}/environments/{env}" {
+		t.Errorf("unexpected path template %v", got)
+	}
+	if request.Attributes["http.status_code"] != http.StatusOK || request.Attributes["pantheon.trace_id"] == "" {
+		t.Errorf("unexpected attributes %v", request.Attributes)
+	}
+
+	// The 503 is retried, the 401 triggers a refresh, then the retry succeeds
+	attempts := recorder.named("HTTP attempt")
+	if len(attempts) != 3 {
+		t.Fatalf("expected 3 attempts, got %d", len(attempts))
+	}
+	refreshes := recorder.named("token refresh")
+	if len(refreshes) != 1 || refreshes[0].ParentID != request.SpanID {
+		t.Fatalf("expected a token refresh inside the request, got %+v", refreshes)
+	}
+	logins := recorder.named("HTTP POST")
+	if len(logins) != 1 || logins[0].ParentID != refreshes[0].SpanID {
+		t.Errorf("expected the login request inside the token refresh, got %+v", logins)
+	}
+	for _, span := range recorder.spans {
+		if span.TraceID != request.TraceID {
+			t.Errorf("expected %s to share the request's trace", span.Name)
+		}
+	}
+}
+
+func TestWorkflowWaitTracing(t *testing.T) {
+	var polls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		polls++
+		result := ""
+		if polls == 2 {
+			result = "succeeded"
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"id": "wf-1", "type": "deploy", "result": result})
+	}))
+	defer server.Close()
+
+	recorder := &spanRecorder{}
+	client := NewClient(WithBaseURL(server.URL), WithRetryPolicy(nil), WithSpanExporter(recorder))
+
+	_, err := NewWorkflowsService(client).Wait(context.Background(), "site-1", "wf-1", &WaitOptions{PollInterval: time.Millisecond})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	waits := recorder.named("workflow wait")
+	if len(waits) != 1 {
+		t.Fatalf("expected one wait span, got %d", len(waits))
+	}
+	if waits[0].Attributes["workflow.type"] != "deploy" || waits[0].Attributes["workflow.result"] != "succeeded" {
+		t.Errorf("unexpected wait attributes %v", waits[0].Attributes)
+	}
+
+	pollSpans := recorder.named("workflow poll")
+	if len(pollSpans) != 2 {
+		t.Fatalf("expected 2 poll spans, got %d", len(pollSpans))
+	}
+	for _, poll := range pollSpans {
+		if poll.ParentID != waits[0].SpanID {
+			t.Errorf("expected polls inside the wait")
+		}
+	}
+	if gets := recorder.named("HTTP GET"); len(gets) != 2 || gets[0].ParentID != pollSpans[0].SpanID {
+		t.Errorf("expected each GET inside its poll, got %+v", gets)
+	}
+}
+
+func TestStartSpanWithoutExporter(t *testing.T) {
+	ctx, span := StartSpan(context.Background(), nil, "untraced")
+	if span != nil || SpanFromContext(ctx) != nil {
+		t.Fatalf("expected no span without an exporter or parent")
+	}
+	// Methods are safe on a nil span
+	span.SetAttribute("key", "value")
+	span.Finish(nil)
+}
+
+func TestJSONSpanExporter(t *testing.T) {
+	var buf bytes.Buffer
+	_, span := StartSpan(context.Background(), NewJSONSpanExporter(&buf), "test")
+	span.SetAttribute("http.status_code", 200)
+	span.Finish(nil)
+
+	var exported map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &exported); err != nil {
+		t.Fatalf("expected a line of JSON, got %q", buf.String())
+	}
+	if exported["name"] != "test" || exported["trace_id"] == "" || !strings.HasSuffix(buf.String(), "\n") {
+		t.Errorf("unexpected span %s", buf.String())
+	}
+}
+
+func TestPathTemplate(t *testing.T) {
+	tests := map[string]string{
+		"/api/sites/my-site/environments/dev/workflows":                             "/api/sites/{site}/environments/{env}/workflows",
+		"/api/sites/my-site/environments/dev/backups/catalog/b1":                    "/api/sites/{site}/environments/{env}/backups/catalog/{backup}",
+		"/api/organizations/org-1/tags/sites/my-site":                               "/api/organizations/{org}/tags/sites/{site}",
+		"/api/users/11111111-2222-3333-4444-555555555555/keys":                      "/api/users/{user}/keys",
+		"/api/site-names/my-site":                                                   "/api/site-names/{site}",
+		"/api/sites/my-site/memberships/users/22222222-3333-4444-5555-666666666666": "/api/sites/{site}/memberships/users/{user}",
+	}
+	for path, want := range tests {
+		if got := PathTemplate(path); got != want {
+			t.Errorf("PathTemplate(%s) = %s, want %s", path, got, want)
+		}
+	}
+}
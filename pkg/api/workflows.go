@@ -2,12 +2,71 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/deviantintegral/terminus-golang/pkg/api/models"
 )
 
+// Sentinel errors matched by *WorkflowError through errors.Is
+var (
+	ErrWorkflowFailed  = errors.New("workflow failed")
+	ErrWorkflowTimeout = errors.New("workflow did not complete within timeout")
+)
+
+// WorkflowError reports a workflow that finished unsuccessfully, did not
+// finish before the wait timed out, or whose wait was interrupted
+type WorkflowError struct {
+	WorkflowID string
+	Message    string
+	Timeout    bool
+	// Err is the context error when the wait was interrupted; the workflow
+	// itself keeps running on the platform
+	Err error
+}
+
+// NewWorkflowError creates an error for a workflow that finished unsuccessfully
+func NewWorkflowError(workflow *models.Workflow) *WorkflowError {
+	return &WorkflowError{
+		WorkflowID: workflow.ID,
+		Message:    workflow.GetMessage(),
+	}
+}
+
+func (e *WorkflowError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("wait for workflow interrupted: %v", e.Err)
+	}
+	if e.Timeout {
+		return ErrWorkflowTimeout.Error()
+	}
+	if e.Message != "" {
+		return e.Message
+	}
+	return ErrWorkflowFailed.Error()
+}
+
+// Unwrap returns the context error of an interrupted wait
+func (e *WorkflowError) Unwrap() error { return e.Err }
+
+// Is reports whether the error matches ErrWorkflowFailed or ErrWorkflowTimeout
+func (e *WorkflowError) Is(target error) bool {
+	if e.Err != nil {
+		return false
+	}
+	return (target == ErrWorkflowTimeout && e.Timeout) || (target == ErrWorkflowFailed && !e.Timeout)
+}
+
+// WorkflowID returns the ID of the workflow wrapped by err, if any
+func WorkflowID(err error) string {
+	var workflowErr *WorkflowError
+	if errors.As(err, &workflowErr) {
+		return workflowErr.WorkflowID
+	}
+	return ""
+}
+
 // WorkflowsService handles workflow-related operations
 type WorkflowsService struct {
 	client *Client
@@ -68,9 +127,10 @@ func (s *WorkflowsService) Get(ctx context.Context, siteID, workflowID string) (
 
 // WaitOptions configures workflow wait behavior
 type WaitOptions struct {
-	// PollInterval is how often to check workflow status
+	// PollInterval is how often to check workflow status (default 3s)
 	PollInterval time.Duration
-	// Timeout is the maximum time to wait
+	// Timeout is the maximum time to wait (default: the client's wait
+	// timeout, or 30m)
 	Timeout time.Duration
 	// OnProgress is called on each poll with the current workflow state
 	OnProgress func(*models.Workflow)
@@ -84,12 +144,81 @@ func DefaultWaitOptions() *WaitOptions {
 	}
 }
 
+// waitOptions fills in unset wait options with defaults, using the client's
+// wait timeout when one is configured
+func (s *WorkflowsService) waitOptions(opts *WaitOptions) *WaitOptions {
+	resolved := DefaultWaitOptions()
+	if s.client.waitTimeout > 0 {
+		resolved.Timeout = s.client.waitTimeout
+	}
+	if opts == nil {
+		return resolved
+	}
+
+	if opts.PollInterval > 0 {
+		resolved.PollInterval = opts.PollInterval
+	}
+	if opts.Timeout > 0 {
+		resolved.Timeout = opts.Timeout
+	}
+	resolved.OnProgress = opts.OnProgress
+
+	return resolved
+}
+
+// WorkflowObserver is told how each wait for a workflow ended: with the
+// finished workflow, or with the error that ended the wait. siteID is empty
+// for user workflows.
+type WorkflowObserver func(siteID, workflowID string, workflow *models.Workflow, err error)
+
+// WithWorkflowObserver calls observer whenever the client stops waiting for a
+// workflow, including the waits inside services such as SitesService.Delete.
+// Made-up workflows in dry-run mode aren't reported.
+func WithWorkflowObserver(observer WorkflowObserver) ClientOption {
+	return func(c *Client) {
+		c.onWorkflowDone = observer
+	}
+}
+
 // Wait waits for a workflow to complete
 func (s *WorkflowsService) Wait(ctx context.Context, siteID, workflowID string, opts *WaitOptions) (*models.Workflow, error) {
-	if opts == nil {
-		opts = DefaultWaitOptions()
+	return s.wait(ctx, siteID, workflowID, opts, func(ctx context.Context) (*models.Workflow, error) {
+		return s.Get(ctx, siteID, workflowID)
+	})
+}
+
+// wait polls get until the workflow finishes, recording the wait and each
+// poll as spans when tracing is enabled and reporting the outcome to the
+// client's workflow observer
+func (s *WorkflowsService) wait(ctx context.Context, siteID, workflowID string, opts *WaitOptions, get func(context.Context) (*models.Workflow, error)) (workflow *models.Workflow, err error) {
+	if observer := s.client.onWorkflowDone; observer != nil && !s.client.dryRun {
+		defer func() { observer(siteID, workflowID, workflow, err) }()
+	}
+	opts = s.waitOptions(opts)
+
+	ctx, span := StartSpan(ctx, s.client.spanExporter, "workflow wait")
+	span.SetAttribute("workflow.id", workflowID)
+	workflow, err = s.poll(ctx, workflowID, opts, func(ctx context.Context) (*models.Workflow, error) {
+		ctx, pollSpan := StartSpan(ctx, nil, "workflow poll")
+		workflow, err := get(ctx)
+		if err == nil {
+			pollSpan.SetAttribute("workflow.type", workflow.Type)
+			pollSpan.SetAttribute("workflow.result", workflow.Result)
+			span.SetAttribute("workflow.type", workflow.Type)
+		}
+		pollSpan.Finish(err)
+		return workflow, err
+	})
+	if err == nil {
+		span.SetAttribute("workflow.result", workflow.Result)
 	}
+	span.Finish(err)
+	return workflow, err
+}
 
+// poll calls get until the workflow finishes, the timeout passes or ctx is
+// cancelled
+func (s *WorkflowsService) poll(ctx context.Context, workflowID string, opts *WaitOptions, get func(context.Context) (*models.Workflow, error)) (*models.Workflow, error) {
 	// Create a context with timeout
 	timeoutCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
 	defer cancel()
@@ -98,8 +227,14 @@ func (s *WorkflowsService) Wait(ctx context.Context, siteID, workflowID string,
 	defer ticker.Stop()
 
 	for {
-		workflow, err := s.Get(timeoutCtx, siteID, workflowID)
+		workflow, err := get(timeoutCtx)
 		if err != nil {
+			if ctx.Err() != nil {
+				return nil, &WorkflowError{WorkflowID: workflowID, Err: ctx.Err()}
+			}
+			if timeoutCtx.Err() != nil {
+				return nil, &WorkflowError{WorkflowID: workflowID, Timeout: true}
+			}
 			return nil, fmt.Errorf("failed to check workflow status: %w", err)
 		}
 
@@ -113,7 +248,10 @@ func (s *WorkflowsService) Wait(ctx context.Context, siteID, workflowID string,
 
 		select {
 		case <-timeoutCtx.Done():
-			return nil, fmt.Errorf("workflow did not complete within timeout")
+			if ctx.Err() != nil {
+				return nil, &WorkflowError{WorkflowID: workflowID, Err: ctx.Err()}
+			}
+			return nil, &WorkflowError{WorkflowID: workflowID, Timeout: true}
 		case <-ticker.C:
 			// Continue polling
 		}
@@ -145,6 +283,9 @@ func (s *WorkflowsService) Watch(ctx context.Context, siteID, workflowID string,
 	for {
 		workflow, err := s.Get(ctx, siteID, workflowID)
 		if err != nil {
+			if ctx.Err() != nil {
+				return &WorkflowError{WorkflowID: workflowID, Err: ctx.Err()}
+			}
 			return fmt.Errorf("failed to check workflow status: %w", err)
 		}
 
@@ -161,7 +302,7 @@ func (s *WorkflowsService) Watch(ctx context.Context, siteID, workflowID string,
 
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return &WorkflowError{WorkflowID: workflowID, Err: ctx.Err()}
 		case <-ticker.C:
 			// Continue watching
 		}
@@ -208,38 +349,9 @@ func (s *WorkflowsService) GetForUser(ctx context.Context, userID, workflowID st
 
 // WaitForUser waits for a user workflow to complete
 func (s *WorkflowsService) WaitForUser(ctx context.Context, userID, workflowID string, opts *WaitOptions) (*models.Workflow, error) {
-	if opts == nil {
-		opts = DefaultWaitOptions()
-	}
-
-	// Create a context with timeout
-	timeoutCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
-	defer cancel()
-
-	ticker := time.NewTicker(opts.PollInterval)
-	defer ticker.Stop()
-
-	for {
-		workflow, err := s.GetForUser(timeoutCtx, userID, workflowID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to check workflow status: %w", err)
-		}
-
-		if opts.OnProgress != nil {
-			opts.OnProgress(workflow)
-		}
-
-		if workflow.IsFinished() {
-			return workflow, nil
-		}
-
-		select {
-		case <-timeoutCtx.Done():
-			return nil, fmt.Errorf("workflow did not complete within timeout")
-		case <-ticker.C:
-			// Continue polling
-		}
-	}
+	return s.wait(ctx, "", workflowID, opts, func(ctx context.Context) (*models.Workflow, error) {
+		return s.GetForUser(ctx, userID, workflowID)
+	})
 }
 
 // CreateForSite creates a workflow for a site
@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"sync/atomic"
@@ -299,6 +300,12 @@ func TestWorkflowsService_Wait_Timeout(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected timeout error")
 	}
+	if !errors.Is(err, ErrWorkflowTimeout) || errors.Is(err, ErrWorkflowFailed) {
+		t.Errorf("expected a workflow timeout error, got %v", err)
+	}
+	if WorkflowID(err) != workflowID {
+		t.Errorf("expected workflow ID %s, got %q", workflowID, WorkflowID(err))
+	}
 
 	// Verify that we polled multiple times before timing out
 	if pollCount.Load() < 2 {
@@ -306,6 +313,74 @@ func TestWorkflowsService_Wait_Timeout(t *testing.T) {
 	}
 }
 
+func TestWorkflowsService_Wait_Cancelled(t *testing.T) {
+	siteID := "12345678-1234-1234-1234-123456789abc"
+	workflowID := "wf-123"
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Simulate Ctrl-C while the workflow is still running
+		cancel()
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":          workflowID,
+			"result":      "",
+			"finished_at": 0.0,
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithToken("test-token"),
+		WithHTTPClient(&http.Client{Timeout: 5 * time.Second}),
+	)
+
+	_, err := NewWorkflowsService(client).Wait(ctx, siteID, workflowID, &WaitOptions{
+		PollInterval: time.Minute,
+		Timeout:      time.Hour,
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if errors.Is(err, ErrWorkflowFailed) || errors.Is(err, ErrWorkflowTimeout) {
+		t.Errorf("expected an interrupted wait not to be a failure or timeout, got %v", err)
+	}
+	if WorkflowID(err) != workflowID {
+		t.Errorf("expected workflow ID %s, got %q", workflowID, WorkflowID(err))
+	}
+}
+
+func TestWorkflowsService_Wait_ClientWaitTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":          "wf-123",
+			"result":      "",
+			"finished_at": 0.0,
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithHTTPClient(&http.Client{Timeout: 5 * time.Second}),
+		WithWaitTimeout(100*time.Millisecond),
+	)
+
+	// Unset options fall back to the client's wait timeout rather than 30 minutes
+	start := time.Now()
+	_, err := NewWorkflowsService(client).Wait(context.Background(), "site", "wf-123", &WaitOptions{})
+	if !errors.Is(err, ErrWorkflowTimeout) {
+		t.Fatalf("expected workflow timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the client wait timeout to apply, waited %v", elapsed)
+	}
+}
+
 func TestWorkflowsService_Wait_DefaultOptions(t *testing.T) {
 	siteID := "12345678-1234-1234-1234-123456789abc"
 	workflowID := "wf-123"
@@ -0,0 +1,198 @@
+// Package audit records the changes made from this machine in a local
+// JSON-lines log.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Redacted replaces secret values in recorded command lines
+const Redacted = "REDACTED"
+
+// SensitiveFlags lists the flags whose values are redacted from command lines
+var SensitiveFlags = []string{"machine-token", "password"}
+
+// Entry is one line of the audit log. Entries for API requests have a
+// method and path; entries for finished workflows have a workflow ID and the
+// workflow's result as their outcome.
+type Entry struct {
+	Time        time.Time `json:"time"`
+	Command     string    `json:"command"`
+	CommandLine string    `json:"command_line"`
+	User        string    `json:"user,omitempty"`
+	Host        string    `json:"host,omitempty"`
+	Site        string    `json:"site,omitempty"`
+	Env         string    `json:"env,omitempty"`
+	Method      string    `json:"method,omitempty"`
+	Path        string    `json:"path,omitempty"`
+	WorkflowID  string    `json:"workflow_id,omitempty"`
+	TraceID     string    `json:"trace_id,omitempty"`
+	Outcome     string    `json:"outcome"`
+}
+
+// Log appends entries to an audit file
+type Log struct {
+	path string
+	base Entry
+
+	mu sync.Mutex
+}
+
+// NewLog creates a log that writes to path. The command, user and host of
+// base are copied into every appended entry that doesn't set its own.
+func NewLog(path string, base Entry) *Log {
+	return &Log{
+		path: path,
+		base: base,
+	}
+}
+
+// Path returns the file the log writes to
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Append writes entry as a single line, filling in the time and the fields
+// from the log's base entry
+func (l *Log) Append(entry Entry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now().UTC()
+	}
+	if entry.Command == "" {
+		entry.Command = l.base.Command
+	}
+	if entry.CommandLine == "" {
+		entry.CommandLine = l.base.CommandLine
+	}
+	if entry.User == "" {
+		entry.User = l.base.User
+	}
+	if entry.Host == "" {
+		entry.Host = l.base.Host
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+	data = append(data, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// Filter selects audit entries. Zero fields match everything.
+type Filter struct {
+	Since time.Time
+	Until time.Time
+	// Sites matches entries for any of the given site names or IDs
+	Sites   []string
+	Command string
+}
+
+// Match reports whether entry is selected by the filter
+func (f *Filter) Match(entry *Entry) bool {
+	if !f.Since.IsZero() && entry.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && entry.Time.After(f.Until) {
+		return false
+	}
+	if f.Command != "" && entry.Command != f.Command {
+		return false
+	}
+	if len(f.Sites) > 0 {
+		for _, site := range f.Sites {
+			if entry.Site == site {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Read returns the entries in the log selected by filter, oldest first. A
+// missing log has no entries, and lines that can't be parsed are skipped.
+func (l *Log) Read(filter *Filter) ([]*Entry, error) {
+	f, err := os.Open(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []*Entry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	entries := []*Entry{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if filter == nil || filter.Match(&entry) {
+			entries = append(entries, &entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// CommandLine joins args for the log, replacing the values of the flags in
+// SensitiveFlags with Redacted
+func CommandLine(args []string) string {
+	redacted := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name, _, hasValue := strings.Cut(arg, "=")
+		if !strings.HasPrefix(name, "-") || !isSecretFlag(name) {
+			redacted = append(redacted, arg)
+			continue
+		}
+
+		if hasValue {
+			redacted = append(redacted, name+"="+Redacted)
+			continue
+		}
+		redacted = append(redacted, arg)
+		if i+1 < len(args) {
+			redacted = append(redacted, Redacted)
+			i++
+		}
+	}
+	return strings.Join(redacted, " ")
+}
+
+// isSecretFlag reports whether the flag name is one of SensitiveFlags
+func isSecretFlag(name string) bool {
+	return slices.Contains(SensitiveFlags, strings.TrimLeft(name, "-"))
+}
@@ -0,0 +1,88 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLogAppendAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.jsonl")
+	log := NewLog(path, Entry{Command: "env:deploy", User: "user@example.com", Host: "laptop"})
+
+	if entries, err := log.Read(nil); err != nil || len(entries) != 0 {
+		t.Fatalf("expected no entries in a missing log, got %v, %v", entries, err)
+	}
+
+	now := time.Now().UTC()
+	entries := []Entry{
+		{Time: now.Add(-48 * time.Hour), Site: "site-a", Outcome: "202 Accepted"},
+		{Time: now.Add(-time.Hour), Site: "site-b", Outcome: "succeeded"},
+		{Time: now, Command: "site:delete", Site: "site-a", Outcome: "200 OK"},
+	}
+	for _, entry := range entries {
+		if err := log.Append(entry); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// Unparseable lines are skipped
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	_, _ = f.WriteString("not json\n")
+	_ = f.Close()
+
+	all, err := log.Read(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(all))
+	}
+	if all[0].User != "user@example.com" || all[0].Host != "laptop" || all[0].Command != "env:deploy" {
+		t.Errorf("expected base fields to be filled in, got %+v", all[0])
+	}
+	if all[2].Command != "site:delete" {
+		t.Errorf("expected entry command to take precedence, got %s", all[2].Command)
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   int
+	}{
+		{"since", Filter{Since: now.Add(-2 * time.Hour)}, 2},
+		{"until", Filter{Until: now.Add(-2 * time.Hour)}, 1},
+		{"site", Filter{Sites: []string{"site-a"}}, 2},
+		{"command", Filter{Command: "env:deploy"}, 2},
+		{"combined", Filter{Sites: []string{"site-a"}, Command: "env:deploy"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := log.Read(&tt.filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != tt.want {
+				t.Errorf("expected %d entries, got %d", tt.want, len(got))
+			}
+		})
+	}
+}
+
+func TestCommandLine(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"env:deploy", "my-site.live", "--note", "Release"}, "env:deploy my-site.live --note Release"},
+		{[]string{"auth:login", "--machine-token=abc123"}, "auth:login --machine-token=REDACTED"},
+		{[]string{"auth:login", "--machine-token", "abc123", "--email", "me@example.com"}, "auth:login --machine-token REDACTED --email me@example.com"},
+		{[]string{"lock:enable", "my-site.dev", "--username", "admin", "--password", "hunter2"}, "lock:enable my-site.dev --username admin --password REDACTED"},
+	}
+	for _, tt := range tests {
+		if got := CommandLine(tt.args); got != tt.want {
+			t.Errorf("CommandLine(%v) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
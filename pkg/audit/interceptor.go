@@ -0,0 +1,88 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/deviantintegral/terminus-golang/pkg/api"
+	"github.com/deviantintegral/terminus-golang/pkg/api/models"
+)
+
+// targetPattern extracts the site and optional environment a request path
+// refers to
+var targetPattern = regexp.MustCompile(`/sites/([^/?]+)(?:/environments/([^/?]+))?`)
+
+// Interceptor records every POST, PUT, PATCH and DELETE request that is sent,
+// with its outcome and, for requests that start a workflow, the workflow ID.
+// Request bodies are never recorded. Failures to write the log don't fail the
+// request, since by then it has already been sent.
+func Interceptor(log *Log) api.Interceptor {
+	return func(req *http.Request, next api.Handler) (*http.Response, error) {
+		switch req.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			return next(req)
+		}
+
+		entry := Entry{
+			Method:  req.Method,
+			Path:    req.URL.Path,
+			TraceID: req.Header.Get(api.TraceIDHeader),
+		}
+		if match := targetPattern.FindStringSubmatch(req.URL.Path); match != nil {
+			entry.Site, entry.Env = match[1], match[2]
+		}
+
+		resp, err := next(req)
+		switch {
+		case err != nil:
+			entry.Outcome = err.Error()
+		default:
+			entry.Outcome = fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+			if resp.StatusCode < 400 && strings.HasSuffix(req.URL.Path, "/workflows") {
+				entry.WorkflowID = peekWorkflowID(resp)
+			}
+		}
+
+		_ = log.Append(entry)
+		return resp, err
+	}
+}
+
+// WorkflowObserver records the result of every workflow the client waits
+// for, or why the wait failed. Use it with api.WithWorkflowObserver.
+func WorkflowObserver(log *Log) api.WorkflowObserver {
+	return func(siteID, workflowID string, workflow *models.Workflow, err error) {
+		entry := Entry{Site: siteID, WorkflowID: workflowID}
+		if err != nil {
+			entry.Outcome = fmt.Sprintf("wait failed: %v", err)
+		} else {
+			entry.Env, entry.Outcome = workflow.EnvironmentID, workflow.Result
+			if workflow.SiteID != "" {
+				entry.Site = workflow.SiteID
+			}
+		}
+		_ = log.Append(entry)
+	}
+}
+
+// peekWorkflowID returns the ID of the workflow in resp, leaving the body
+// readable
+func peekWorkflowID(resp *http.Response) string {
+	data, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	if err != nil {
+		return ""
+	}
+
+	var workflow struct {
+		ID string `json:"id"`
+	}
+	_ = json.Unmarshal(data, &workflow)
+	return workflow.ID
+}
@@ -0,0 +1,96 @@
+package audit
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/deviantintegral/terminus-golang/pkg/api"
+	"github.com/deviantintegral/terminus-golang/pkg/api/apitest"
+)
+
+func TestInterceptor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			_, _ = w.Write([]byte(`{"id": "site-1"}`))
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			_, _ = w.Write([]byte(`{"id": "workflow-1"}`))
+		}
+	}))
+	defer server.Close()
+
+	log := NewLog(filepath.Join(t.TempDir(), "audit.jsonl"), Entry{Command: "env:deploy"})
+	client := api.NewClient(api.WithBaseURL(server.URL), api.WithRetryPolicy(nil), api.WithInterceptors(Interceptor(log)))
+	ctx := context.Background()
+
+	resp, err := client.Get(ctx, "/sites/site-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	resp, err = client.Post(ctx, "/sites/site-1/environments/live/workflows", map[string]string{"type": "deploy"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if string(body) != `{"id": "workflow-1"}` {
+		t.Errorf("expected response body to survive auditing, got %q", body)
+	}
+
+	if _, err := client.Delete(ctx, "/sites/site-1"); !api.IsForbidden(err) {
+		t.Fatalf("expected forbidden error, got %v", err)
+	}
+
+	entries, err := log.Read(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected the POST and DELETE to be recorded, got %d entries", len(entries))
+	}
+
+	post := entries[0]
+	if post.Method != http.MethodPost || post.Site != "site-1" || post.Env != "live" ||
+		post.WorkflowID != "workflow-1" || post.Outcome != "200 OK" || post.TraceID == "" || post.Command != "env:deploy" {
+		t.Errorf("unexpected POST entry: %+v", post)
+	}
+	if del := entries[1]; del.Method != http.MethodDelete || del.Outcome != "403 Forbidden" || del.WorkflowID != "" {
+		t.Errorf("unexpected DELETE entry: %+v", del)
+	}
+}
+
+func TestWorkflowObserver(t *testing.T) {
+	server := apitest.NewServer(t)
+	site := server.AddSite("my-site")
+
+	log := NewLog(filepath.Join(t.TempDir(), "audit.jsonl"), Entry{Command: "site:delete"})
+	client := server.Client(api.WithInterceptors(Interceptor(log)), api.WithWorkflowObserver(WorkflowObserver(log)))
+
+	// SitesService.Delete waits for its workflow itself
+	if err := api.NewSitesService(client).Delete(context.Background(), site.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entries, err := log.Read(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected the request and the workflow to be recorded, got %+v", entries)
+	}
+	started, finished := entries[0], entries[1]
+	if finished.WorkflowID == "" || finished.WorkflowID != started.WorkflowID {
+		t.Errorf("expected the workflow %q to be recorded, got %+v", started.WorkflowID, finished)
+	}
+	if finished.Site != site.ID || finished.Outcome != "succeeded" || finished.Command != "site:delete" {
+		t.Errorf("unexpected workflow entry: %+v", finished)
+	}
+}
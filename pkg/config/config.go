@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -19,6 +21,8 @@ const (
 	DefaultProtocol = "https"
 	// DefaultTimeout is the default timeout in seconds
 	DefaultTimeout = 86400
+	// DefaultWaitTimeout is the default time to wait for workflows in seconds
+	DefaultWaitTimeout = 1800
 	// DefaultDateFormat is the default date format
 	DefaultDateFormat = "2006-01-02 15:04:05"
 )
@@ -31,6 +35,23 @@ type Config struct {
 	Protocol string
 	Timeout  int
 
+	// Workflow settings
+	WaitTimeout int
+
+	// Client-side throttling (zero means unlimited)
+	RateLimit      float64
+	RateLimitBurst int
+	MaxConcurrency int
+
+	// ReadOnly blocks every API request that could change something
+	ReadOnly bool
+
+	// ResponseCache caches responses from read-only API endpoints on disk
+	ResponseCache bool
+
+	// TraceFile receives tracing spans as JSON lines ("-" for standard error)
+	TraceFile string
+
 	// Paths
 	HomeDir     string
 	CacheDir    string
@@ -63,6 +84,7 @@ func New() (*Config, error) {
 		Port:        DefaultPort,
 		Protocol:    DefaultProtocol,
 		Timeout:     DefaultTimeout,
+		WaitTimeout: DefaultWaitTimeout,
 		DateFormat:  DefaultDateFormat,
 		HomeDir:     homeDir,
 		CacheDir:    cacheDir,
@@ -192,7 +214,21 @@ func (c *Config) setValue(key string, value interface{}) {
 	case "TERMINUS_PROTOCOL":
 		c.Protocol = valueStr
 	case "TERMINUS_TIMEOUT":
-		_, _ = fmt.Sscanf(valueStr, "%d", &c.Timeout)
+		c.Timeout = parseSeconds(valueStr, c.Timeout)
+	case "TERMINUS_WAIT_TIMEOUT":
+		c.WaitTimeout = parseSeconds(valueStr, c.WaitTimeout)
+	case "TERMINUS_RATE_LIMIT":
+		_, _ = fmt.Sscanf(valueStr, "%g", &c.RateLimit)
+	case "TERMINUS_RATE_LIMIT_BURST":
+		_, _ = fmt.Sscanf(valueStr, "%d", &c.RateLimitBurst)
+	case "TERMINUS_MAX_CONCURRENCY":
+		_, _ = fmt.Sscanf(valueStr, "%d", &c.MaxConcurrency)
+	case "TERMINUS_READ_ONLY":
+		c.ReadOnly, _ = strconv.ParseBool(valueStr)
+	case "TERMINUS_RESPONSE_CACHE":
+		c.ResponseCache, _ = strconv.ParseBool(valueStr)
+	case "TERMINUS_TRACE_FILE":
+		c.TraceFile = c.expandPath(valueStr)
 	case "TERMINUS_CACHE_DIR":
 		c.CacheDir = c.expandPath(valueStr)
 	case "TERMINUS_PLUGINS_DIR":
@@ -207,6 +243,20 @@ func (c *Config) setValue(key string, value interface{}) {
 	c.values[normalizedKey] = value
 }
 
+// parseSeconds parses a number of seconds or a duration such as "90s" or
+// "1h", returning fallback if value is neither
+func parseSeconds(value string, fallback int) int {
+	if d, err := time.ParseDuration(value); err == nil {
+		return int(d / time.Second)
+	}
+
+	var seconds int
+	if _, err := fmt.Sscanf(value, "%d", &seconds); err != nil {
+		return fallback
+	}
+	return seconds
+}
+
 // expandPath expands placeholders in paths
 func (c *Config) expandPath(path string) string {
 	// Replace common placeholders
@@ -271,6 +321,31 @@ func (c *Config) GetBool(key string) bool {
 	return false
 }
 
+// ResponseCacheDir returns the directory used to cache API responses
+func (c *Config) ResponseCacheDir() string {
+	return filepath.Join(c.CacheDir, "responses")
+}
+
+// NameCacheFile returns the file used to remember resolved site, environment and organization names
+func (c *Config) NameCacheFile() string {
+	return filepath.Join(c.CacheDir, "names.json")
+}
+
+// AuditLogFile returns the JSON-lines file that records changes made from this machine
+func (c *Config) AuditLogFile() string {
+	return filepath.Join(c.CacheDir, "audit.jsonl")
+}
+
+// RequestTimeout returns the maximum time for each API request
+func (c *Config) RequestTimeout() time.Duration {
+	return time.Duration(c.Timeout) * time.Second
+}
+
+// WorkflowWaitTimeout returns the maximum time to wait for workflows to complete
+func (c *Config) WorkflowWaitTimeout() time.Duration {
+	return time.Duration(c.WaitTimeout) * time.Second
+}
+
 // GetBaseURL returns the full API base URL
 func (c *Config) GetBaseURL() string {
 	return fmt.Sprintf("%s://%s:%d/api", c.Protocol, c.Host, c.Port)
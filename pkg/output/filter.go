@@ -0,0 +1,356 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrUnknownField is matched by errors for filters and sort keys naming a
+// field that the listed items don't have
+var ErrUnknownField = errors.New("unknown field")
+
+// Filter is a condition on a field of list items, such as plan=Basic.
+// Fields are named as in the table output.
+type Filter struct {
+	Field    string
+	Operator string
+	Value    string
+	pattern  *regexp.Regexp
+}
+
+// listFilterOperators lists the operators a filter may use, two-character
+// operators first so that != isn't read as !
+var listFilterOperators = []string{"~=", "!=", "<=", ">=", "=", "<", ">"}
+
+// ParseFilter parses a filter such as name=value. Operators are = and !=
+// (case-insensitive), ~= (regular expression), and <, >, <= and >= for
+// numbers and dates.
+func ParseFilter(expr string) (*Filter, error) {
+	i := strings.IndexAny(expr, "~!<>=")
+	if i < 0 {
+		return nil, fmt.Errorf("invalid filter %q: expected field=value", expr)
+	}
+
+	var operator string
+	for _, candidate := range listFilterOperators {
+		if strings.HasPrefix(expr[i:], candidate) {
+			operator = candidate
+			break
+		}
+	}
+	field := strings.TrimSpace(expr[:i])
+	if operator == "" || field == "" {
+		return nil, fmt.Errorf("invalid filter %q: expected field=value", expr)
+	}
+
+	filter := &Filter{
+		Field:    field,
+		Operator: operator,
+		Value:    strings.TrimSpace(expr[i+len(operator):]),
+	}
+	switch operator {
+	case "~=":
+		pattern, err := regexp.Compile(filter.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter %q: %w", expr, err)
+		}
+		filter.pattern = pattern
+	case "<", ">", "<=", ">=":
+		if _, ok := parseFilterNumber(filter.Value); !ok {
+			if _, ok := parseFilterDate(filter.Value); !ok {
+				return nil, fmt.Errorf("invalid filter %q: %s needs a number or a date", expr, operator)
+			}
+		}
+	}
+	return filter, nil
+}
+
+// Match reports whether a serialized field value satisfies the filter
+func (f *Filter) Match(value interface{}) bool {
+	text := plainText(value)
+	switch f.Operator {
+	case "=":
+		return strings.EqualFold(text, f.Value)
+	case "!=":
+		return !strings.EqualFold(text, f.Value)
+	case "~=":
+		return f.pattern.MatchString(text)
+	}
+
+	cmp, ok := compareFilterValue(value, f.Value)
+	if !ok {
+		return false
+	}
+	switch f.Operator {
+	case "<":
+		return cmp < 0
+	case ">":
+		return cmp > 0
+	case "<=":
+		return cmp <= 0
+	default:
+		return cmp >= 0
+	}
+}
+
+// compareFilterValue compares a field value with a number or date from a
+// filter. Numeric fields compared with a date are read as Unix timestamps.
+func compareFilterValue(value interface{}, want string) (int, bool) {
+	if wantNumber, ok := parseFilterNumber(want); ok {
+		got, ok := toNumber(value)
+		if !ok {
+			if got, ok = parseFilterNumber(plainText(value)); !ok {
+				return 0, false
+			}
+		}
+		return compareFloats(got, wantNumber), true
+	}
+
+	wantDate, ok := parseFilterDate(want)
+	if !ok {
+		return 0, false
+	}
+	var got time.Time
+	if n, isNumber := toNumber(value); isNumber {
+		got = time.Unix(int64(n), 0)
+	} else if got, ok = parseFilterDate(plainText(value)); !ok {
+		return 0, false
+	}
+	return got.Compare(wantDate), true
+}
+
+func parseFilterNumber(s string) (float64, bool) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return f, err == nil
+}
+
+// filterDateLayouts are the date formats accepted by filters and sorting
+var filterDateLayouts = []string{time.RFC3339, time.DateTime, time.DateOnly}
+
+func parseFilterDate(s string) (time.Time, bool) {
+	for _, layout := range filterDateLayouts {
+		if t, err := time.ParseInLocation(layout, strings.TrimSpace(s), time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// SortKey sorts list items by a field, named as in the table output
+type SortKey struct {
+	Field      string
+	Descending bool
+}
+
+// ParseSortKey parses a sort key such as name, name:asc or created:desc
+func ParseSortKey(expr string) (SortKey, error) {
+	field, direction, _ := strings.Cut(expr, ":")
+	key := SortKey{Field: strings.TrimSpace(field)}
+	if key.Field == "" {
+		return key, fmt.Errorf("invalid sort %q: expected field or field:desc", expr)
+	}
+	switch strings.ToLower(strings.TrimSpace(direction)) {
+	case "", "asc":
+	case "desc":
+		key.Descending = true
+	default:
+		return key, fmt.Errorf("invalid sort %q: direction must be asc or desc", expr)
+	}
+	return key, nil
+}
+
+// compareSortValues orders two field values: numbers numerically, dates
+// chronologically, and anything else as case-insensitive text. Empty values
+// sort first.
+func compareSortValues(a, b interface{}) int {
+	aText, bText := plainText(a), plainText(b)
+	if aText == "" || bText == "" {
+		return strings.Compare(aText, bText)
+	}
+
+	aNumber, aOK := toNumber(a)
+	bNumber, bOK := toNumber(b)
+	if !aOK {
+		aNumber, aOK = parseFilterNumber(aText)
+	}
+	if !bOK {
+		bNumber, bOK = parseFilterNumber(bText)
+	}
+	if aOK && bOK {
+		return compareFloats(aNumber, bNumber)
+	}
+
+	if aDate, ok := parseFilterDate(aText); ok {
+		if bDate, ok := parseFilterDate(bText); ok {
+			return aDate.Compare(bDate)
+		}
+	}
+	return strings.Compare(strings.ToLower(aText), strings.ToLower(bText))
+}
+
+// parseListOptions parses the filters and sort keys of opts
+func parseListOptions(opts *Options) ([]*Filter, []SortKey, error) {
+	filters := make([]*Filter, 0, len(opts.Filters))
+	for _, expr := range opts.Filters {
+		filter, err := ParseFilter(expr)
+		if err != nil {
+			return nil, nil, err
+		}
+		filters = append(filters, filter)
+	}
+
+	keys := make([]SortKey, 0, len(opts.Sort))
+	for _, expr := range opts.Sort {
+		key, err := ParseSortKey(expr)
+		if err != nil {
+			return nil, nil, err
+		}
+		keys = append(keys, key)
+	}
+	return filters, keys, nil
+}
+
+// checkFields returns an ErrUnknownField error for the first of fields that
+// items of type t don't have. The fields of maps, of parsed objects and of
+// lists of mixed types aren't known from the type, so they aren't checked.
+func checkFields(t reflect.Type, fields []string) error {
+	if t == nil || t == reflect.TypeOf(&object{}) {
+		return nil
+	}
+	sample := reflect.Zero(t)
+	if t.Kind() == reflect.Ptr {
+		sample = reflect.New(t.Elem())
+	}
+	if kind := reflect.Indirect(sample).Kind(); kind == reflect.Interface || kind == reflect.Map {
+		return nil
+	}
+
+	item, ok := newItemFields(sample)
+	if !ok {
+		return nil
+	}
+	for _, field := range fields {
+		if _, ok := item.lookup(field); !ok {
+			return fmt.Errorf("%w %q: expected one of %s", ErrUnknownField, field, strings.Join(item.names, ", "))
+		}
+	}
+	return nil
+}
+
+// filterFields returns the fields that filters refer to
+func filterFields(filters []*Filter) []string {
+	fields := make([]string, len(filters))
+	for i, filter := range filters {
+		fields[i] = filter.Field
+	}
+	return fields
+}
+
+// matchesFilters reports whether a list item matches every filter
+func matchesFilters(item interface{}, filters []*Filter) bool {
+	values := fieldValues(reflect.ValueOf(item), filterFields(filters))
+	for i, filter := range filters {
+		if !filter.Match(values[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// filterAndSort returns the items of a list that match every filter, sorted
+// by the sort keys, as a slice of the same type. Anything other than a list
+// is returned unchanged. Fields that the item type doesn't have are reported
+// as errors rather than matching nothing.
+func filterAndSort(data interface{}, filters []*Filter, keys []SortKey) (interface{}, error) {
+	v := reflect.ValueOf(data)
+	if v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() == reflect.Slice {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return data, nil
+	}
+
+	fields := filterFields(filters)
+	for _, key := range keys {
+		fields = append(fields, key.Field)
+	}
+	itemType := v.Type().Elem()
+	if itemType.Kind() == reflect.Interface && v.Len() > 0 && !v.Index(0).IsNil() {
+		itemType = v.Index(0).Elem().Type()
+	}
+	if err := checkFields(itemType, fields); err != nil {
+		return nil, err
+	}
+
+	type row struct {
+		item   reflect.Value
+		values []interface{}
+	}
+	var rows []row
+	for i := 0; i < v.Len(); i++ {
+		item := v.Index(i)
+		values := fieldValues(item, fields)
+		matched := true
+		for j, filter := range filters {
+			if !filter.Match(values[j]) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			rows = append(rows, row{item: item, values: values[len(filters):]})
+		}
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		for k, key := range keys {
+			cmp := compareSortValues(rows[i].values[k], rows[j].values[k])
+			if cmp == 0 {
+				continue
+			}
+			if key.Descending {
+				return cmp > 0
+			}
+			return cmp < 0
+		}
+		return false
+	})
+
+	result := reflect.MakeSlice(reflect.SliceOf(v.Type().Elem()), 0, len(rows))
+	for _, r := range rows {
+		result = reflect.Append(result, r.item)
+	}
+	return result.Interface(), nil
+}
+
+// fieldValues returns the serialized values of fields for a list item,
+// with nil for fields the item doesn't have
+func fieldValues(v reflect.Value, fields []string) []interface{} {
+	values := make([]interface{}, len(fields))
+	item, ok := newItemFields(v)
+	if !ok {
+		return values
+	}
+	for i, field := range fields {
+		if name, ok := item.lookup(field); ok {
+			values[i] = toGeneric(item.values[name])
+		}
+	}
+	return values
+}
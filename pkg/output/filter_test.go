@@ -0,0 +1,175 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type filterTestSite struct {
+	Name    string `json:"name"`
+	Plan    string `json:"plan_name"`
+	Size    int    `json:"size"`
+	Created int64  `json:"created"`
+	Updated string `json:"updated"`
+}
+
+func (s *filterTestSite) Serialize() []SerializedField {
+	return []SerializedField{
+		{Name: "Name", Value: s.Name},
+		{Name: "Plan", Value: s.Plan},
+		{Name: "Size", Value: s.Size},
+		{Name: "Created", Value: s.Created},
+		{Name: "Last Updated", Value: s.Updated},
+	}
+}
+
+var filterTestSites = []*filterTestSite{
+	{Name: "alpha", Plan: "Basic", Size: 10, Created: 1704067200, Updated: "2024-03-01 10:00:00"}, // 2024-01-01
+	{Name: "beta", Plan: "Sandbox", Size: 2, Created: 1672531200, Updated: "2023-06-01 10:00:00"}, // 2023-01-01
+	{Name: "gamma", Plan: "basic", Size: 30, Created: 1717200000, Updated: "2024-06-01 10:00:00"}, // 2024-06-01
+}
+
+func filterTestNames(t *testing.T, opts *Options) string {
+	t.Helper()
+	var buf bytes.Buffer
+	opts.Format = FormatList
+	opts.Writer = &buf
+	if err := Print(filterTestSites, opts); err != nil {
+		t.Fatalf("Print failed: %v", err)
+	}
+	return strings.Join(strings.Fields(buf.String()), ",")
+}
+
+func TestPrintFilters(t *testing.T) {
+	tests := []struct {
+		filters []string
+		want    string
+	}{
+		{[]string{"plan=basic"}, "alpha,gamma"},
+		{[]string{"Plan!=Basic"}, "beta"},
+		{[]string{"name~=^(a|g)"}, "alpha,gamma"},
+		{[]string{"size>5"}, "alpha,gamma"},
+		{[]string{"size<=10"}, "alpha,beta"},
+		{[]string{"created>=2024-01-01"}, "alpha,gamma"},
+		{[]string{"last_updated<2024-01-01"}, "beta"},
+		{[]string{"Last Updated>2024-05-01T00:00:00Z"}, "gamma"},
+		{[]string{"plan=basic", "size>20"}, "gamma"},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.filters, " "), func(t *testing.T) {
+			if got := filterTestNames(t, &Options{Filters: tt.filters}); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintSort(t *testing.T) {
+	tests := []struct {
+		sort []string
+		want string
+	}{
+		{[]string{"size"}, "beta,alpha,gamma"},
+		{[]string{"size:desc"}, "gamma,alpha,beta"},
+		{[]string{"created"}, "beta,alpha,gamma"},
+		{[]string{"LastUpdated:desc"}, "gamma,alpha,beta"},
+		{[]string{"plan", "name:desc"}, "gamma,alpha,beta"},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.sort, ","), func(t *testing.T) {
+			if got := filterTestNames(t, &Options{Sort: tt.sort}); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintFilterKeepsItems(t *testing.T) {
+	var buf bytes.Buffer
+	opts := &Options{Format: FormatJSON, Writer: &buf, Filters: []string{"name=beta"}}
+	if err := Print(filterTestSites, opts); err != nil {
+		t.Fatalf("Print failed: %v", err)
+	}
+	// JSON output still uses the items' own encoding
+	if !strings.Contains(buf.String(), `"plan_name": "Sandbox"`) || strings.Contains(buf.String(), "alpha") {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestStreamFilterAndSort(t *testing.T) {
+	var buf bytes.Buffer
+	stream := NewStream(&Options{Format: FormatList, Writer: &buf, Filters: []string{"plan=basic"}})
+	for _, site := range filterTestSites {
+		if err := stream.Write(site); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if want := "alpha\ngamma\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	stream = NewStream(&Options{Format: FormatList, Writer: &buf, Sort: []string{"name:desc"}})
+	for _, site := range filterTestSites {
+		if err := stream.Write(site); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if want := "gamma\nbeta\nalpha\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestUnknownFields(t *testing.T) {
+	for _, opts := range []*Options{
+		{Filters: []string{"missing=x"}},
+		{Filters: []string{"missing!=x"}},
+		{Sort: []string{"missing"}},
+	} {
+		opts.Writer = &bytes.Buffer{}
+		if err := Print(filterTestSites, opts); !errors.Is(err, ErrUnknownField) {
+			t.Errorf("Print with %v: expected an unknown field error, got %v", opts, err)
+		}
+		if err := Print([]*filterTestSite{}, opts); !errors.Is(err, ErrUnknownField) {
+			t.Errorf("Print of no items with %v: expected an unknown field error, got %v", opts, err)
+		}
+
+		stream := NewStream(&Options{Format: FormatJSON, Writer: &bytes.Buffer{}, Filters: opts.Filters, Sort: opts.Sort})
+		err := stream.Write(filterTestSites[0])
+		if err == nil {
+			err = stream.Close()
+		}
+		if !errors.Is(err, ErrUnknownField) {
+			t.Errorf("Stream with %v: expected an unknown field error, got %v", opts, err)
+		}
+	}
+
+	// The fields of maps vary from item to item
+	items := []map[string]interface{}{{"name": "alpha"}}
+	if err := Print(items, &Options{Format: FormatJSON, Writer: &bytes.Buffer{}, Filters: []string{"plan=x"}}); err != nil {
+		t.Errorf("unexpected error filtering maps: %v", err)
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	for _, expr := range []string{"name", "=value", "name~value", "name~=(", "size>big"} {
+		if _, err := ParseFilter(expr); err == nil {
+			t.Errorf("ParseFilter(%q) succeeded, want an error", expr)
+		}
+	}
+	for _, expr := range []string{"", ":desc", "name:sideways"} {
+		if _, err := ParseSortKey(expr); err == nil {
+			t.Errorf("ParseSortKey(%q) succeeded, want an error", expr)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+const (
+	// FormatNDJSON is newline-delimited JSON, one object per line
+	FormatNDJSON Format = "ndjson"
+	// FormatMarkdown is a GitHub Flavored Markdown table
+	FormatMarkdown Format = "markdown"
+	// FormatHTML is an HTML table
+	FormatHTML Format = "html"
+	// FormatTSV is tab-separated values
+	FormatTSV Format = "tsv"
+)
+
+// printNDJSON prints each item of a list as a compact JSON object on its own
+// line, with the same fields as the table output
+func printNDJSON(data interface{}, opts *Options) error {
+	items, ok := serialize(data, opts.Fields).([]interface{})
+	if !ok {
+		return writeNDJSON(opts.Writer, serialize(data, opts.Fields))
+	}
+	for _, item := range items {
+		if err := writeNDJSON(opts.Writer, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeNDJSON writes a serialized value as one line of JSON
+func writeNDJSON(w io.Writer, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode item: %w", err)
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+// printMarkdown prints data as a GitHub Flavored Markdown table
+func printMarkdown(data interface{}, opts *Options) error {
+	rows, headers := extractTableData(data, opts.Fields)
+	if len(rows) == 0 {
+		return nil
+	}
+
+	cells := func(row []string) []string {
+		escaped := make([]string, len(headers))
+		for i := range headers {
+			if i < len(row) {
+				escaped[i] = markdownEscaper.Replace(row[i])
+			}
+		}
+		return escaped
+	}
+
+	header := cells(headers)
+	body := make([][]string, len(rows))
+	widths := make([]int, len(headers))
+	for i, h := range header {
+		// GFM needs at least three dashes in the separator
+		widths[i] = max(len(h), 3)
+	}
+	for i, row := range rows {
+		body[i] = cells(row)
+		for j, cell := range body[i] {
+			widths[j] = max(widths[j], len(cell))
+		}
+	}
+
+	writeRow := func(row []string) {
+		for i, cell := range row {
+			_, _ = fmt.Fprintf(opts.Writer, "| %-*s ", widths[i], cell)
+		}
+		_, _ = fmt.Fprintln(opts.Writer, "|")
+	}
+
+	writeRow(header)
+	separator := make([]string, len(widths))
+	for i, w := range widths {
+		separator[i] = strings.Repeat("-", w)
+	}
+	writeRow(separator)
+	for _, row := range body {
+		writeRow(row)
+	}
+	return nil
+}
+
+// markdownEscaper keeps a cell on one line and stops pipes from ending it
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "|", `\|`, "\r\n", "<br>", "\n", "<br>")
+
+// printHTML prints data as an HTML table
+func printHTML(data interface{}, opts *Options) error {
+	rows, headers := extractTableData(data, opts.Fields)
+	if len(rows) == 0 {
+		return nil
+	}
+
+	var b strings.Builder
+	b.WriteString("<table>\n  <thead>\n    <tr>")
+	for _, h := range headers {
+		fmt.Fprintf(&b, "<th>%s</th>", html.EscapeString(h))
+	}
+	b.WriteString("</tr>\n  </thead>\n  <tbody>\n")
+	for _, row := range rows {
+		b.WriteString("    <tr>")
+		for _, cell := range row {
+			fmt.Fprintf(&b, "<td>%s</td>", html.EscapeString(cell))
+		}
+		b.WriteString("</tr>\n")
+	}
+	b.WriteString("  </tbody>\n</table>\n")
+
+	_, err := io.WriteString(opts.Writer, b.String())
+	return err
+}
+
+// printTSV prints data as tab-separated values with a header row
+func printTSV(data interface{}, opts *Options) error {
+	rows, headers := extractTableData(data, opts.Fields)
+	if len(rows) == 0 {
+		return nil
+	}
+
+	if err := writeTSVRow(opts.Writer, headers); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := writeTSVRow(opts.Writer, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tsvEscaper escapes the characters that would break a TSV row, using the
+// same backslash escapes as PostgreSQL and MySQL
+var tsvEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+func writeTSVRow(w io.Writer, row []string) error {
+	escaped := make([]string, len(row))
+	for i, cell := range row {
+		escaped[i] = tsvEscaper.Replace(cell)
+	}
+	_, err := fmt.Fprintln(w, strings.Join(escaped, "\t"))
+	return err
+}
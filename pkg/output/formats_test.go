@@ -0,0 +1,96 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+)
+
+type formatsTestItem struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Size    int    `json:"size"`
+	Private string `json:"private"`
+}
+
+func (i formatsTestItem) DefaultFields() []string {
+	return []string{"id", "name", "size"}
+}
+
+func TestPrintFormats(t *testing.T) {
+	items := []formatsTestItem{
+		{ID: "1", Name: "a|b", Size: 3, Private: "x"},
+		{ID: "2", Name: "<tab\there>\nnext", Size: 10, Private: "y"},
+	}
+
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{
+			format: FormatNDJSON,
+			want: `{"id":"1","name":"a|b","size":3}` + "\n" +
+				`{"id":"2","name":"\u003ctab\there\u003e\nnext","size":10}` + "\n",
+		},
+		{
+			format: FormatTSV,
+			want:   "id\tname\tsize\n1\ta|b\t3\n2\t<tab\\there>\\nnext\t10\n",
+		},
+		{
+			format: FormatMarkdown,
+			want: "| id  | name               | size |\n" +
+				"| --- | ------------------ | ---- |\n" +
+				"| 1   | a\\|b               | 3    |\n" +
+				"| 2   | <tab\there><br>next | 10   |\n",
+		},
+		{
+			format: FormatHTML,
+			want: "<table>\n  <thead>\n    <tr><th>id</th><th>name</th><th>size</th></tr>\n  </thead>\n  <tbody>\n" +
+				"    <tr><td>1</td><td>a|b</td><td>3</td></tr>\n" +
+				"    <tr><td>2</td><td>&lt;tab\there&gt;\nnext</td><td>10</td></tr>\n" +
+				"  </tbody>\n</table>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Print(items, &Options{Format: tt.format, Writer: &buf}); err != nil {
+				t.Fatalf("Print failed: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintFormatsSerializer(t *testing.T) {
+	items := []*streamTestItem{{ID: "1", Name: "alpha"}}
+
+	tests := map[Format]string{
+		FormatNDJSON:   `{"Name":"alpha","ID":"1"}` + "\n",
+		FormatTSV:      "Name\tID\nalpha\t1\n",
+		FormatMarkdown: "| Name  | ID  |\n| ----- | --- |\n| alpha | 1   |\n",
+	}
+	for format, want := range tests {
+		var buf bytes.Buffer
+		if err := Print(items, &Options{Format: format, Writer: &buf}); err != nil {
+			t.Fatalf("Print(%s) failed: %v", format, err)
+		}
+		if buf.String() != want {
+			t.Errorf("%s: got %q, want %q", format, buf.String(), want)
+		}
+	}
+}
+
+func TestPrintFormatsEmpty(t *testing.T) {
+	for _, format := range []Format{FormatNDJSON, FormatTSV, FormatMarkdown, FormatHTML} {
+		var buf bytes.Buffer
+		if err := Print([]formatsTestItem{}, &Options{Format: format, Writer: &buf}); err != nil {
+			t.Fatalf("Print(%s) failed: %v", format, err)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("%s: expected no output for an empty list, got %q", format, buf.String())
+		}
+	}
+}
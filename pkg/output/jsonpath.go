@@ -0,0 +1,429 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JSONPath is a compiled kubectl-style JSONPath template, such as
+// '{.items[*].id}' or '{range .items[*]}{.name}{"\n"}{end}'. Text outside
+// braces is printed as is, and the values matched by each expression are
+// printed separated by spaces. Paths support .field, ['field'], [n], [*],
+// ..field and [?(@.field == "value")] filters.
+type JSONPath struct {
+	nodes []jsonPathNode
+}
+
+// jsonPathNode is a piece of a JSONPath template: text, a path expression or
+// a range over a path
+type jsonPathNode struct {
+	text     string
+	path     []pathStep
+	isPath   bool
+	isRange  bool
+	children []jsonPathNode
+}
+
+// pathStep is one step of a path, mapping each current value to zero or
+// more values
+type pathStep func(root, value interface{}) ([]interface{}, error)
+
+// ParseJSONPath compiles a JSONPath template
+func ParseJSONPath(template string) (*JSONPath, error) {
+	nodes, _, ended, err := parseJSONPathNodes(template)
+	if err == nil && ended {
+		err = errors.New("{end} without {range}")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid jsonpath %q: %w", template, err)
+	}
+	return &JSONPath{nodes: nodes}, nil
+}
+
+// parseJSONPathNodes parses template up to its end or the first unmatched
+// {end}, returning the remainder after {end} and whether one was found
+func parseJSONPathNodes(template string) (nodes []jsonPathNode, rest string, ended bool, err error) {
+	for template != "" {
+		start := strings.Index(template, "{")
+		if start < 0 {
+			nodes = append(nodes, jsonPathNode{text: template})
+			break
+		}
+		if start > 0 {
+			nodes = append(nodes, jsonPathNode{text: template[:start]})
+		}
+
+		end := closingBrace(template, start)
+		if end < 0 {
+			return nil, "", false, errors.New("unclosed {")
+		}
+		action := strings.TrimSpace(template[start+1 : end])
+		template = template[end+1:]
+
+		switch {
+		case action == "end":
+			return nodes, template, true, nil
+		case strings.HasPrefix(action, "range "):
+			steps, err := parsePath(strings.TrimSpace(strings.TrimPrefix(action, "range ")))
+			if err != nil {
+				return nil, "", false, err
+			}
+			children, remainder, ended, err := parseJSONPathNodes(template)
+			if err != nil {
+				return nil, "", false, err
+			}
+			if !ended {
+				return nil, "", false, errors.New("{range} without {end}")
+			}
+			nodes = append(nodes, jsonPathNode{path: steps, isRange: true, children: children})
+			template = remainder
+		case strings.HasPrefix(action, `"`):
+			var text string
+			if err := json.Unmarshal([]byte(action), &text); err != nil {
+				return nil, "", false, fmt.Errorf("invalid string %s", action)
+			}
+			nodes = append(nodes, jsonPathNode{text: text})
+		default:
+			steps, err := parsePath(action)
+			if err != nil {
+				return nil, "", false, err
+			}
+			nodes = append(nodes, jsonPathNode{path: steps, isPath: true})
+		}
+	}
+	return nodes, "", false, nil
+}
+
+// closingBrace returns the index of the brace closing the one at start,
+// skipping braces inside quoted strings
+func closingBrace(s string, start int) int {
+	quote := byte(0)
+	for i := start + 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0 && c == '\\':
+			i++
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == 0 && c == '}':
+			return i
+		}
+	}
+	return -1
+}
+
+// Execute renders the template against data
+func (p *JSONPath) Execute(data interface{}) (string, error) {
+	var b strings.Builder
+	if err := executeJSONPath(&b, p.nodes, data, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func executeJSONPath(b *strings.Builder, nodes []jsonPathNode, root, current interface{}) error {
+	for _, node := range nodes {
+		switch {
+		case node.isRange:
+			values, err := evalPath(node.path, root, current)
+			if err != nil {
+				return err
+			}
+			for _, value := range values {
+				if err := executeJSONPath(b, node.children, root, value); err != nil {
+					return err
+				}
+			}
+		case node.isPath:
+			values, err := evalPath(node.path, root, current)
+			if err != nil {
+				return err
+			}
+			for i, value := range values {
+				if i > 0 {
+					b.WriteByte(' ')
+				}
+				b.WriteString(plainText(value))
+			}
+		default:
+			b.WriteString(node.text)
+		}
+	}
+	return nil
+}
+
+func evalPath(steps []pathStep, root, current interface{}) ([]interface{}, error) {
+	values := []interface{}{current}
+	for _, step := range steps {
+		var next []interface{}
+		for _, value := range values {
+			results, err := step(root, value)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, results...)
+		}
+		values = next
+	}
+	return values, nil
+}
+
+// parsePath parses a path such as .items[*].id, $.items[0] or @.name
+func parsePath(path string) ([]pathStep, error) {
+	var steps []pathStep
+	switch {
+	case strings.HasPrefix(path, "$"):
+		steps = append(steps, func(root, _ interface{}) ([]interface{}, error) { return []interface{}{root}, nil })
+		path = path[1:]
+	case strings.HasPrefix(path, "@"):
+		path = path[1:]
+	}
+
+	for path != "" {
+		switch {
+		case strings.HasPrefix(path, ".."):
+			name, rest := splitPathName(path[2:])
+			if name == "" {
+				return nil, fmt.Errorf("expected a field name after .. in %q", path)
+			}
+			steps = append(steps, recursiveStep(name))
+			path = rest
+		case strings.HasPrefix(path, "."):
+			name, rest := splitPathName(path[1:])
+			path = rest
+			switch name {
+			case "":
+				// A bare dot refers to the current value
+			case "*":
+				steps = append(steps, wildcardStep)
+			default:
+				steps = append(steps, fieldStep(name))
+			}
+		case strings.HasPrefix(path, "["):
+			end := closingBracket(path)
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed [ in %q", path)
+			}
+			step, err := parseBracketStep(strings.TrimSpace(path[1:end]))
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, step)
+			path = path[end+1:]
+		default:
+			return nil, fmt.Errorf("unexpected %q in path", path)
+		}
+	}
+	return steps, nil
+}
+
+// splitPathName splits a field name from the start of path
+func splitPathName(path string) (name, rest string) {
+	if strings.HasPrefix(path, "*") {
+		return "*", path[1:]
+	}
+	i := strings.IndexAny(path, ".[")
+	if i < 0 {
+		return path, ""
+	}
+	return path[:i], path[i:]
+}
+
+// closingBracket returns the index of the bracket closing the one at the
+// start of s, skipping brackets inside quoted strings and nested filters
+func closingBracket(s string) int {
+	depth := 0
+	quote := byte(0)
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0 && c == '\\':
+			i++
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == 0 && c == '[':
+			depth++
+		case quote == 0 && c == ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// parseBracketStep parses the inside of [...]: *, an index, a quoted field
+// name or a ?(...) filter
+func parseBracketStep(inner string) (pathStep, error) {
+	switch {
+	case inner == "*":
+		return wildcardStep, nil
+	case strings.HasPrefix(inner, "?(") && strings.HasSuffix(inner, ")"):
+		return parseFilter(strings.TrimSpace(inner[2 : len(inner)-1]))
+	case strings.HasPrefix(inner, "'") || strings.HasPrefix(inner, `"`):
+		name, err := unquotePathString(inner)
+		if err != nil {
+			return nil, err
+		}
+		return fieldStep(name), nil
+	}
+
+	index, err := strconv.Atoi(inner)
+	if err != nil {
+		return nil, fmt.Errorf("invalid index [%s]", inner)
+	}
+	return func(_, value interface{}) ([]interface{}, error) {
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, nil
+		}
+		i := index
+		if i < 0 {
+			i += len(items)
+		}
+		if i < 0 || i >= len(items) {
+			return nil, nil
+		}
+		return []interface{}{items[i]}, nil
+	}, nil
+}
+
+// unquotePathString parses a single- or double-quoted string
+func unquotePathString(s string) (string, error) {
+	if len(s) < 2 || s[len(s)-1] != s[0] {
+		return "", fmt.Errorf("invalid string %s", s)
+	}
+	if s[0] == '\'' {
+		return strings.ReplaceAll(s[1:len(s)-1], `\'`, "'"), nil
+	}
+	var text string
+	if err := json.Unmarshal([]byte(s), &text); err != nil {
+		return "", fmt.Errorf("invalid string %s", s)
+	}
+	return text, nil
+}
+
+// filterOperators lists comparison operators, longest first
+var filterOperators = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+// parseFilter parses a filter condition such as @.name == "dev", or @.name
+// to select values where the path exists
+func parseFilter(condition string) (pathStep, error) {
+	left, op, right := condition, "", ""
+	for _, candidate := range filterOperators {
+		if i := strings.Index(condition, candidate); i >= 0 {
+			left, op, right = strings.TrimSpace(condition[:i]), candidate, strings.TrimSpace(condition[i+len(candidate):])
+			break
+		}
+	}
+
+	leftSteps, err := parsePath(left)
+	if err != nil {
+		return nil, err
+	}
+	var want interface{}
+	if op != "" {
+		if want, err = parseFilterLiteral(right); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(root, value interface{}) ([]interface{}, error) {
+		items, err := wildcardStep(root, value)
+		if err != nil {
+			return nil, err
+		}
+		var matches []interface{}
+		for _, item := range items {
+			got, err := evalPath(leftSteps, root, item)
+			if err != nil {
+				return nil, err
+			}
+			if len(got) == 0 {
+				continue
+			}
+			if op == "" {
+				if got[0] != nil {
+					matches = append(matches, item)
+				}
+				continue
+			}
+			if result, _ := binaryOp(op, got[0], want); result == true {
+				matches = append(matches, item)
+			}
+		}
+		return matches, nil
+	}, nil
+}
+
+// parseFilterLiteral parses the right-hand side of a filter comparison
+func parseFilterLiteral(s string) (interface{}, error) {
+	switch s {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	if strings.HasPrefix(s, "'") || strings.HasPrefix(s, `"`) {
+		return unquotePathString(s)
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %s in filter", s)
+	}
+	return fromNumber(f), nil
+}
+
+func fieldStep(name string) pathStep {
+	return func(_, value interface{}) ([]interface{}, error) {
+		switch v := value.(type) {
+		case *object:
+			if field, ok := v.get(name); ok {
+				return []interface{}{field}, nil
+			}
+		case map[string]interface{}:
+			if field, ok := v[name]; ok {
+				return []interface{}{field}, nil
+			}
+		}
+		return nil, nil
+	}
+}
+
+func wildcardStep(_, value interface{}) ([]interface{}, error) {
+	switch value.(type) {
+	case []interface{}, *object, map[string]interface{}:
+		return iterate(value)
+	}
+	return nil, nil
+}
+
+// recursiveStep finds name in value and everything below it
+func recursiveStep(name string) pathStep {
+	var walk func(value interface{}) []interface{}
+	walk = func(value interface{}) []interface{} {
+		var found []interface{}
+		if name == "*" {
+			children, _ := wildcardStep(nil, value)
+			found = append(found, children...)
+		} else if matches, _ := fieldStep(name)(nil, value); matches != nil {
+			found = append(found, matches...)
+		}
+		children, _ := wildcardStep(nil, value)
+		for _, child := range children {
+			found = append(found, walk(child)...)
+		}
+		return found
+	}
+	return func(_, value interface{}) ([]interface{}, error) {
+		return walk(value), nil
+	}
+}
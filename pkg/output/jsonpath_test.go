@@ -0,0 +1,53 @@
+package output
+
+import "testing"
+
+func TestJSONPathExecute(t *testing.T) {
+	data := map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{"id": "a", "env": "dev", "size": int64(3), "meta": map[string]interface{}{"owner": "x"}},
+			map[string]interface{}{"id": "b", "env": "live", "size": int64(5), "meta": map[string]interface{}{"owner": "y"}},
+		},
+	}
+
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"{.items[*].id}", "a b"},
+		{"{$.items[0].env}", "dev"},
+		{"{.items[-1].id}", "b"},
+		{"{.items[1]['env']}", "live"},
+		{`{.items[?(@.env == "live")].id}`, "b"},
+		{"{.items[?(@.size < 4)].id}", "a"},
+		{"{..owner}", "x y"},
+		{"{.items[0].meta}", `{"owner":"x"}`},
+		{"{.items[0].missing}", ""},
+		{`{range .items[*]}{.id}{"\t"}{.size}{"\n"}{end}`, "a\t3\nb\t5\n"},
+		{"ids: {.items[*].id}", "ids: a b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			path, err := ParseJSONPath(tt.template)
+			if err != nil {
+				t.Fatalf("ParseJSONPath failed: %v", err)
+			}
+			got, err := path.Execute(data)
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseJSONPathErrors(t *testing.T) {
+	for _, template := range []string{"{.items", "{range .items[*]}{.id}", "{end}", "{.items[}", "{.items[x]}", "{foo}"} {
+		if _, err := ParseJSONPath(template); err == nil {
+			t.Errorf("ParseJSONPath(%q) succeeded, want an error", template)
+		}
+	}
+}
@@ -2,6 +2,7 @@
 package output
 
 import (
+	"encoding"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
@@ -34,6 +35,13 @@ type Options struct {
 	Format Format
 	Fields []string
 	Writer io.Writer
+	// Filters limit lists to items matching conditions such as plan=Basic
+	Filters []string
+	// Sort orders lists by fields, such as name or created:desc
+	Sort []string
+	// Query is a jq-like expression run on the serialized data before it is
+	// formatted
+	Query string
 }
 
 // DefaultOptions returns default output options
@@ -54,7 +62,34 @@ func Print(data interface{}, opts *Options) error {
 		opts.Writer = os.Stdout
 	}
 
-	switch opts.Format {
+	if len(opts.Filters) > 0 || len(opts.Sort) > 0 {
+		filters, keys, err := parseListOptions(opts)
+		if err != nil {
+			return err
+		}
+		if data, err = filterAndSort(data, filters, keys); err != nil {
+			return err
+		}
+	}
+
+	if opts.Query != "" {
+		result, err := applyQuery(opts.Query, data, opts.Fields)
+		if err != nil {
+			return err
+		}
+		// The query has already selected the fields
+		data = result
+		opts = &Options{Format: opts.Format, Writer: opts.Writer}
+		if (opts.Format == FormatTable || opts.Format == FormatList) && isScalarResult(data) {
+			return printLines(data, opts.Writer)
+		}
+	}
+
+	switch name, arg := splitFormat(opts.Format); name {
+	case FormatTemplate:
+		return printTemplate(data, arg, opts)
+	case FormatJSONPath:
+		return printJSONPath(data, arg, opts)
 	case FormatTable:
 		return printTable(data, opts)
 	case FormatJSON:
@@ -65,6 +100,14 @@ func Print(data interface{}, opts *Options) error {
 		return printCSV(data, opts)
 	case FormatList:
 		return printList(data, opts)
+	case FormatNDJSON:
+		return printNDJSON(data, opts)
+	case FormatMarkdown:
+		return printMarkdown(data, opts)
+	case FormatHTML:
+		return printHTML(data, opts)
+	case FormatTSV:
+		return printTSV(data, opts)
 	default:
 		return fmt.Errorf("unsupported format: %s", opts.Format)
 	}
@@ -544,14 +587,23 @@ func formatValue(v reflect.Value) string {
 		return ""
 	}
 
-	// Dereference pointer
-	if v.Kind() == reflect.Ptr {
+	// Dereference pointers and interfaces
+	if v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
 		if v.IsNil() {
 			return ""
 		}
 		v = v.Elem()
 	}
 
+	// Types such as time.Time format themselves
+	if v.Kind() == reflect.Struct && v.CanInterface() {
+		if marshaler, ok := v.Interface().(encoding.TextMarshaler); ok {
+			if text, err := marshaler.MarshalText(); err == nil {
+				return string(text)
+			}
+		}
+	}
+
 	// Handle different types
 	switch v.Kind() {
 	case reflect.String:
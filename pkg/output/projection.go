@@ -0,0 +1,205 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+const (
+	// FormatTemplate prints each item with a Go template, given as
+	// template=<template>
+	FormatTemplate Format = "template"
+	// FormatJSONPath prints a JSONPath template, given as jsonpath=<template>
+	FormatJSONPath Format = "jsonpath"
+)
+
+// splitFormat separates a format such as template={{.ID}} into its name and
+// argument
+func splitFormat(format Format) (name Format, arg string) {
+	before, after, found := strings.Cut(string(format), "=")
+	if !found {
+		return format, ""
+	}
+	return Format(before), after
+}
+
+// Validate checks the format, filters, sorting, template and query before
+// anything is printed, so that a typo is reported before a command does any
+// work
+func (o *Options) Validate() error {
+	if _, _, err := parseListOptions(o); err != nil {
+		return err
+	}
+	if o.Query != "" {
+		if _, err := ParseQuery(o.Query); err != nil {
+			return err
+		}
+	}
+
+	name, arg := splitFormat(o.Format)
+	switch name {
+	case FormatTemplate:
+		_, err := parseTemplate(arg)
+		return err
+	case FormatJSONPath:
+		_, err := ParseJSONPath(arg)
+		return err
+	case FormatTable, FormatJSON, FormatYAML, FormatCSV, FormatList, FormatNDJSON, FormatMarkdown, FormatHTML, FormatTSV:
+		if arg != "" {
+			return fmt.Errorf("format %s does not take an argument", name)
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported format: %s", o.Format)
+}
+
+// templateFuncs are available to --format=template
+var templateFuncs = template.FuncMap{
+	"json": func(value interface{}) (string, error) {
+		data, err := json.Marshal(value)
+		return string(data), err
+	},
+	"join": func(separator string, items []interface{}) string {
+		parts := make([]string, len(items))
+		for i, item := range items {
+			parts[i] = plainText(item)
+		}
+		return strings.Join(parts, separator)
+	},
+}
+
+func parseTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		return nil, fmt.Errorf("template format requires a template, e.g. template='{{.ID}}'")
+	}
+	tmpl, err := template.New("format").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// printTemplate executes a Go template for each item of a list, or once for a
+// single item, ending each with a newline
+func printTemplate(data interface{}, text string, opts *Options) error {
+	tmpl, err := parseTemplate(text)
+	if err != nil {
+		return err
+	}
+
+	items, ok := serialize(data, opts.Fields).([]interface{})
+	if !ok {
+		items = []interface{}{serialize(data, opts.Fields)}
+	}
+	for _, item := range items {
+		var b strings.Builder
+		if err := tmpl.Execute(&b, templateData(item)); err != nil {
+			return fmt.Errorf("failed to execute template: %w", err)
+		}
+		if err := writeLine(opts.Writer, b.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// templateData exposes an object's fields to templates as a map, with
+// missing values as empty strings rather than "<no value>". Each field is
+// also available under its table header without spaces or punctuation, so
+// {{.ID}} works for a field named id and {{.IsFrozen}} for "Is Frozen?".
+func templateData(item interface{}) interface{} {
+	obj, ok := item.(*object)
+	if !ok {
+		return item
+	}
+	values := make(map[string]interface{}, len(obj.keys))
+	for _, key := range obj.keys {
+		value := obj.values[key]
+		if value == nil {
+			value = ""
+		}
+		values[key] = value
+	}
+	for _, key := range obj.keys {
+		alias := templateName(toHumanReadable(key))
+		if _, ok := values[alias]; !ok && alias != "" {
+			values[alias] = values[key]
+		}
+	}
+	return values
+}
+
+// templateName strips everything but letters and digits from a header
+func templateName(header string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, header)
+}
+
+// isScalarResult reports whether a query result is a plain value or a list
+// of plain values, which print one per line rather than as a table
+func isScalarResult(result interface{}) bool {
+	items, ok := result.([]interface{})
+	if !ok {
+		items = []interface{}{result}
+	}
+	for _, item := range items {
+		switch item.(type) {
+		case *object, []interface{}, map[string]interface{}:
+			return false
+		}
+	}
+	return true
+}
+
+// printLines prints a scalar query result one value per line
+func printLines(result interface{}, w io.Writer) error {
+	items, ok := result.([]interface{})
+	if !ok {
+		items = []interface{}{result}
+	}
+	for _, item := range items {
+		if _, err := fmt.Fprintln(w, plainText(item)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printJSONPath executes a JSONPath template. Lists are available as .items,
+// as with kubectl.
+func printJSONPath(data interface{}, text string, opts *Options) error {
+	path, err := ParseJSONPath(text)
+	if err != nil {
+		return err
+	}
+
+	root := serialize(data, opts.Fields)
+	if items, ok := root.([]interface{}); ok {
+		wrapper := newObject()
+		wrapper.set("items", items)
+		root = wrapper
+	}
+
+	result, err := path.Execute(root)
+	if err != nil {
+		return err
+	}
+	return writeLine(opts.Writer, result)
+}
+
+// writeLine writes s, adding a newline if it doesn't already end with one
+func writeLine(w io.Writer, s string) error {
+	if !strings.HasSuffix(s, "\n") {
+		s += "\n"
+	}
+	_, err := io.WriteString(w, s)
+	return err
+}
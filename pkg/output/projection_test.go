@@ -0,0 +1,177 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type projectionTestItem struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Plan    string `json:"plan_name"`
+	Created int64  `json:"created"`
+}
+
+func (i projectionTestItem) DefaultFields() []string {
+	return []string{"name", "plan_name"}
+}
+
+func TestPrintTemplate(t *testing.T) {
+	items := []*streamTestItem{{ID: "1", Name: "first"}, {ID: "2", Name: "second"}}
+
+	var buf bytes.Buffer
+	if err := Print(items, &Options{Format: "template={{.ID}} {{.Name}}", Writer: &buf}); err != nil {
+		t.Fatalf("Print failed: %v", err)
+	}
+	if want := "1 first\n2 second\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestPrintTemplateHeaderNames(t *testing.T) {
+	item := projectionTestItem{ID: "1", Name: "site", Plan: "Basic"}
+
+	var buf bytes.Buffer
+	opts := &Options{Format: "template={{.ID}} {{.id}} {{.PlanName}} {{.Created}} {{json .name}}", Fields: []string{"id", "name", "plan_name", "created"}, Writer: &buf}
+	if err := Print(item, opts); err != nil {
+		t.Fatalf("Print failed: %v", err)
+	}
+	if want := "1 1 Basic 0 \"site\"\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestPrintJSONPathFormat(t *testing.T) {
+	items := []*streamTestItem{{ID: "1", Name: "first"}, {ID: "2", Name: "second"}}
+
+	var buf bytes.Buffer
+	if err := Print(items, &Options{Format: "jsonpath={.items[*].ID}", Writer: &buf}); err != nil {
+		t.Fatalf("Print failed: %v", err)
+	}
+	if want := "1 2\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestPrintQuery(t *testing.T) {
+	items := []projectionTestItem{
+		{ID: "1", Name: "alpha", Plan: "Basic"},
+		{ID: "2", Name: "beta", Plan: "Sandbox"},
+	}
+
+	tests := []struct {
+		name   string
+		format Format
+		fields []string
+		query  string
+		want   string
+	}{
+		{
+			name:   "default fields select what the query sees",
+			format: FormatJSON,
+			query:  ".[0]",
+			want:   "{\n  \"name\": \"alpha\",\n  \"plan_name\": \"Basic\"\n}\n",
+		},
+		{
+			name:   "fields select what the query sees",
+			format: FormatJSON,
+			fields: []string{"plan_name", "id"},
+			query:  "map(.id)",
+			want:   "[\n  \"1\",\n  \"2\"\n]\n",
+		},
+		{
+			name:   "scalars print one per line",
+			format: FormatTable,
+			query:  `.[] | select(.plan_name != "Sandbox") | .name`,
+			want:   "alpha\n",
+		},
+		{
+			name:   "objects print as a table",
+			format: FormatCSV,
+			query:  `map({plan: .plan_name, name})`,
+			want:   "plan,name\nBasic,alpha\nSandbox,beta\n",
+		},
+		{
+			name:   "no results",
+			format: FormatJSON,
+			query:  `.[] | select(.name == "gamma")`,
+			want:   "[]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			opts := &Options{Format: tt.format, Fields: tt.fields, Query: tt.query, Writer: &buf}
+			if err := Print(items, opts); err != nil {
+				t.Fatalf("Print failed: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("got %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintQuerySerializer(t *testing.T) {
+	items := []*streamTestItem{{ID: "1", Name: "first"}}
+
+	var buf bytes.Buffer
+	if err := Print(items, &Options{Format: FormatJSON, Query: ".[0]", Writer: &buf}); err != nil {
+		t.Fatalf("Print failed: %v", err)
+	}
+	// Serializer names and order are used rather than the JSON tags
+	if want := "{\n  \"Name\": \"first\",\n  \"ID\": \"1\"\n}\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestStreamQuery(t *testing.T) {
+	var buf bytes.Buffer
+	stream := NewStream(&Options{Format: FormatList, Query: ".[].ID", Writer: &buf})
+	for _, item := range []*streamTestItem{{ID: "1", Name: "first"}, {ID: "2", Name: "second"}} {
+		if err := stream.Write(item); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected queries to buffer until Close, got %q", buf.String())
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if want := "1\n2\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	valid := []*Options{
+		{Format: FormatTable},
+		{Format: FormatCSV, Query: ".[] | .id"},
+		{Format: "template={{.ID}}"},
+		{Format: "jsonpath={.items[*].id}"},
+	}
+	for _, opts := range valid {
+		if err := opts.Validate(); err != nil {
+			t.Errorf("Validate(%q, %q) failed: %v", opts.Format, opts.Query, err)
+		}
+	}
+
+	invalid := map[string]*Options{
+		"unsupported format":  {Format: "xml"},
+		"unexpected argument": {Format: "json=x"},
+		"empty template":      {Format: "template="},
+		"bad template":        {Format: "template={{.ID"},
+		"bad jsonpath":        {Format: "jsonpath={.items["},
+		"bad query":           {Format: FormatTable, Query: ".[] |"},
+	}
+	for name, opts := range invalid {
+		if err := opts.Validate(); err == nil {
+			t.Errorf("%s: Validate(%q, %q) succeeded, want an error", name, opts.Format, opts.Query)
+		} else if strings.TrimSpace(err.Error()) == "" {
+			t.Errorf("%s: empty error message", name)
+		}
+	}
+}
@@ -0,0 +1,1376 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Query is a compiled jq-style expression. It supports paths (.a.b, .["a b"],
+// .[0], .[], .[1:3]), pipes, commas, comparisons, and/or, //, arithmetic
+// (including merging objects with + and *, and removing elements from arrays
+// with -), array and object construction, and common builtins such as select,
+// map, length, keys, sort_by and join.
+type Query struct {
+	source string
+	eval   queryFunc
+}
+
+// queryFunc evaluates an expression against an input, producing any number
+// of outputs
+type queryFunc func(input interface{}) ([]interface{}, error)
+
+// ParseQuery compiles a jq-style expression
+func ParseQuery(source string) (*Query, error) {
+	tokens, err := lexQuery(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query %q: %w", source, err)
+	}
+	p := &queryParser{tokens: tokens}
+	eval, err := p.parsePipe()
+	if err == nil && !p.at(tokEOF) {
+		err = fmt.Errorf("unexpected %s", p.peek())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid query %q: %w", source, err)
+	}
+	return &Query{source: source, eval: eval}, nil
+}
+
+// Run evaluates the query against serialized data and returns its outputs
+func (q *Query) Run(input interface{}) ([]interface{}, error) {
+	outputs, err := q.eval(input)
+	if err != nil {
+		return nil, fmt.Errorf("query %q failed: %w", q.source, err)
+	}
+	return outputs, nil
+}
+
+// applyQuery runs query against data serialized with fields, returning a
+// single output as is and any other number of outputs as a list
+func applyQuery(query string, data interface{}, fields []string) (interface{}, error) {
+	q, err := ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	outputs, err := q.Run(serialize(data, fields))
+	if err != nil {
+		return nil, err
+	}
+	if len(outputs) == 1 {
+		return outputs[0], nil
+	}
+	if outputs == nil {
+		outputs = []interface{}{}
+	}
+	return outputs, nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokDot
+	tokField
+	tokIdent
+	tokString
+	tokNumber
+	tokPunct
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	value interface{}
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of query"
+	}
+	return strconv.Quote(t.text)
+}
+
+// queryPunctuation lists operators, longest first so that they lex greedily
+var queryPunctuation = []string{"==", "!=", "<=", ">=", "//", "|", ",", "(", ")", "[", "]", "{", "}", ":", ";", "?", "<", ">", "+", "-", "*", "/", "%"}
+
+func lexQuery(source string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(source); {
+		c := source[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '.':
+			if i+1 < len(source) && isIdentStart(rune(source[i+1])) {
+				j := i + 1
+				for j < len(source) && isIdentPart(rune(source[j])) {
+					j++
+				}
+				tokens = append(tokens, token{kind: tokField, text: source[i:j], value: source[i+1 : j]})
+				i = j
+			} else {
+				tokens = append(tokens, token{kind: tokDot, text: "."})
+				i++
+			}
+		case c == '"':
+			j := i + 1
+			for j < len(source) && source[j] != '"' {
+				if source[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(source) {
+				return nil, errors.New("unterminated string")
+			}
+			var s string
+			if err := json.Unmarshal([]byte(source[i:j+1]), &s); err != nil {
+				return nil, fmt.Errorf("invalid string %s", source[i:j+1])
+			}
+			tokens = append(tokens, token{kind: tokString, text: source[i : j+1], value: s})
+			i = j + 1
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(source) && (source[j] >= '0' && source[j] <= '9' || source[j] == '.') {
+				j++
+			}
+			f, err := strconv.ParseFloat(source[i:j], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %s", source[i:j])
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: source[i:j], value: fromNumber(f)})
+			i = j
+		case isIdentStart(rune(c)):
+			j := i
+			for j < len(source) && isIdentPart(rune(source[j])) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: source[i:j]})
+			i = j
+		default:
+			matched := false
+			for _, punct := range queryPunctuation {
+				if strings.HasPrefix(source[i:], punct) {
+					tokens = append(tokens, token{kind: tokPunct, text: punct})
+					i += len(punct)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character %q", c)
+			}
+		}
+	}
+	return append(tokens, token{kind: tokEOF}), nil
+}
+
+func isIdentStart(r rune) bool { return r == '_' || unicode.IsLetter(r) }
+func isIdentPart(r rune) bool  { return isIdentStart(r) || unicode.IsDigit(r) }
+
+type queryParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *queryParser) peek() token { return p.tokens[p.pos] }
+
+func (p *queryParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *queryParser) at(kind tokenKind) bool { return p.peek().kind == kind }
+
+// atPunct reports whether the next token is one of the given operators
+func (p *queryParser) atPunct(texts ...string) bool {
+	t := p.peek()
+	if t.kind != tokPunct && t.kind != tokIdent {
+		return false
+	}
+	for _, text := range texts {
+		if t.text == text {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *queryParser) expect(text string) error {
+	if !p.atPunct(text) {
+		return fmt.Errorf("expected %q, got %s", text, p.peek())
+	}
+	p.next()
+	return nil
+}
+
+// parsePipe parses a | b
+func (p *queryParser) parsePipe() (queryFunc, error) {
+	left, err := p.parseComma()
+	if err != nil {
+		return nil, err
+	}
+	for p.atPunct("|") {
+		p.next()
+		right, err := p.parseComma()
+		if err != nil {
+			return nil, err
+		}
+		left = pipe(left, right)
+	}
+	return left, nil
+}
+
+func pipe(left, right queryFunc) queryFunc {
+	return func(input interface{}) ([]interface{}, error) {
+		values, err := left(input)
+		if err != nil {
+			return nil, err
+		}
+		var outputs []interface{}
+		for _, value := range values {
+			results, err := right(value)
+			if err != nil {
+				return nil, err
+			}
+			outputs = append(outputs, results...)
+		}
+		return outputs, nil
+	}
+}
+
+// parseComma parses a, b
+func (p *queryParser) parseComma() (queryFunc, error) {
+	left, err := p.parseAlternative()
+	if err != nil {
+		return nil, err
+	}
+	for p.atPunct(",") {
+		p.next()
+		right, err := p.parseAlternative()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(input interface{}) ([]interface{}, error) {
+			a, err := l(input)
+			if err != nil {
+				return nil, err
+			}
+			b, err := right(input)
+			if err != nil {
+				return nil, err
+			}
+			return append(a, b...), nil
+		}
+	}
+	return left, nil
+}
+
+// parseAlternative parses a // b, which produces the truthy outputs of a, or
+// those of b if there are none
+func (p *queryParser) parseAlternative() (queryFunc, error) {
+	left, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	for p.atPunct("//") {
+		p.next()
+		right, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(input interface{}) ([]interface{}, error) {
+			values, _ := l(input)
+			var outputs []interface{}
+			for _, value := range values {
+				if truthy(value) {
+					outputs = append(outputs, value)
+				}
+			}
+			if len(outputs) > 0 {
+				return outputs, nil
+			}
+			return right(input)
+		}
+	}
+	return left, nil
+}
+
+// parseOr parses a or b
+func (p *queryParser) parseOr() (queryFunc, error) {
+	return p.parseBinary([]string{"or"}, p.parseAnd)
+}
+
+// parseAnd parses a and b
+func (p *queryParser) parseAnd() (queryFunc, error) {
+	return p.parseBinary([]string{"and"}, p.parseComparison)
+}
+
+// parseComparison parses a == b and the other comparisons
+func (p *queryParser) parseComparison() (queryFunc, error) {
+	return p.parseBinary([]string{"==", "!=", "<", "<=", ">", ">="}, p.parseAdditive)
+}
+
+// parseAdditive parses a + b and a - b
+func (p *queryParser) parseAdditive() (queryFunc, error) {
+	return p.parseBinary([]string{"+", "-"}, p.parseMultiplicative)
+}
+
+// parseMultiplicative parses a * b, a / b and a % b
+func (p *queryParser) parseMultiplicative() (queryFunc, error) {
+	return p.parseBinary([]string{"*", "/", "%"}, p.parsePostfix)
+}
+
+// parseBinary parses left-associative operators, evaluating both sides
+// against the same input and combining every pair of outputs
+func (p *queryParser) parseBinary(operators []string, operand func() (queryFunc, error)) (queryFunc, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for p.atPunct(operators...) {
+		op := p.next().text
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(input interface{}) ([]interface{}, error) {
+			a, err := l(input)
+			if err != nil {
+				return nil, err
+			}
+			b, err := right(input)
+			if err != nil {
+				return nil, err
+			}
+			var outputs []interface{}
+			for _, x := range a {
+				for _, y := range b {
+					result, err := binaryOp(op, x, y)
+					if err != nil {
+						return nil, err
+					}
+					outputs = append(outputs, result)
+				}
+			}
+			return outputs, nil
+		}
+	}
+	return left, nil
+}
+
+// parsePostfix parses a term followed by any number of .field, [index], []
+// and ? suffixes
+func (p *queryParser) parsePostfix() (queryFunc, error) {
+	term, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.at(tokField):
+			term = pipe(term, fieldAccess(p.next().value.(string)))
+		case p.at(tokDot) && p.tokens[p.pos+1].kind == tokString:
+			p.next()
+			term = pipe(term, fieldAccess(p.next().value.(string)))
+		case p.at(tokDot) && p.tokens[p.pos+1].text == "[":
+			p.next()
+		case p.atPunct("["):
+			suffix, err := p.parseBracket()
+			if err != nil {
+				return nil, err
+			}
+			term = pipe(term, suffix)
+		case p.atPunct("?"):
+			p.next()
+			t := term
+			term = func(input interface{}) ([]interface{}, error) {
+				outputs, err := t(input)
+				if err != nil {
+					return nil, nil
+				}
+				return outputs, nil
+			}
+		default:
+			return term, nil
+		}
+	}
+}
+
+// parseBracket parses [], [expr] or a slice [from:to], where either bound
+// may be left out, after a term
+func (p *queryParser) parseBracket() (queryFunc, error) {
+	if err := p.expect("["); err != nil {
+		return nil, err
+	}
+	if p.atPunct("]") {
+		p.next()
+		return iterate, nil
+	}
+	var index queryFunc
+	if !p.atPunct(":") {
+		var err error
+		if index, err = p.parsePipe(); err != nil {
+			return nil, err
+		}
+	}
+	if p.atPunct(":") {
+		p.next()
+		var to queryFunc
+		if !p.atPunct("]") {
+			var err error
+			if to, err = p.parsePipe(); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		return slice(index, to), nil
+	}
+	if err := p.expect("]"); err != nil {
+		return nil, err
+	}
+	return func(input interface{}) ([]interface{}, error) {
+		keys, err := index(input)
+		if err != nil {
+			return nil, err
+		}
+		var outputs []interface{}
+		for _, key := range keys {
+			value, err := indexValue(input, key)
+			if err != nil {
+				return nil, err
+			}
+			outputs = append(outputs, value)
+		}
+		return outputs, nil
+	}, nil
+}
+
+// parseTerm parses identity, paths, literals, parenthesized expressions,
+// array and object construction and function calls
+func (p *queryParser) parseTerm() (queryFunc, error) {
+	t := p.peek()
+	switch {
+	case t.kind == tokDot:
+		p.next()
+		if p.at(tokString) {
+			return fieldAccess(p.next().value.(string)), nil
+		}
+		return identity, nil
+	case t.kind == tokField:
+		p.next()
+		return fieldAccess(t.value.(string)), nil
+	case t.kind == tokString || t.kind == tokNumber:
+		p.next()
+		return literal(t.value), nil
+	case t.kind == tokPunct && t.text == "-":
+		p.next()
+		operand, err := p.parsePostfix()
+		if err != nil {
+			return nil, err
+		}
+		return pipe(operand, func(input interface{}) ([]interface{}, error) {
+			result, err := binaryOp("-", int64(0), input)
+			return []interface{}{result}, err
+		}), nil
+	case t.kind == tokPunct && t.text == "(":
+		p.next()
+		inner, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		return inner, p.expect(")")
+	case t.kind == tokPunct && t.text == "[":
+		return p.parseArray()
+	case t.kind == tokPunct && t.text == "{":
+		return p.parseObject()
+	case t.kind == tokIdent:
+		return p.parseCall()
+	}
+	return nil, fmt.Errorf("unexpected %s", t)
+}
+
+// parseArray parses [expr], collecting every output of expr into one array
+func (p *queryParser) parseArray() (queryFunc, error) {
+	p.next()
+	if p.atPunct("]") {
+		p.next()
+		return literal([]interface{}{}), nil
+	}
+	inner, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("]"); err != nil {
+		return nil, err
+	}
+	return func(input interface{}) ([]interface{}, error) {
+		items, err := inner(input)
+		if err != nil {
+			return nil, err
+		}
+		if items == nil {
+			items = []interface{}{}
+		}
+		return []interface{}{items}, nil
+	}, nil
+}
+
+// parseObject parses {key: expr, ...}, where a key may be a name, a string
+// or a parenthesized expression, and {name} is short for {name: .name}
+func (p *queryParser) parseObject() (queryFunc, error) {
+	p.next()
+	type entry struct {
+		key   queryFunc
+		value queryFunc
+	}
+	var entries []entry
+	for !p.atPunct("}") {
+		var e entry
+		t := p.next()
+		switch {
+		case t.kind == tokIdent || t.kind == tokString:
+			name := t.text
+			if t.kind == tokString {
+				name = t.value.(string)
+			}
+			e.key = literal(name)
+			e.value = fieldAccess(name)
+		case t.kind == tokPunct && t.text == "(":
+			key, err := p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			e.key = key
+			e.value = nil
+		default:
+			return nil, fmt.Errorf("unexpected %s in object", t)
+		}
+		if p.atPunct(":") {
+			p.next()
+			value, err := p.parseAlternative()
+			if err != nil {
+				return nil, err
+			}
+			e.value = value
+		} else if e.value == nil {
+			return nil, errors.New("expected \":\" after computed object key")
+		}
+		entries = append(entries, e)
+		if !p.atPunct(",") {
+			break
+		}
+		p.next()
+	}
+	if err := p.expect("}"); err != nil {
+		return nil, err
+	}
+
+	return func(input interface{}) ([]interface{}, error) {
+		results := []*object{newObject()}
+		for _, e := range entries {
+			keys, err := e.key(input)
+			if err != nil {
+				return nil, err
+			}
+			values, err := e.value(input)
+			if err != nil {
+				return nil, err
+			}
+			var next []*object
+			for _, partial := range results {
+				for _, key := range keys {
+					name, ok := key.(string)
+					if !ok {
+						return nil, fmt.Errorf("object keys must be strings, got %s", typeName(key))
+					}
+					for _, value := range values {
+						obj := newObject()
+						for _, k := range partial.keys {
+							obj.set(k, partial.values[k])
+						}
+						obj.set(name, value)
+						next = append(next, obj)
+					}
+				}
+			}
+			results = next
+		}
+		outputs := make([]interface{}, len(results))
+		for i, obj := range results {
+			outputs[i] = obj
+		}
+		return outputs, nil
+	}, nil
+}
+
+// parseCall parses keywords and builtin function calls
+func (p *queryParser) parseCall() (queryFunc, error) {
+	name := p.next().text
+	switch name {
+	case "true":
+		return literal(true), nil
+	case "false":
+		return literal(false), nil
+	case "null":
+		return literal(nil), nil
+	}
+
+	var args []queryFunc
+	if p.atPunct("(") {
+		p.next()
+		for {
+			arg, err := p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if !p.atPunct(";") {
+				break
+			}
+			p.next()
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+	}
+
+	builtin, ok := queryBuiltins[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s", name)
+	}
+	if len(args) != builtin.args {
+		return nil, fmt.Errorf("%s takes %d arguments, got %d", name, builtin.args, len(args))
+	}
+	return func(input interface{}) ([]interface{}, error) {
+		return builtin.fn(input, args)
+	}, nil
+}
+
+func identity(input interface{}) ([]interface{}, error) {
+	return []interface{}{input}, nil
+}
+
+func literal(value interface{}) queryFunc {
+	return func(interface{}) ([]interface{}, error) {
+		return []interface{}{value}, nil
+	}
+}
+
+func fieldAccess(name string) queryFunc {
+	return func(input interface{}) ([]interface{}, error) {
+		value, err := indexValue(input, name)
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}{value}, nil
+	}
+}
+
+// iterate produces each element of an array or value of an object
+func iterate(input interface{}) ([]interface{}, error) {
+	switch v := input.(type) {
+	case []interface{}:
+		return v, nil
+	case *object:
+		outputs := make([]interface{}, 0, len(v.keys))
+		for _, key := range v.keys {
+			outputs = append(outputs, v.values[key])
+		}
+		return outputs, nil
+	case map[string]interface{}:
+		outputs := make([]interface{}, 0, len(v))
+		for _, key := range sortedKeys(v) {
+			outputs = append(outputs, v[key])
+		}
+		return outputs, nil
+	}
+	return nil, fmt.Errorf("cannot iterate over %s", typeName(input))
+}
+
+// slice returns a function producing input[from:to] for every combination
+// of the outputs of from and to. A nil bound is left out.
+func slice(from, to queryFunc) queryFunc {
+	bound := func(fn queryFunc, input interface{}) ([]interface{}, error) {
+		if fn == nil {
+			return []interface{}{nil}, nil
+		}
+		return fn(input)
+	}
+	return func(input interface{}) ([]interface{}, error) {
+		starts, err := bound(from, input)
+		if err != nil {
+			return nil, err
+		}
+		ends, err := bound(to, input)
+		if err != nil {
+			return nil, err
+		}
+		var outputs []interface{}
+		for _, start := range starts {
+			for _, end := range ends {
+				value, err := sliceValue(input, start, end)
+				if err != nil {
+					return nil, err
+				}
+				outputs = append(outputs, value)
+			}
+		}
+		return outputs, nil
+	}
+}
+
+// sliceValue returns input[from:to] for arrays and strings, counting
+// negative bounds from the end. A nil bound is left out, and a slice of null
+// is null.
+func sliceValue(input, from, to interface{}) (interface{}, error) {
+	if input == nil {
+		return nil, nil
+	}
+	var length int
+	switch v := input.(type) {
+	case []interface{}:
+		length = len(v)
+	case string:
+		length = len([]rune(v))
+	default:
+		return nil, fmt.Errorf("cannot slice %s", typeName(input))
+	}
+
+	clamp := func(bound interface{}, fallback int) (int, error) {
+		if bound == nil {
+			return fallback, nil
+		}
+		n, ok := toNumber(bound)
+		if !ok {
+			return 0, fmt.Errorf("cannot slice with %s", typeName(bound))
+		}
+		i := int(n)
+		if i < 0 {
+			i += length
+		}
+		return min(max(i, 0), length), nil
+	}
+	start, err := clamp(from, 0)
+	if err != nil {
+		return nil, err
+	}
+	end, err := clamp(to, length)
+	if err != nil {
+		return nil, err
+	}
+	end = max(start, end)
+
+	if s, ok := input.(string); ok {
+		return string([]rune(s)[start:end]), nil
+	}
+	return append([]interface{}{}, input.([]interface{})[start:end]...), nil
+}
+
+// indexValue returns input[key] for objects and arrays. Missing keys and
+// indexes, and any index of null, produce null.
+func indexValue(input, key interface{}) (interface{}, error) {
+	if input == nil {
+		return nil, nil
+	}
+	switch k := key.(type) {
+	case string:
+		switch v := input.(type) {
+		case *object:
+			value, _ := v.get(k)
+			return value, nil
+		case map[string]interface{}:
+			return v[k], nil
+		}
+	default:
+		n, ok := toNumber(key)
+		if !ok {
+			break
+		}
+		if v, ok := input.([]interface{}); ok {
+			i := int(n)
+			if i < 0 {
+				i += len(v)
+			}
+			if i < 0 || i >= len(v) {
+				return nil, nil
+			}
+			return v[i], nil
+		}
+	}
+	return nil, fmt.Errorf("cannot index %s with %s", typeName(input), plainText(key))
+}
+
+// truthy reports whether a value counts as true: everything but false and null
+func truthy(value interface{}) bool {
+	return value != nil && value != false
+}
+
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case int64, int, float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	}
+	return "object"
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// objectKeys returns the keys of an object in order, or of a map sorted
+func objectKeys(value interface{}) ([]string, bool) {
+	switch v := value.(type) {
+	case *object:
+		return v.keys, true
+	case map[string]interface{}:
+		return sortedKeys(v), true
+	}
+	return nil, false
+}
+
+// binaryOp applies an infix operator to two values
+func binaryOp(op string, x, y interface{}) (interface{}, error) {
+	switch op {
+	case "and":
+		return truthy(x) && truthy(y), nil
+	case "or":
+		return truthy(x) || truthy(y), nil
+	case "==":
+		return compareValues(x, y) == 0, nil
+	case "!=":
+		return compareValues(x, y) != 0, nil
+	case "<":
+		return compareValues(x, y) < 0, nil
+	case "<=":
+		return compareValues(x, y) <= 0, nil
+	case ">":
+		return compareValues(x, y) > 0, nil
+	case ">=":
+		return compareValues(x, y) >= 0, nil
+	}
+
+	a, aNum := toNumber(x)
+	b, bNum := toNumber(y)
+	if aNum && bNum {
+		switch op {
+		case "+":
+			return fromNumber(a + b), nil
+		case "-":
+			return fromNumber(a - b), nil
+		case "*":
+			return fromNumber(a * b), nil
+		case "/":
+			if b == 0 {
+				return nil, errors.New("division by zero")
+			}
+			return fromNumber(a / b), nil
+		case "%":
+			if int64(b) == 0 {
+				return nil, errors.New("division by zero")
+			}
+			return int64(a) % int64(b), nil
+		}
+	}
+
+	switch op {
+	case "+":
+		switch {
+		case x == nil:
+			return y, nil
+		case y == nil:
+			return x, nil
+		}
+		if s, ok := x.(string); ok {
+			if t, ok := y.(string); ok {
+				return s + t, nil
+			}
+		}
+		if s, ok := x.([]interface{}); ok {
+			if t, ok := y.([]interface{}); ok {
+				return append(append([]interface{}{}, s...), t...), nil
+			}
+		}
+		if merged, ok := mergeObjects(x, y, false); ok {
+			return merged, nil
+		}
+	case "-":
+		if s, ok := x.([]interface{}); ok {
+			if t, ok := y.([]interface{}); ok {
+				remaining := []interface{}{}
+				for _, item := range s {
+					if !slices.ContainsFunc(t, func(other interface{}) bool { return compareValues(item, other) == 0 }) {
+						remaining = append(remaining, item)
+					}
+				}
+				return remaining, nil
+			}
+		}
+	case "*":
+		if merged, ok := mergeObjects(x, y, true); ok {
+			return merged, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot apply %s to %s and %s", op, typeName(x), typeName(y))
+}
+
+// mergeObjects returns the keys of x and y, taking the value from y when both
+// have a key. With deep set, objects under the same key are merged in turn.
+// It returns false unless x and y are both objects.
+func mergeObjects(x, y interface{}, deep bool) (interface{}, bool) {
+	xKeys, ok := objectKeys(x)
+	if !ok {
+		return nil, false
+	}
+	yKeys, ok := objectKeys(y)
+	if !ok {
+		return nil, false
+	}
+
+	merged := newObject()
+	for _, key := range xKeys {
+		value, _ := indexValue(x, key)
+		merged.set(key, value)
+	}
+	for _, key := range yKeys {
+		value, _ := indexValue(y, key)
+		if existing, ok := merged.get(key); ok && deep {
+			if inner, ok := mergeObjects(existing, value, true); ok {
+				value = inner
+			}
+		}
+		merged.set(key, value)
+	}
+	return merged, true
+}
+
+// typeOrder ranks types in jq's sort order
+func typeOrder(value interface{}) int {
+	switch v := value.(type) {
+	case nil:
+		return 0
+	case bool:
+		if !v {
+			return 1
+		}
+		return 2
+	case int64, int, float64:
+		return 3
+	case string:
+		return 4
+	case []interface{}:
+		return 5
+	}
+	return 6
+}
+
+// compareValues orders two values the way jq does
+func compareValues(x, y interface{}) int {
+	if tx, ty := typeOrder(x), typeOrder(y); tx != ty {
+		return tx - ty
+	}
+	switch a := x.(type) {
+	case int64, int, float64:
+		fa, _ := toNumber(a)
+		fb, _ := toNumber(y)
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(a, y.(string))
+	case []interface{}:
+		b := y.([]interface{})
+		for i := 0; i < len(a) && i < len(b); i++ {
+			if c := compareValues(a[i], b[i]); c != 0 {
+				return c
+			}
+		}
+		return len(a) - len(b)
+	case nil, bool:
+		return 0
+	}
+	return strings.Compare(plainText(x), plainText(y))
+}
+
+type queryBuiltin struct {
+	args int
+	fn   func(input interface{}, args []queryFunc) ([]interface{}, error)
+}
+
+// one wraps a builtin that produces exactly one output
+func one(fn func(input interface{}) (interface{}, error)) queryBuiltin {
+	return queryBuiltin{fn: func(input interface{}, _ []queryFunc) ([]interface{}, error) {
+		value, err := fn(input)
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}{value}, nil
+	}}
+}
+
+// stringFunc wraps a builtin that takes a string argument
+func stringFunc(name string, fn func(input, arg string) (interface{}, error)) queryBuiltin {
+	return queryBuiltin{args: 1, fn: func(input interface{}, args []queryFunc) ([]interface{}, error) {
+		s, ok := input.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s requires a string input, got %s", name, typeName(input))
+		}
+		argValues, err := args[0](input)
+		if err != nil {
+			return nil, err
+		}
+		var outputs []interface{}
+		for _, argValue := range argValues {
+			arg, ok := argValue.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s requires a string argument, got %s", name, typeName(argValue))
+			}
+			result, err := fn(s, arg)
+			if err != nil {
+				return nil, err
+			}
+			outputs = append(outputs, result)
+		}
+		return outputs, nil
+	}}
+}
+
+// requireArray returns input as an array for builtins that need one
+func requireArray(name string, input interface{}) ([]interface{}, error) {
+	items, ok := input.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s requires an array, got %s", name, typeName(input))
+	}
+	return items, nil
+}
+
+// sortBy sorts items by the first output of key for each item
+func sortBy(items []interface{}, key queryFunc) ([]interface{}, error) {
+	type keyed struct {
+		key  interface{}
+		item interface{}
+	}
+	pairs := make([]keyed, len(items))
+	for i, item := range items {
+		keys, err := key(item)
+		if err != nil {
+			return nil, err
+		}
+		pairs[i] = keyed{item: item}
+		if len(keys) > 0 {
+			pairs[i].key = keys[0]
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return compareValues(pairs[i].key, pairs[j].key) < 0 })
+	sorted := make([]interface{}, len(pairs))
+	for i, pair := range pairs {
+		sorted[i] = pair.item
+	}
+	return sorted, nil
+}
+
+var queryBuiltins map[string]queryBuiltin
+
+func init() {
+	queryBuiltins = map[string]queryBuiltin{
+		"empty": {fn: func(interface{}, []queryFunc) ([]interface{}, error) { return nil, nil }},
+		"not":   one(func(input interface{}) (interface{}, error) { return !truthy(input), nil }),
+		"length": one(func(input interface{}) (interface{}, error) {
+			switch v := input.(type) {
+			case nil:
+				return int64(0), nil
+			case string:
+				return int64(len([]rune(v))), nil
+			case []interface{}:
+				return int64(len(v)), nil
+			case *object:
+				return int64(len(v.keys)), nil
+			case map[string]interface{}:
+				return int64(len(v)), nil
+			}
+			if n, ok := toNumber(input); ok {
+				if n < 0 {
+					n = -n
+				}
+				return fromNumber(n), nil
+			}
+			return nil, fmt.Errorf("%s has no length", typeName(input))
+		}),
+		"keys": one(func(input interface{}) (interface{}, error) {
+			keys, ok := objectKeys(input)
+			if !ok {
+				if items, isArray := input.([]interface{}); isArray {
+					indexes := make([]interface{}, len(items))
+					for i := range items {
+						indexes[i] = int64(i)
+					}
+					return indexes, nil
+				}
+				return nil, fmt.Errorf("%s has no keys", typeName(input))
+			}
+			sorted := append([]string{}, keys...)
+			sort.Strings(sorted)
+			result := make([]interface{}, len(sorted))
+			for i, key := range sorted {
+				result[i] = key
+			}
+			return result, nil
+		}),
+		"keys_unsorted": one(func(input interface{}) (interface{}, error) {
+			keys, ok := objectKeys(input)
+			if !ok {
+				return nil, fmt.Errorf("%s has no keys", typeName(input))
+			}
+			result := make([]interface{}, len(keys))
+			for i, key := range keys {
+				result[i] = key
+			}
+			return result, nil
+		}),
+		"has": {args: 1, fn: func(input interface{}, args []queryFunc) ([]interface{}, error) {
+			keys, err := args[0](input)
+			if err != nil {
+				return nil, err
+			}
+			var outputs []interface{}
+			for _, key := range keys {
+				switch v := input.(type) {
+				case *object:
+					_, ok := v.get(plainText(key))
+					outputs = append(outputs, ok)
+				case map[string]interface{}:
+					_, ok := v[plainText(key)]
+					outputs = append(outputs, ok)
+				case []interface{}:
+					n, _ := toNumber(key)
+					outputs = append(outputs, n >= 0 && int(n) < len(v))
+				default:
+					return nil, fmt.Errorf("cannot check whether %s has a key", typeName(input))
+				}
+			}
+			return outputs, nil
+		}},
+		"select": {args: 1, fn: func(input interface{}, args []queryFunc) ([]interface{}, error) {
+			conditions, err := args[0](input)
+			if err != nil {
+				return nil, err
+			}
+			var outputs []interface{}
+			for _, condition := range conditions {
+				if truthy(condition) {
+					outputs = append(outputs, input)
+				}
+			}
+			return outputs, nil
+		}},
+		"map": {args: 1, fn: func(input interface{}, args []queryFunc) ([]interface{}, error) {
+			items, err := iterate(input)
+			if err != nil {
+				return nil, err
+			}
+			mapped := []interface{}{}
+			for _, item := range items {
+				results, err := args[0](item)
+				if err != nil {
+					return nil, err
+				}
+				mapped = append(mapped, results...)
+			}
+			return []interface{}{mapped}, nil
+		}},
+		"sort": one(func(input interface{}) (interface{}, error) {
+			items, err := requireArray("sort", input)
+			if err != nil {
+				return nil, err
+			}
+			return sortBy(items, identity)
+		}),
+		"sort_by": {args: 1, fn: func(input interface{}, args []queryFunc) ([]interface{}, error) {
+			items, err := requireArray("sort_by", input)
+			if err != nil {
+				return nil, err
+			}
+			sorted, err := sortBy(items, args[0])
+			if err != nil {
+				return nil, err
+			}
+			return []interface{}{sorted}, nil
+		}},
+		"reverse": one(func(input interface{}) (interface{}, error) {
+			items, err := requireArray("reverse", input)
+			if err != nil {
+				return nil, err
+			}
+			reversed := make([]interface{}, len(items))
+			for i, item := range items {
+				reversed[len(items)-1-i] = item
+			}
+			return reversed, nil
+		}),
+		"unique": one(func(input interface{}) (interface{}, error) {
+			items, err := requireArray("unique", input)
+			if err != nil {
+				return nil, err
+			}
+			sorted, err := sortBy(items, identity)
+			if err != nil {
+				return nil, err
+			}
+			unique := []interface{}{}
+			for i, item := range sorted {
+				if i == 0 || compareValues(item, sorted[i-1]) != 0 {
+					unique = append(unique, item)
+				}
+			}
+			return unique, nil
+		}),
+		"first": one(func(input interface{}) (interface{}, error) { return indexValue(input, int64(0)) }),
+		"last":  one(func(input interface{}) (interface{}, error) { return indexValue(input, int64(-1)) }),
+		"add": one(func(input interface{}) (interface{}, error) {
+			items, err := iterate(input)
+			if err != nil {
+				return nil, err
+			}
+			var sum interface{}
+			for _, item := range items {
+				if sum, err = binaryOp("+", sum, item); err != nil {
+					return nil, err
+				}
+			}
+			return sum, nil
+		}),
+		"min": one(func(input interface{}) (interface{}, error) {
+			items, err := requireArray("min", input)
+			if err != nil || len(items) == 0 {
+				return nil, err
+			}
+			sorted, err := sortBy(items, identity)
+			if err != nil {
+				return nil, err
+			}
+			return sorted[0], nil
+		}),
+		"max": one(func(input interface{}) (interface{}, error) {
+			items, err := requireArray("max", input)
+			if err != nil || len(items) == 0 {
+				return nil, err
+			}
+			sorted, err := sortBy(items, identity)
+			if err != nil {
+				return nil, err
+			}
+			return sorted[len(sorted)-1], nil
+		}),
+		"to_entries": one(func(input interface{}) (interface{}, error) {
+			keys, ok := objectKeys(input)
+			if !ok {
+				return nil, fmt.Errorf("%s has no entries", typeName(input))
+			}
+			entries := make([]interface{}, len(keys))
+			for i, key := range keys {
+				value, _ := indexValue(input, key)
+				entry := newObject()
+				entry.set("key", key)
+				entry.set("value", value)
+				entries[i] = entry
+			}
+			return entries, nil
+		}),
+		"join": {args: 1, fn: func(input interface{}, args []queryFunc) ([]interface{}, error) {
+			items, err := requireArray("join", input)
+			if err != nil {
+				return nil, err
+			}
+			separators, err := args[0](input)
+			if err != nil {
+				return nil, err
+			}
+			var outputs []interface{}
+			for _, separator := range separators {
+				parts := make([]string, len(items))
+				for i, item := range items {
+					parts[i] = plainText(item)
+				}
+				outputs = append(outputs, strings.Join(parts, plainText(separator)))
+			}
+			return outputs, nil
+		}},
+		"tostring": one(func(input interface{}) (interface{}, error) { return plainText(input), nil }),
+		"tonumber": one(func(input interface{}) (interface{}, error) {
+			if _, ok := toNumber(input); ok {
+				return input, nil
+			}
+			f, err := strconv.ParseFloat(plainText(input), 64)
+			if err != nil {
+				return nil, fmt.Errorf("cannot parse %q as a number", plainText(input))
+			}
+			return fromNumber(f), nil
+		}),
+		"type": one(func(input interface{}) (interface{}, error) { return typeName(input), nil }),
+		"ascii_downcase": one(func(input interface{}) (interface{}, error) {
+			s, ok := input.(string)
+			if !ok {
+				return nil, fmt.Errorf("ascii_downcase requires a string input, got %s", typeName(input))
+			}
+			return strings.ToLower(s), nil
+		}),
+		"ascii_upcase": one(func(input interface{}) (interface{}, error) {
+			s, ok := input.(string)
+			if !ok {
+				return nil, fmt.Errorf("ascii_upcase requires a string input, got %s", typeName(input))
+			}
+			return strings.ToUpper(s), nil
+		}),
+		"startswith": stringFunc("startswith", func(s, prefix string) (interface{}, error) {
+			return strings.HasPrefix(s, prefix), nil
+		}),
+		"endswith": stringFunc("endswith", func(s, suffix string) (interface{}, error) {
+			return strings.HasSuffix(s, suffix), nil
+		}),
+		"contains": stringFunc("contains", func(s, substr string) (interface{}, error) {
+			return strings.Contains(s, substr), nil
+		}),
+		"test": stringFunc("test", func(s, pattern string) (interface{}, error) {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regular expression: %w", err)
+			}
+			return re.MatchString(s), nil
+		}),
+	}
+}
@@ -0,0 +1,112 @@
+package output
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestQueryRun(t *testing.T) {
+	input := []interface{}{
+		map[string]interface{}{"id": "a", "name": "Alpha", "size": int64(3), "tags": []interface{}{"x", "y"}},
+		map[string]interface{}{"id": "b", "name": "Beta", "size": int64(5), "tags": []interface{}{}},
+		map[string]interface{}{"id": "c", "name": "Gamma", "size": int64(1), "tags": nil},
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{".", `[[{"id":"a","name":"Alpha","size":3,"tags":["x","y"]},{"id":"b","name":"Beta","size":5,"tags":[]},{"id":"c","name":"Gamma","size":1,"tags":null}]]`},
+		{".[].id", `["a","b","c"]`},
+		{".[0].name", `["Alpha"]`},
+		{".[-1].id", `["c"]`},
+		{`.[] | select(.size > 2) | .id`, `["a","b"]`},
+		{`map(.size) | add`, `[9]`},
+		{`length`, `[3]`},
+		{`[.[] | .size] | max`, `[5]`},
+		{`sort_by(.size) | map(.id)`, `[["c","a","b"]]`},
+		{`sort_by(.size) | reverse | first | .id`, `["b"]`},
+		{`.[] | {id, big: (.size >= 5)}`, `[{"id":"a","big":false},{"id":"b","big":true},{"id":"c","big":false}]`},
+		{`.[] | select(.name | startswith("G")) | .id`, `["c"]`},
+		{`.[] | .tags // "none"`, `[["x","y"],[],"none"]`},
+		{`.[1] | keys`, `[["id","name","size","tags"]]`},
+		{`.[0].tags | join(",")`, `["x,y"]`},
+		{`.[] | .id + "-" + (.size | tostring)`, `["a-3","b-5","c-1"]`},
+		{`.[0].size * 2 + 1`, `[7]`},
+		{`.[0] | has("name"), has("missing")`, `[true,false]`},
+		{`.[] | select(.id == "a" or .id == "c") | .name | ascii_upcase`, `["ALPHA","GAMMA"]`},
+		{`.[0].missing?`, `[null]`},
+		{`[.[].id] | unique | length`, `[3]`},
+		{`.[0] | to_entries | map(.key)`, `[["id","name","size","tags"]]`},
+		{`.[] | select(.name | test("^[AB]")) | .id`, `["a","b"]`},
+		{`map(.name) | sort | .[0]`, `["Alpha"]`},
+		{`.[] | empty`, `null`},
+		{`.[1:] | map(.id)`, `[["b","c"]]`},
+		{`.[:1] | map(.id)`, `[["a"]]`},
+		{`.[-2:] | map(.id)`, `[["b","c"]]`},
+		{`.[1:-1] | map(.id)`, `[["b"]]`},
+		{`.[5:] | length`, `[0]`},
+		{`.[0].name[1:3]`, `["lp"]`},
+		{`.[2].tags[1:]`, `[null]`},
+		{`{} + {a: 1}`, `[{"a":1}]`},
+		{`.[0] | {id, size} + {size: 4, extra: true}`, `[{"id":"a","size":4,"extra":true}]`},
+		{`{a: {b: 1, c: 2}} * {a: {c: 3}, d: 4}`, `[{"a":{"b":1,"c":3},"d":4}]`},
+		{`{a: {b: 1}} + {a: {c: 2}}`, `[{"a":{"c":2}}]`},
+		{`[1, 2, 3, 2] - [2]`, `[[1,3]]`},
+		{`[.[].id] - ["a", "missing"]`, `[["b","c"]]`},
+		{`.[0].name | ascii_downcase`, `["alpha"]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseQuery failed: %v", err)
+			}
+			got, err := q.Run(input)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			data, err := json.Marshal(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("got %s, want %s", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	for _, query := range []string{"", ".[", "select(", ".foo |", "nosuchfunction", `"unterminated`, ". ]", ".[1:", ".[:2", ".[1:2:3]"} {
+		if _, err := ParseQuery(query); err == nil {
+			t.Errorf("ParseQuery(%q) succeeded, want an error", query)
+		}
+	}
+}
+
+func TestQueryRunErrors(t *testing.T) {
+	for _, query := range []string{
+		`.[0] + 1`,
+		`.[0].name | keys`,
+		`"a" | .[0]`,
+		`.[0] - .[0]`,
+		`[1] - 1`,
+		`{} - {}`,
+		`[1] * [1]`,
+		`{} + []`,
+		`.[0] | ascii_downcase`,
+		`[.[0].name] | ascii_upcase`,
+		`.[0][1:]`,
+		`.[0].name["a":]`,
+	} {
+		q, err := ParseQuery(query)
+		if err != nil {
+			t.Fatalf("ParseQuery(%q) failed: %v", query, err)
+		}
+		if _, err := q.Run([]interface{}{map[string]interface{}{"name": "x"}}); err == nil {
+			t.Errorf("Run(%q) succeeded, want an error", query)
+		}
+	}
+}
@@ -0,0 +1,326 @@
+package output
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// object is a serialized item. Its keys keep the order of the table columns,
+// so it prints the same way as the item it came from.
+type object struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newObject() *object {
+	return &object{values: make(map[string]interface{})}
+}
+
+// set adds or replaces a value, keeping the position of an existing key
+func (o *object) set(key string, value interface{}) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// get returns the value for key
+func (o *object) get(key string) (interface{}, bool) {
+	value, ok := o.values[key]
+	return value, ok
+}
+
+// Serialize implements Serializer so that objects print with their key order
+func (o *object) Serialize() []SerializedField {
+	fields := make([]SerializedField, 0, len(o.keys))
+	for _, key := range o.keys {
+		fields = append(fields, SerializedField{Name: key, Value: o.values[key]})
+	}
+	return fields
+}
+
+// MarshalJSON encodes the object with its keys in order
+func (o *object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// MarshalYAML encodes the object as a mapping with its keys in order
+func (o *object) MarshalYAML() (interface{}, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, key := range o.keys {
+		var value yaml.Node
+		if err := value.Encode(o.values[key]); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, &value)
+	}
+	return node, nil
+}
+
+// serialize converts data into the generic values that queries, templates
+// and JSONPath expressions run on. Lists become []interface{} and items
+// become objects with the same field names, selection and order as the table
+// output: Serializer names, then JSON tags, limited to fields or, if none are
+// given, the DefaultFielder fields.
+func serialize(data interface{}, fields []string) interface{} {
+	if len(fields) == 0 {
+		fields = getDefaultFields(data)
+	}
+
+	v := reflect.ValueOf(data)
+	if v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() == reflect.Slice {
+		v = v.Elem()
+	}
+	if (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type().Elem().Kind() != reflect.Uint8 {
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = serializeItem(v.Index(i), fields)
+		}
+		return items
+	}
+	return serializeItem(v, fields)
+}
+
+// serializeItem converts a single item to an object, or to a generic value
+// if it isn't a struct or map
+func serializeItem(v reflect.Value, fields []string) interface{} {
+	item, ok := newItemFields(v)
+	if !ok {
+		if !v.IsValid() {
+			return nil
+		}
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			return nil
+		}
+		return toGeneric(v.Interface())
+	}
+
+	obj := newObject()
+	if len(fields) == 0 {
+		for _, name := range item.names {
+			obj.set(name, toGeneric(item.values[name]))
+		}
+		return obj
+	}
+	for _, field := range fields {
+		name, ok := item.lookup(field)
+		if !ok {
+			obj.set(field, nil)
+			continue
+		}
+		obj.set(name, toGeneric(item.values[name]))
+	}
+	return obj
+}
+
+// itemFields holds the fields of a struct, Serializer or map in table order
+type itemFields struct {
+	names   []string
+	values  map[string]interface{}
+	aliases map[string]string
+}
+
+// newItemFields collects the fields of v: Serializer names, then JSON names
+// of struct fields, then the sorted keys of a string-keyed map. It returns
+// false for anything else.
+func newItemFields(v reflect.Value) (*itemFields, bool) {
+	if !v.IsValid() {
+		return nil, false
+	}
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+
+	item := &itemFields{values: make(map[string]interface{}), aliases: make(map[string]string)}
+	serializer, isSerializer := asSerializer(v)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+
+	switch {
+	case isSerializer:
+		for _, sf := range serializer.Serialize() {
+			item.add(sf.Name, sf.Value)
+		}
+	case v.Kind() == reflect.Struct && !isTextMarshaler(v):
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() || field.Tag.Get("json") == "-" {
+				continue
+			}
+			item.add(getHeaderName(&field), v.Field(i).Interface(), field.Name)
+		}
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		keys := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			item.add(key, v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key())).Interface())
+		}
+	default:
+		return nil, false
+	}
+	return item, true
+}
+
+// add records a field, which can be looked up by its name, its table header
+// or any of the aliases
+func (f *itemFields) add(name string, value interface{}, aliases ...string) {
+	f.names = append(f.names, name)
+	f.values[name] = value
+	for _, alias := range append(aliases, name, toHumanReadable(name)) {
+		key := fieldKey(alias)
+		if _, ok := f.aliases[key]; !ok {
+			f.aliases[key] = name
+		}
+	}
+}
+
+// lookup returns the name of the field matching field
+func (f *itemFields) lookup(field string) (string, bool) {
+	name, ok := f.aliases[fieldKey(field)]
+	return name, ok
+}
+
+// fieldKey normalizes a field name for matching, ignoring case, spaces and
+// punctuation, so "Is Frozen?", "is_frozen" and "IsFrozen" are the same field
+func fieldKey(name string) string {
+	return strings.ToLower(templateName(name))
+}
+
+// asSerializer returns v as a Serializer, checking pointer receivers first
+func asSerializer(v reflect.Value) (Serializer, bool) {
+	if !v.CanInterface() {
+		return nil, false
+	}
+	if serializer, ok := v.Interface().(Serializer); ok {
+		return serializer, true
+	}
+	if v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().CanInterface() {
+		serializer, ok := v.Elem().Interface().(Serializer)
+		return serializer, ok
+	}
+	return nil, false
+}
+
+// isTextMarshaler reports whether v formats itself as text, like time.Time
+func isTextMarshaler(v reflect.Value) bool {
+	if !v.CanInterface() {
+		return false
+	}
+	_, ok := v.Interface().(encoding.TextMarshaler)
+	return ok
+}
+
+// toGeneric converts a value to the types produced by decoding JSON, keeping
+// whole numbers as int64 so that IDs and timestamps print exactly
+func toGeneric(value interface{}) interface{} {
+	switch value.(type) {
+	case nil, string, bool, int64, float64, *object:
+		return value
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return normalizeNumbers(generic)
+}
+
+// normalizeNumbers replaces json.Number values with int64 or float64
+func normalizeNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalizeNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeNumbers(item)
+		}
+	}
+	return value
+}
+
+// toNumber returns value as a float64 if it is a number
+func toNumber(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case int64:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// fromNumber returns f as an int64 if it is a whole number, and otherwise as
+// a float64
+func fromNumber(f float64) interface{} {
+	if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+		return int64(f)
+	}
+	return f
+}
+
+// plainText formats a generic value for templates and JSONPath: strings as
+// they are, nil as nothing, and everything else as compact JSON
+func plainText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
@@ -0,0 +1,177 @@
+// Package output provides formatting utilities for CLI output.
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+)
+
+// Stream prints list items one at a time as they become available.
+// JSON, NDJSON, CSV, TSV and list formats are written incrementally, skipping items that
+// don't match the filters; formats that need to see every row before printing
+// (table, YAML, templates), sorting and queries are buffered until Close.
+type Stream struct {
+	opts     *Options
+	count    int
+	buffered []interface{}
+	csv      *csv.Writer
+	header   bool
+	filters  []*Filter
+}
+
+// NewStream creates a stream that prints items using the given options
+func NewStream(opts *Options) *Stream {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	if opts.Writer == nil {
+		opts.Writer = os.Stdout
+	}
+	return &Stream{opts: opts}
+}
+
+// Write prints a single item
+func (s *Stream) Write(item interface{}) error {
+	if len(s.opts.Filters) > 0 {
+		if s.filters == nil {
+			filters, _, err := parseListOptions(s.opts)
+			if err != nil {
+				return err
+			}
+			// Every item has the same type, so the first one is enough to
+			// check the fields against
+			if err := checkFields(reflect.TypeOf(item), filterFields(filters)); err != nil {
+				return err
+			}
+			s.filters = filters
+		}
+		if !matchesFilters(item, s.filters) {
+			return nil
+		}
+	}
+
+	defer func() { s.count++ }()
+
+	if s.opts.Query != "" || len(s.opts.Sort) > 0 {
+		s.buffered = append(s.buffered, item)
+		return nil
+	}
+
+	switch s.opts.Format {
+	case FormatJSON:
+		return s.writeJSON(item)
+	case FormatCSV:
+		return s.writeCSV(item)
+	case FormatNDJSON:
+		return writeNDJSON(s.opts.Writer, serialize(item, s.opts.Fields))
+	case FormatTSV:
+		return s.writeTSV(item)
+	case FormatList:
+		rows, _ := extractItemData(item, s.opts.Fields)
+		if len(rows) > 0 && len(rows[0]) > 0 {
+			_, err := fmt.Fprintln(s.opts.Writer, rows[0][0])
+			return err
+		}
+		return nil
+	default:
+		s.buffered = append(s.buffered, item)
+		return nil
+	}
+}
+
+// Close finishes the output, printing any buffered items
+func (s *Stream) Close() error {
+	if s.opts.Query != "" || len(s.opts.Sort) > 0 {
+		return s.flush()
+	}
+
+	switch s.opts.Format {
+	case FormatJSON:
+		if s.count == 0 {
+			_, err := fmt.Fprintln(s.opts.Writer, "[]")
+			return err
+		}
+		_, err := fmt.Fprintln(s.opts.Writer, "\n]")
+		return err
+	case FormatCSV:
+		if s.csv != nil {
+			s.csv.Flush()
+			return s.csv.Error()
+		}
+		return nil
+	case FormatList, FormatNDJSON, FormatTSV:
+		return nil
+	default:
+		return s.flush()
+	}
+}
+
+// flush prints the buffered items
+func (s *Stream) flush() error {
+	if s.buffered == nil {
+		s.buffered = []interface{}{}
+	}
+	return Print(s.buffered, s.opts)
+}
+
+// writeJSON writes an item as the next element of a JSON array, matching the
+// indentation used by Print
+func (s *Stream) writeJSON(item interface{}) error {
+	data, err := json.MarshalIndent(item, "  ", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode item: %w", err)
+	}
+
+	prefix := ",\n  "
+	if s.count == 0 {
+		prefix = "[\n  "
+	}
+	_, err = fmt.Fprintf(s.opts.Writer, "%s%s", prefix, data)
+	return err
+}
+
+// writeCSV writes an item as a CSV row, emitting the header before the first row
+func (s *Stream) writeCSV(item interface{}) error {
+	rows, headers := extractItemData(item, s.opts.Fields)
+	if len(rows) == 0 {
+		return nil
+	}
+
+	if s.csv == nil {
+		s.csv = csv.NewWriter(s.opts.Writer)
+		if err := s.csv.Write(headers); err != nil {
+			return fmt.Errorf("failed to write CSV header: %w", err)
+		}
+	}
+
+	if err := s.csv.Write(rows[0]); err != nil {
+		return fmt.Errorf("failed to write CSV row: %w", err)
+	}
+	s.csv.Flush()
+	return s.csv.Error()
+}
+
+// writeTSV writes an item as a TSV row, emitting the header before the first row
+func (s *Stream) writeTSV(item interface{}) error {
+	rows, headers := extractItemData(item, s.opts.Fields)
+	if len(rows) == 0 {
+		return nil
+	}
+
+	if !s.header {
+		if err := writeTSVRow(s.opts.Writer, headers); err != nil {
+			return err
+		}
+		s.header = true
+	}
+	return writeTSVRow(s.opts.Writer, rows[0])
+}
+
+// extractItemData extracts a single list item the same way extractTableData
+// does for slice elements, so pointer receivers such as Serializer are honored
+func extractItemData(item interface{}, fields []string) (rows [][]string, headers []string) {
+	return extractTableData([]interface{}{item}, fields)
+}
@@ -0,0 +1,77 @@
+// Package output provides formatting utilities for CLI output.
+package output
+
+import (
+	"bytes"
+	"testing"
+)
+
+type streamTestItem struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func (i *streamTestItem) Serialize() []SerializedField {
+	return []SerializedField{
+		{Name: "Name", Value: i.Name},
+		{Name: "ID", Value: i.ID},
+	}
+}
+
+func TestStreamMatchesPrint(t *testing.T) {
+	items := []*streamTestItem{
+		{ID: "1", Name: "alpha"},
+		{ID: "2", Name: "beta"},
+		{ID: "3", Name: "gamma <&>"},
+	}
+
+	formats := []Format{FormatJSON, FormatCSV, FormatList, FormatTable, FormatYAML, FormatNDJSON, FormatTSV, FormatMarkdown, FormatHTML}
+	for _, format := range formats {
+		t.Run(string(format), func(t *testing.T) {
+			var expected, actual bytes.Buffer
+			if err := Print(items, &Options{Format: format, Writer: &expected}); err != nil {
+				t.Fatalf("Print failed: %v", err)
+			}
+
+			stream := NewStream(&Options{Format: format, Writer: &actual})
+			for _, item := range items {
+				if err := stream.Write(item); err != nil {
+					t.Fatalf("Write failed: %v", err)
+				}
+			}
+			if err := stream.Close(); err != nil {
+				t.Fatalf("Close failed: %v", err)
+			}
+
+			if actual.String() != expected.String() {
+				t.Errorf("stream output differs from Print output\nexpected:\n%s\nactual:\n%s", expected.String(), actual.String())
+			}
+		})
+	}
+}
+
+func TestStreamWritesIncrementally(t *testing.T) {
+	var buf bytes.Buffer
+	stream := NewStream(&Options{Format: FormatCSV, Writer: &buf})
+
+	if err := stream.Write(&streamTestItem{ID: "1", Name: "alpha"}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	// The first row must be visible before the stream is closed
+	if buf.String() != "Name,ID\nalpha,1\n" {
+		t.Errorf("unexpected output before close: %q", buf.String())
+	}
+}
+
+func TestStreamEmptyJSON(t *testing.T) {
+	var buf bytes.Buffer
+	stream := NewStream(&Options{Format: FormatJSON, Writer: &buf})
+	if err := stream.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if buf.String() != "[]\n" {
+		t.Errorf("expected empty JSON array, got %q", buf.String())
+	}
+}
@@ -0,0 +1,271 @@
+// Package remote runs commands on Pantheon environments over SSH, using the
+// SFTP endpoint returned by the connection-info API.
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deviantintegral/terminus-golang/pkg/api/models"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// ErrNoAuth is returned when no SSH agent or private key is available
+var ErrNoAuth = errors.New("no SSH credentials found: start an SSH agent or add a key in ~/.ssh, and register it with ssh-key:add")
+
+// ExitError reports a remote command that exited with a non-zero status
+type ExitError struct {
+	Command string
+	Code    int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("%s exited with status %d", e.Command, e.Code)
+}
+
+// Target is the SSH endpoint of an environment
+type Target struct {
+	Host string
+	Port int
+	User string
+}
+
+// TargetFromConnectionInfo returns the SFTP endpoint of an environment, which
+// also accepts commands over SSH
+func TargetFromConnectionInfo(info *models.ConnectionInfo) Target {
+	return Target{Host: info.SFTPHost, Port: info.SFTPPort, User: info.SFTPUsername}
+}
+
+// Addr returns the host and port to dial
+func (t Target) Addr() string {
+	return net.JoinHostPort(t.Host, strconv.Itoa(t.Port))
+}
+
+// Dialer opens SSH connections to environments
+type Dialer struct {
+	auth            []ssh.AuthMethod
+	hostKeyCallback ssh.HostKeyCallback
+	connectTimeout  time.Duration
+}
+
+// Option configures a Dialer
+type Option func(*Dialer)
+
+// WithAuth sets the authentication methods, instead of the SSH agent and
+// the default keys in ~/.ssh
+func WithAuth(methods ...ssh.AuthMethod) Option {
+	return func(d *Dialer) {
+		d.auth = methods
+	}
+}
+
+// WithHostKeyCallback sets how host keys are checked, instead of the default
+// that rejects keys that conflict with ~/.ssh/known_hosts
+func WithHostKeyCallback(callback ssh.HostKeyCallback) Option {
+	return func(d *Dialer) {
+		d.hostKeyCallback = callback
+	}
+}
+
+// WithConnectTimeout limits how long connecting and authenticating may take
+func WithConnectTimeout(timeout time.Duration) Option {
+	return func(d *Dialer) {
+		d.connectTimeout = timeout
+	}
+}
+
+// NewDialer creates a dialer with the given options
+func NewDialer(opts ...Option) *Dialer {
+	d := &Dialer{connectTimeout: 30 * time.Second}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Dial connects and authenticates to target
+func (d *Dialer) Dial(ctx context.Context, target Target) (*ssh.Client, error) {
+	auth := d.auth
+	if auth == nil {
+		var err error
+		if auth, err = DefaultAuth(); err != nil {
+			return nil, err
+		}
+	}
+	hostKeyCallback := d.hostKeyCallback
+	if hostKeyCallback == nil {
+		hostKeyCallback = DefaultHostKeyCallback()
+	}
+
+	config := &ssh.ClientConfig{
+		User:            target.User,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         d.connectTimeout,
+	}
+
+	dialer := &net.Dialer{Timeout: d.connectTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", target.Addr())
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", target.Addr(), err)
+	}
+
+	// Bound the handshake by the context and the connect timeout
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	} else if d.connectTimeout > 0 {
+		_ = conn.SetDeadline(time.Now().Add(d.connectTimeout))
+	}
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, target.Addr(), config)
+	stop()
+	if err != nil {
+		_ = conn.Close()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("failed to open SSH connection to %s: %w", target.Addr(), err)
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	return ssh.NewClient(sshConn, chans, reqs), nil
+}
+
+// DefaultAuth returns the keys held by the SSH agent, if one is running, and
+// the unencrypted default keys in ~/.ssh
+func DefaultAuth() ([]ssh.AuthMethod, error) {
+	var signers []ssh.Signer
+
+	if socket := os.Getenv("SSH_AUTH_SOCK"); socket != "" {
+		if conn, err := net.Dial("unix", socket); err == nil {
+			if agentSigners, err := agent.NewClient(conn).Signers(); err == nil {
+				signers = append(signers, agentSigners...)
+			}
+		}
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		for _, name := range []string{"id_ed25519", "id_ecdsa", "id_rsa"} {
+			data, err := os.ReadFile(filepath.Join(home, ".ssh", name))
+			if err != nil {
+				continue
+			}
+			// Passphrase-protected keys must be loaded into the agent
+			if signer, err := ssh.ParsePrivateKey(data); err == nil {
+				signers = append(signers, signer)
+			}
+		}
+	}
+
+	if len(signers) == 0 {
+		return nil, ErrNoAuth
+	}
+	return []ssh.AuthMethod{ssh.PublicKeys(signers...)}, nil
+}
+
+// DefaultHostKeyCallback rejects host keys that conflict with
+// ~/.ssh/known_hosts and accepts hosts that aren't listed there. Pantheon
+// application servers change as containers migrate, so, as with PHP Terminus,
+// unknown hosts can't be required to be listed.
+func DefaultHostKeyCallback() ssh.HostKeyCallback {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ssh.InsecureIgnoreHostKey() //nolint:gosec // see above
+	}
+	known, err := knownhosts.New(filepath.Join(home, ".ssh", "known_hosts"))
+	if err != nil {
+		return ssh.InsecureIgnoreHostKey() //nolint:gosec // see above
+	}
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		err := known(hostname, remote, key)
+		var keyErr *knownhosts.KeyError
+		if errors.As(err, &keyErr) && len(keyErr.Want) == 0 {
+			return nil
+		}
+		return err
+	}
+}
+
+// Command builds a remote command line from a program and its arguments,
+// quoting each argument for the remote shell
+func Command(program string, args ...string) string {
+	parts := make([]string, 0, len(args)+1)
+	parts = append(parts, program)
+	for _, arg := range args {
+		parts = append(parts, Quote(arg))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Quote quotes s for a POSIX shell, leaving simple words as they are
+func Quote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_=.,:/@%+") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Run runs command in a new session on client, copying stdin to it, if not
+// nil, and its output to stdout and stderr as it arrives. A non-zero exit
+// status is returned as an *ExitError. If ctx ends first, the session is
+// closed and ctx's error is returned.
+func Run(ctx context.Context, client *ssh.Client, command string, stdin io.Reader, stdout, stderr io.Writer) error {
+	session, err := client.NewSession()
+	if err != nil {
+		return fmt.Errorf("failed to open SSH session: %w", err)
+	}
+	defer func() { _ = session.Close() }()
+
+	session.Stdout = stdout
+	session.Stderr = stderr
+
+	// Copy stdin without waiting for it, so that the command can finish
+	// while the input is still open
+	var input io.WriteCloser
+	if stdin != nil {
+		if input, err = session.StdinPipe(); err != nil {
+			return fmt.Errorf("failed to open SSH session input: %w", err)
+		}
+	}
+
+	if err := session.Start(command); err != nil {
+		return fmt.Errorf("failed to start remote command: %w", err)
+	}
+	if input != nil {
+		go func() {
+			_, _ = io.Copy(input, stdin)
+			_ = input.Close()
+		}()
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- session.Wait() }()
+
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		_ = session.Signal(ssh.SIGTERM)
+		_ = session.Close()
+		return ctx.Err()
+	}
+
+	var exitErr *ssh.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		return &ExitError{Command: strings.SplitN(command, " ", 2)[0], Code: exitErr.ExitStatus()}
+	case err != nil:
+		return fmt.Errorf("remote command failed: %w", err)
+	}
+	return nil
+}
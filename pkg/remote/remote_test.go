@@ -0,0 +1,121 @@
+package remote_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/deviantintegral/terminus-golang/pkg/remote"
+	"github.com/deviantintegral/terminus-golang/pkg/remote/remotetest"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestRun(t *testing.T) {
+	server := remotetest.NewServer(t)
+	server.Handler = func(_ context.Context, s *remotetest.Session) int {
+		input, _ := io.ReadAll(s.Stdin)
+		_, _ = fmt.Fprintf(s.Stdout, "ran %s as %s with %q", s.Command, s.User, input)
+		_, _ = fmt.Fprint(s.Stderr, "warning")
+		return 0
+	}
+
+	client, err := server.Dialer().Dial(context.Background(), server.Target("dev.site-id"))
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	var stdout, stderr bytes.Buffer
+	command := remote.Command("drush", "status", "--field=uri")
+	if err := remote.Run(context.Background(), client, command, bytes.NewBufferString("input"), &stdout, &stderr); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if want := `ran drush status --field=uri as dev.site-id with "input"`; stdout.String() != want {
+		t.Errorf("stdout = %q, want %q", stdout.String(), want)
+	}
+	if stderr.String() != "warning" {
+		t.Errorf("stderr = %q, want %q", stderr.String(), "warning")
+	}
+}
+
+func TestRunExitStatus(t *testing.T) {
+	server := remotetest.NewServer(t)
+	server.Handler = func(context.Context, *remotetest.Session) int { return 3 }
+
+	client, err := server.Dialer().Dial(context.Background(), server.Target("dev.site-id"))
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	err = remote.Run(context.Background(), client, "wp plugin list", nil, io.Discard, io.Discard)
+	var exitErr *remote.ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 3 || exitErr.Command != "wp" {
+		t.Fatalf("expected wp to exit with status 3, got %v", err)
+	}
+}
+
+func TestRunContextCancelled(t *testing.T) {
+	server := remotetest.NewServer(t)
+	server.Handler = func(ctx context.Context, _ *remotetest.Session) int {
+		<-ctx.Done()
+		return 143
+	}
+
+	client, err := server.Dialer().Dial(context.Background(), server.Target("dev.site-id"))
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = remote.Run(ctx, client, "drush cron", nil, io.Discard, io.Discard)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline to end the command, got %v", err)
+	}
+}
+
+func TestDialRejectsUnknownKey(t *testing.T) {
+	server := remotetest.NewServer(t)
+	other := remotetest.NewServer(t)
+
+	// Authenticate with another server's client key
+	dialer := remote.NewDialer(
+		remote.WithAuth(ssh.PublicKeysCallback(func() ([]ssh.Signer, error) {
+			return nil, errors.New("no keys")
+		})),
+		remote.WithHostKeyCallback(ssh.InsecureIgnoreHostKey()), //nolint:gosec // test server
+	)
+	if _, err := dialer.Dial(context.Background(), server.Target("dev.site-id")); err == nil {
+		t.Fatal("expected authentication to fail")
+	}
+
+	// Trust another server's host key
+	if _, err := other.Dialer().Dial(context.Background(), server.Target("dev.site-id")); err == nil {
+		t.Fatal("expected the host key check to fail")
+	}
+}
+
+func TestCommand(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"status"}, "drush status"},
+		{[]string{"sql:query", "SELECT * FROM users"}, "drush sql:query 'SELECT * FROM users'"},
+		{[]string{"eval", "echo 'hi';"}, `drush eval 'echo '\''hi'\'';'`},
+		{[]string{"--uri=https://example.com/a"}, "drush --uri=https://example.com/a"},
+		{[]string{""}, "drush ''"},
+		{[]string{"$(rm -rf /)"}, "drush '$(rm -rf /)'"},
+	}
+	for _, tt := range tests {
+		if got := remote.Command("drush", tt.args...); got != tt.want {
+			t.Errorf("Command(%q) = %s, want %s", tt.args, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+// Package remotetest provides a local stand-in for the SSH endpoint of a
+// Pantheon environment, for testing code built on pkg/remote.
+package remotetest
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/deviantintegral/terminus-golang/pkg/remote"
+	"golang.org/x/crypto/ssh"
+)
+
+// Session is a command run on the server
+type Session struct {
+	User    string
+	Command string
+	Env     map[string]string
+	Stdin   io.Reader
+	Stdout  io.Writer
+	Stderr  io.Writer
+}
+
+// Handler runs a command and returns its exit status. ctx is cancelled when
+// the client closes the session or sends a signal.
+type Handler func(ctx context.Context, s *Session) int
+
+// Server is an SSH server on a local port that accepts a generated client key,
+// runs commands with Handler and forwards TCP connections to the addresses
+// clients ask for. A Server is safe for concurrent use.
+type Server struct {
+	// Handler runs each command. The default prints nothing and exits 0.
+	Handler Handler
+
+	listener net.Listener
+	hostKey  ssh.Signer
+	client   ssh.Signer
+
+	mu       sync.Mutex
+	sessions []*Session
+	forwards []string
+	conns    map[*ssh.ServerConn]struct{}
+	wg       sync.WaitGroup
+}
+
+// NewServer starts a server that is stopped when the test finishes
+func NewServer(t *testing.T) *Server {
+	t.Helper()
+
+	s := &Server{hostKey: newSigner(t), client: newSigner(t), conns: make(map[*ssh.ServerConn]struct{})}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	s.listener = listener
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) != string(s.client.PublicKey().Marshal()) {
+				return nil, errors.New("unknown key")
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(s.hostKey)
+
+	s.wg.Add(1)
+	go s.serve(config)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func newSigner(t *testing.T) ssh.Signer {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	return signer
+}
+
+// Close stops the server
+func (s *Server) Close() {
+	_ = s.listener.Close()
+	s.wg.Wait()
+}
+
+// Host returns the host the server listens on
+func (s *Server) Host() string {
+	host, _, _ := net.SplitHostPort(s.listener.Addr().String())
+	return host
+}
+
+// Port returns the port the server listens on
+func (s *Server) Port() int {
+	_, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	n, _ := strconv.Atoi(port)
+	return n
+}
+
+// Target returns the server's address as a target for user
+func (s *Server) Target(user string) remote.Target {
+	return remote.Target{Host: s.Host(), Port: s.Port(), User: user}
+}
+
+// Dialer returns a dialer that authenticates with the server's client key
+// and trusts only its host key
+func (s *Server) Dialer() *remote.Dialer {
+	return remote.NewDialer(
+		remote.WithAuth(ssh.PublicKeys(s.client)),
+		remote.WithHostKeyCallback(ssh.FixedHostKey(s.hostKey.PublicKey())),
+	)
+}
+
+// Sessions returns the commands that have been run, in order
+func (s *Server) Sessions() []*Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Session(nil), s.sessions...)
+}
+
+// Forwards returns the addresses that TCP connections have been forwarded
+// to, in order
+func (s *Server) Forwards() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.forwards...)
+}
+
+// DropConnections closes every open SSH connection, as happens when an
+// environment's container migrates
+func (s *Server) DropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+}
+
+func (s *Server) serve(config *ssh.ServerConfig) {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handleConn(conn, config)
+		}()
+	}
+}
+
+func (s *Server) handleConn(conn net.Conn, config *ssh.ServerConfig) {
+	defer func() { _ = conn.Close() }()
+
+	serverConn, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	defer func() { _ = serverConn.Close() }()
+	go ssh.DiscardRequests(reqs)
+
+	s.mu.Lock()
+	s.conns[serverConn] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, serverConn)
+		s.mu.Unlock()
+	}()
+
+	var channels sync.WaitGroup
+	defer channels.Wait()
+	for newChannel := range chans {
+		switch newChannel.ChannelType() {
+		case "session":
+			channel, requests, err := newChannel.Accept()
+			if err != nil {
+				continue
+			}
+			channels.Add(1)
+			go func() {
+				defer channels.Done()
+				s.handleSession(serverConn.User(), channel, requests)
+			}()
+		case "direct-tcpip":
+			channels.Add(1)
+			go func() {
+				defer channels.Done()
+				s.handleForward(newChannel)
+			}()
+		default:
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unsupported channel type")
+		}
+	}
+}
+
+// handleForward connects a direct-tcpip channel to the address it asks for
+func (s *Server) handleForward(newChannel ssh.NewChannel) {
+	var dest struct {
+		Host       string
+		Port       uint32
+		OriginHost string
+		OriginPort uint32
+	}
+	if err := ssh.Unmarshal(newChannel.ExtraData(), &dest); err != nil {
+		_ = newChannel.Reject(ssh.ConnectionFailed, "invalid forward request")
+		return
+	}
+	addr := net.JoinHostPort(dest.Host, strconv.Itoa(int(dest.Port)))
+
+	s.mu.Lock()
+	s.forwards = append(s.forwards, addr)
+	s.mu.Unlock()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		_ = newChannel.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+	defer func() { _ = conn.Close() }()
+	channel, requests, err := newChannel.Accept()
+	if err != nil {
+		return
+	}
+	defer func() { _ = channel.Close() }()
+	go ssh.DiscardRequests(requests)
+
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(channel, conn)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(conn, channel)
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+// handleSession answers session requests until a command or shell is
+// started, then runs it and reports its exit status
+func (s *Server) handleSession(user string, channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer func() { _ = channel.Close() }()
+
+	session := &Session{User: user, Env: make(map[string]string), Stdin: channel, Stdout: channel, Stderr: channel.Stderr()}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := false
+	for req := range requests {
+		switch {
+		case req.Type == "env" && !started:
+			var env struct{ Name, Value string }
+			if ssh.Unmarshal(req.Payload, &env) == nil {
+				session.Env[env.Name] = env.Value
+			}
+			_ = req.Reply(true, nil)
+		case req.Type == "pty-req" && !started:
+			_ = req.Reply(true, nil)
+		case (req.Type == "exec" || req.Type == "shell") && !started:
+			if req.Type == "exec" {
+				var exec struct{ Command string }
+				if ssh.Unmarshal(req.Payload, &exec) != nil {
+					_ = req.Reply(false, nil)
+					continue
+				}
+				session.Command = exec.Command
+			}
+			started = true
+			_ = req.Reply(true, nil)
+
+			s.mu.Lock()
+			s.sessions = append(s.sessions, session)
+			s.mu.Unlock()
+
+			go func() {
+				status := 0
+				if s.Handler != nil {
+					status = s.Handler(ctx, session)
+				}
+				_ = channel.CloseWrite()
+				_, _ = channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{uint32(status)}))
+				_ = channel.Close()
+			}()
+		case req.Type == "signal":
+			cancel()
+		default:
+			if req.WantReply {
+				_ = req.Reply(false, nil)
+			}
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// maxRetryDelay caps the wait between reconnection attempts
+const maxRetryDelay = 30 * time.Second
+
+// Tunnel forwards connections from a local listener to an address that is
+// reachable from an environment, such as its database, over SSH. When the
+// SSH connection drops or the address stops answering, the tunnel connects
+// again with a fresh target and address, since both change when the
+// environment's containers migrate.
+type Tunnel struct {
+	// Dialer opens the SSH connections
+	Dialer *Dialer
+
+	// Resolve returns the SSH target and the address to forward to. It is
+	// called before each connection.
+	Resolve func(ctx context.Context) (Target, string, error)
+
+	// Logf, if set, reports connections and reconnections
+	Logf func(format string, args ...interface{})
+
+	// RetryDelay is the wait before the first reconnection attempt, doubled
+	// after each failure up to 30s. The default is one second.
+	RetryDelay time.Duration
+}
+
+// Serve accepts connections on listener and forwards them until ctx ends,
+// then returns ctx's error. Errors while making the first connection are
+// returned; later ones are retried. listener is closed when Serve returns.
+func (t *Tunnel) Serve(ctx context.Context, listener net.Listener) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Accept in the background so that connections that arrive while
+	// reconnecting wait for the new SSH connection
+	conns := make(chan net.Conn)
+	acceptErr := make(chan error, 1)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				acceptErr <- err
+				return
+			}
+			select {
+			case conns <- conn:
+			case <-ctx.Done():
+				_ = conn.Close()
+				return
+			}
+		}
+	}()
+	stop := context.AfterFunc(ctx, func() { _ = listener.Close() })
+	defer stop()
+
+	initialDelay := t.RetryDelay
+	if initialDelay <= 0 {
+		initialDelay = time.Second
+	}
+	delay := initialDelay
+	connected := false
+
+	for {
+		client, addr, err := t.connect(ctx)
+		if err == nil {
+			connected = true
+			delay = initialDelay
+			t.logf("Forwarding %s to %s", listener.Addr(), addr)
+			err = forward(ctx, client, addr, conns, acceptErr)
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var listenErr *acceptError
+		if !connected || errors.As(err, &listenErr) {
+			return err
+		}
+
+		t.logf("Tunnel lost (%v); reconnecting in %s", err, delay)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		delay = min(delay*2, maxRetryDelay)
+	}
+}
+
+// connect resolves the tunnel's endpoints and opens an SSH connection
+func (t *Tunnel) connect(ctx context.Context) (*ssh.Client, string, error) {
+	target, addr, err := t.Resolve(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	client, err := t.Dialer.Dial(ctx, target)
+	if err != nil {
+		return nil, "", err
+	}
+	return client, addr, nil
+}
+
+func (t *Tunnel) logf(format string, args ...interface{}) {
+	if t.Logf != nil {
+		t.Logf(format, args...)
+	}
+}
+
+// acceptError reports that the local listener failed
+type acceptError struct {
+	err error
+}
+
+func (e *acceptError) Error() string { return fmt.Sprintf("failed to accept connection: %v", e.err) }
+func (e *acceptError) Unwrap() error { return e.err }
+
+// forward copies each accepted connection to and from addr through client,
+// until ctx ends, client's connection drops or addr can't be reached. It
+// closes client and waits for the connections it forwarded to end.
+func forward(ctx context.Context, client *ssh.Client, addr string, conns <-chan net.Conn, acceptErr <-chan error) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer func() { _ = client.Close() }()
+
+	dropped := make(chan error, 1)
+	go func() { dropped <- client.Wait() }()
+
+	failed := make(chan error, 1)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-acceptErr:
+			return &acceptError{err: err}
+		case err := <-dropped:
+			if err == nil {
+				err = io.EOF
+			}
+			return fmt.Errorf("SSH connection closed: %w", err)
+		case err := <-failed:
+			return err
+		case local := <-conns:
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				upstream, err := client.Dial("tcp", addr)
+				if err != nil {
+					_ = local.Close()
+					select {
+					case failed <- fmt.Errorf("failed to connect to %s: %w", addr, err):
+					default:
+					}
+					return
+				}
+				proxy(local, upstream)
+			}()
+		}
+	}
+}
+
+// proxy copies data both ways between two connections until either side
+// closes, then closes both
+func proxy(a, b net.Conn) {
+	done := make(chan struct{}, 2)
+	copyAndSignal := func(dst, src net.Conn) {
+		_, _ = io.Copy(dst, src)
+		done <- struct{}{}
+	}
+	go copyAndSignal(a, b)
+	go copyAndSignal(b, a)
+	<-done
+	_ = a.Close()
+	_ = b.Close()
+	<-done
+}
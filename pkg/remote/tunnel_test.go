@@ -0,0 +1,129 @@
+package remote_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/deviantintegral/terminus-golang/pkg/remote"
+	"github.com/deviantintegral/terminus-golang/pkg/remote/remotetest"
+)
+
+// startEcho starts a TCP server that echoes each line it receives
+func startEcho(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// echoThrough sends a line through the tunnel listening on addr and returns
+// the reply
+func echoThrough(t *testing.T, addr, line string) string {
+	t.Helper()
+
+	conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+	if err != nil {
+		t.Fatalf("failed to connect to tunnel: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	if _, err := fmt.Fprintln(conn, line); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	return reply[:len(reply)-1]
+}
+
+func TestTunnelReconnects(t *testing.T) {
+	server := remotetest.NewServer(t)
+	echo := startEcho(t)
+
+	var resolved atomic.Int32
+	tunnel := &remote.Tunnel{
+		Dialer: server.Dialer(),
+		Resolve: func(context.Context) (remote.Target, string, error) {
+			resolved.Add(1)
+			return server.Target("dev.site-id"), echo, nil
+		},
+		RetryDelay: time.Millisecond,
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- tunnel.Serve(ctx, listener) }()
+
+	if got := echoThrough(t, listener.Addr().String(), "hello"); got != "hello" {
+		t.Errorf("got %q, want %q", got, "hello")
+	}
+
+	// A dropped connection is replaced, with the endpoints resolved again
+	server.DropConnections()
+	deadline := time.Now().Add(5 * time.Second)
+	for resolved.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := echoThrough(t, listener.Addr().String(), "again"); got != "again" {
+		t.Errorf("got %q, want %q", got, "again")
+	}
+	if n := resolved.Load(); n != 2 {
+		t.Errorf("expected the endpoints to be resolved twice, got %d", n)
+	}
+	if forwards := server.Forwards(); len(forwards) != 2 || forwards[0] != echo {
+		t.Errorf("unexpected forwards %v", forwards)
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected Serve to stop with context.Canceled, got %v", err)
+	}
+}
+
+func TestTunnelFirstConnectionFails(t *testing.T) {
+	wantErr := errors.New("no such environment")
+	tunnel := &remote.Tunnel{
+		Dialer: remote.NewDialer(),
+		Resolve: func(context.Context) (remote.Target, string, error) {
+			return remote.Target{}, "", wantErr
+		},
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	if err := tunnel.Serve(context.Background(), listener); !errors.Is(err, wantErr) {
+		t.Errorf("expected %v, got %v", wantErr, err)
+	}
+}